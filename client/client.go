@@ -216,15 +216,23 @@ func (r *ZanzibarDagClient) GetAllNamespaces() ([]string, error) {
 }
 
 func (r *ZanzibarDagClient) Check(from domain.Node, to domain.Node, searchCond domain.SearchCondition) (bool, error) {
+	return r.CheckInContext(from, to, searchCond, nil)
+}
+
+// CheckInContext checks like Check, evaluating the conditions of relations
+// with the variables of conditionContext.
+func (r *ZanzibarDagClient) CheckInContext(from domain.Node, to domain.Node, searchCond domain.SearchCondition, conditionContext domain.ConditionContext) (bool, error) {
 	type requestBody struct {
-		Subject         domain.Node            `json:"subject"`
-		Object          domain.Node            `json:"object"`
-		SearchCondition domain.SearchCondition `json:"search_condition"`
+		Subject         domain.Node             `json:"subject"`
+		Object          domain.Node             `json:"object"`
+		SearchCondition domain.SearchCondition  `json:"search_condition"`
+		Context         domain.ConditionContext `json:"context,omitempty"`
 	}
 	payload := requestBody{
 		Subject:         from,
 		Object:          to,
 		SearchCondition: searchCond,
+		Context:         conditionContext,
 	}
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"strings"
+
 	"github.com/spf13/viper"
 )
 
@@ -8,6 +10,10 @@ func ReadConfig() error {
 	viper.AddConfigPath(".")
 	viper.SetConfigType("yaml")
 
+	// allow overriding any key from the environment, e.g. DB_TYPE=sqlite
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
 	if err := viper.ReadInConfig(); err != nil {
 		return err
 	}
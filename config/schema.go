@@ -0,0 +1,27 @@
+package config
+
+import (
+	"os"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadSchemaFile reads the namespace definitions of a schema file, like
+//
+//	namespaces:
+//	  - name: doc
+//	    relations:
+//	      - name: viewer
+//	        subject_types: [user, group#member]
+func ReadSchemaFile(path string) ([]domain.NamespaceDefinition, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	schema := domain.Schema{}
+	if err := yaml.Unmarshal(content, &schema); err != nil {
+		return nil, err
+	}
+	return schema.Namespaces, nil
+}
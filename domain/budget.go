@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// Budget bounds the work of one traversal. A field of 0 sets no limit.
+type Budget struct {
+	// MaxDepth is the number of relations a path may have.
+	MaxDepth int
+	// MaxNodes is the number of nodes a traversal may visit. Traversals
+	// that search inside the storage engine are bounded by depth and time
+	// only.
+	MaxNodes int
+	// MaxPaths is the number of paths GetAllPaths and GetKShortestPaths
+	// return.
+	MaxPaths int
+	// Timeout is the wall time a traversal may take.
+	Timeout time.Duration
+}
+
+// Override returns b with the nonzero fields of o in place of its own.
+func (b Budget) Override(o Budget) Budget {
+	if o.MaxDepth > 0 {
+		b.MaxDepth = o.MaxDepth
+	}
+	if o.MaxNodes > 0 {
+		b.MaxNodes = o.MaxNodes
+	}
+	if o.MaxPaths > 0 {
+		b.MaxPaths = o.MaxPaths
+	}
+	if o.Timeout > 0 {
+		b.Timeout = o.Timeout
+	}
+	return b
+}
+
+// The limits of a Budget a traversal can fail on, as reported by
+// BudgetExceededError. Running out of depth means a path is not found, and
+// out of paths means the result is truncated. Only a permission evaluation
+// fails on depth, when it runs out of it under an exclusion, where not
+// finding a subject would grant access.
+const (
+	NodeBudget  = "nodes"
+	TimeBudget  = "time"
+	DepthBudget = "depth"
+)
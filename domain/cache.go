@@ -0,0 +1,18 @@
+package domain
+
+// CacheStats reports the counters of the check decision cache and of the
+// adjacency lookup cache.
+type CacheStats struct {
+	Checks  CacheCounters `json:"checks"`
+	Lookups CacheCounters `json:"lookups"`
+}
+
+type CacheCounters struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+	// Invalidations counts the entries removed by writes, Evictions the ones
+	// removed for space or age.
+	Invalidations uint64 `json:"invalidations"`
+	Evictions     uint64 `json:"evictions"`
+}
@@ -59,3 +59,26 @@ func (c *CollectCondition) ShouldCollect(node Node) bool {
 	}
 	return false
 }
+
+// PathPreference ranks paths of the same length, those through the earlier
+// listed namespaces and relations first.
+type PathPreference struct {
+	Namespaces []string `json:"namespaces"`
+	Relations  []string `json:"relations"`
+}
+
+// Cost is the rank of the object namespace of relation plus the rank of its
+// relation, an unlisted value ranking after every listed one. A path costs
+// the sum of the costs of its relations.
+func (p *PathPreference) Cost(relation Relation) int {
+	return rank(p.Namespaces, relation.ObjectNamespace) + rank(p.Relations, relation.Relation)
+}
+
+func rank(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return len(values)
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type Relation struct {
 	ObjectNamespace  string `json:"object_namespace"`
 	ObjectName       string `json:"object_name"`
@@ -7,6 +9,46 @@ type Relation struct {
 	SubjectNamespace string `json:"subject_namespace"`
 	SubjectName      string `json:"subject_name"`
 	SubjectRelation  string `json:"subject_relation"`
+	// Condition, if set, is an expression the relation only holds while it
+	// evaluates true, see Condition. ConditionParams are its parameters.
+	// Neither is part of the identity of the relation, nor matched by
+	// queries.
+	Condition       string          `json:"condition,omitempty"`
+	ConditionParams ConditionParams `json:"condition_params,omitempty"`
+	// ExpiresAt, if not nil, is the time the relation expires at. From then
+	// on reads do not see it, and writes take it for deleted. It is no part
+	// of the identity of the relation either, so relations that may carry
+	// one are compared by their Key.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Key returns relation without its condition and expiry, the fields that
+// identify it.
+func (r Relation) Key() Relation {
+	return Relation{
+		ObjectNamespace:  r.ObjectNamespace,
+		ObjectName:       r.ObjectName,
+		Relation:         r.Relation,
+		SubjectNamespace: r.SubjectNamespace,
+		SubjectName:      r.SubjectName,
+		SubjectRelation:  r.SubjectRelation,
+	}
+}
+
+// Expired reports whether relation has expired at now.
+func (r Relation) Expired(now time.Time) bool {
+	return r.ExpiresAt != nil && !now.Before(*r.ExpiresAt)
+}
+
+// Compile compiles the condition of relation, nil if it has none.
+func (r Relation) Compile() (*Condition, error) {
+	if r.Condition == "" {
+		if r.ConditionParams != "" {
+			return nil, InvalidConditionError{Reason: "params without an expression"}
+		}
+		return nil, nil
+	}
+	return CompileCondition(r.Condition, r.ConditionParams)
 }
 
 type Node struct {
@@ -15,6 +57,28 @@ type Node struct {
 	Relation  string `json:"relation"`
 }
 
+// Wildcard is the name of the subject matching every subject without
+// relation of its namespace, like user:* for anyone.
+const Wildcard = "*"
+
+// IsWildcard reports whether n is the wildcard subject of its namespace.
+func (n Node) IsWildcard() bool {
+	return n.Name == Wildcard && n.Relation == ""
+}
+
+// Matches reports whether n, as the subject of a relation, stands for
+// subject: n is subject, or the entity of subject if it has no relation, or
+// the wildcard of its namespace if it has none either.
+func (n Node) Matches(subject Node) bool {
+	if n == subject {
+		return true
+	}
+	if subject.Relation != "" {
+		return false
+	}
+	return n.Namespace == subject.Namespace && (n.Name == subject.Name || n.IsWildcard())
+}
+
 type TreeNode struct {
 	Namespace string      `json:"namespace"`
 	Name      string      `json:"name"`
@@ -26,6 +90,12 @@ type ErrResponse struct {
 	Error string `json:"error"`
 }
 
+type BatchErrResponse struct {
+	Error string `json:"error"`
+	// Index of the failed operation, if the error is specific to one
+	Index *int `json:"index,omitempty"`
+}
+
 type RelationsResponse struct {
 	Relations []Relation `json:"data"`
 }
@@ -33,3 +103,8 @@ type RelationsResponse struct {
 type StringsResponse struct {
 	Data []string `json:"data"`
 }
+
+type TokenResponse struct {
+	// Token is the consistency token of the write
+	Token string `json:"token"`
+}
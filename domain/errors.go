@@ -1,5 +1,7 @@
 package domain
 
+import "fmt"
+
 type CauseCycleError struct {
 }
 
@@ -13,3 +15,97 @@ type RequestBodyError struct {
 func (e RequestBodyError) Error() string {
 	return "body attribute error"
 }
+
+type AlreadyExistsError struct {
+}
+
+func (e AlreadyExistsError) Error() string {
+	return "relation already exists"
+}
+
+type InvalidTokenError struct {
+}
+
+func (e InvalidTokenError) Error() string {
+	return "invalid consistency token"
+}
+
+type SnapshotUnavailableError struct {
+}
+
+func (e SnapshotUnavailableError) Error() string {
+	return "snapshot of the revision is not available"
+}
+
+// BudgetExceededError is returned by a traversal that ran out of the Limit
+// of its budget before it could answer.
+type BudgetExceededError struct {
+	Limit string
+}
+
+func (e BudgetExceededError) Error() string {
+	return "budget exceeded: " + e.Limit
+}
+
+// InvalidSchemaError rejects a schema, Reason tells what is wrong with it.
+type InvalidSchemaError struct {
+	Reason string
+}
+
+func (e InvalidSchemaError) Error() string {
+	return "invalid schema: " + e.Reason
+}
+
+// SchemaViolationError rejects a relation the namespace schema does not
+// allow, Reason tells why.
+type SchemaViolationError struct {
+	Reason string
+}
+
+func (e SchemaViolationError) Error() string {
+	return "schema violation: " + e.Reason
+}
+
+// InvalidConditionError rejects the condition of a relation, Reason tells
+// what is wrong with it.
+type InvalidConditionError struct {
+	Reason string
+}
+
+func (e InvalidConditionError) Error() string {
+	return "invalid condition: " + e.Reason
+}
+
+// MissingContextError is returned by the evaluation of a condition that needs
+// the context variable Name, which the check does not have.
+type MissingContextError struct {
+	Name string
+}
+
+func (e MissingContextError) Error() string {
+	return "missing context variable " + e.Name
+}
+
+// ConditionContextError rejects the context of a check, Reason tells which
+// variable does not fit the condition using it.
+type ConditionContextError struct {
+	Reason string
+}
+
+func (e ConditionContextError) Error() string {
+	return "invalid context: " + e.Reason
+}
+
+// BatchOperationError is the error of the operation at Index of a batch.
+type BatchOperationError struct {
+	Index int
+	Err   error
+}
+
+func (e BatchOperationError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err.Error())
+}
+
+func (e BatchOperationError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,15 @@
+package domain
+
+// UsersetTree is the tree Expand returns for the relation of an object. The
+// children of a node are the subjects holding its relation directly: subject
+// sets like group:eng#member with children of their own and, at the bottom,
+// subjects without relation.
+type UsersetTree struct {
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Relation  string        `json:"relation"`
+	Children  []UsersetTree `json:"children"`
+	// Truncated marks a subject set at the depth of the expansion, whose
+	// children are not listed.
+	Truncated bool `json:"truncated,omitempty"`
+}
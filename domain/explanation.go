@@ -0,0 +1,18 @@
+package domain
+
+// Explanation is the evaluation of a rewrite on Object for the subject of a
+// permission check. Its children are the operands, relations or subject
+// sets the decision rests on: for an allowed union the branch that allowed,
+// for a denied intersection or exclusion the branch that denied, and every
+// branch tried otherwise.
+type Explanation struct {
+	Object Node `json:"object"`
+	// Rewrite is the expression or term evaluated, the definition of the
+	// relation of Object at its top. It is empty for the subject itself.
+	Rewrite string `json:"rewrite"`
+	Allowed bool   `json:"allowed"`
+	// Through is the stored relation followed to Object, from the object
+	// of the parent explanation.
+	Through  *Relation     `json:"through,omitempty"`
+	Children []Explanation `json:"children"`
+}
@@ -0,0 +1,1060 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MaxConditionLength is the length in bytes a condition expression may
+// have.
+const MaxConditionLength = 4096
+
+// MaxConditionDepth is how deep parentheses, lists, calls and unary
+// operators of a condition expression may nest.
+const MaxConditionDepth = 32
+
+// ConditionParams are the parameters of the condition of a relation, a JSON
+// object. They are kept as canonical JSON, with sorted keys and without
+// spaces, so relations with the same parameters compare equal.
+type ConditionParams string
+
+// NewConditionParams returns params as ConditionParams, empty if there are
+// none.
+func NewConditionParams(params map[string]interface{}) (ConditionParams, error) {
+	if len(params) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return ConditionParams(data), nil
+}
+
+// ParseConditionParams parses the JSON object data as ConditionParams.
+func ParseConditionParams(data []byte) (ConditionParams, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return "", nil
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return "", InvalidConditionError{Reason: "params must be a JSON object"}
+	}
+	return NewConditionParams(params)
+}
+
+// Map returns the parameters, nil if there are none.
+func (p ConditionParams) Map() (map[string]interface{}, error) {
+	if p == "" {
+		return nil, nil
+	}
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(p), &params); err != nil {
+		return nil, InvalidConditionError{Reason: "params must be a JSON object"}
+	}
+	return params, nil
+}
+
+func (p ConditionParams) MarshalJSON() ([]byte, error) {
+	if p == "" {
+		return []byte("null"), nil
+	}
+	return []byte(p), nil
+}
+
+func (p *ConditionParams) UnmarshalJSON(data []byte) error {
+	params, err := ParseConditionParams(data)
+	if err != nil {
+		return err
+	}
+	*p = params
+	return nil
+}
+
+// ConditionContext holds the values of the context variables of a check, as
+// decoded from JSON.
+type ConditionContext map[string]interface{}
+
+// Condition is a compiled condition expression, with its parameters bound.
+//
+// The language is a small typed expression language without loops or
+// calls into the server, so an evaluation takes time linear in the length of
+// the expression. An expression has the type bool and is made of
+//
+//   - literals: numbers, double quoted strings, true, false and lists of
+//     numbers or strings like ["a", "b"]
+//   - variables: the parameters of the relation, the context variables of
+//     the check, and now, the time of the check
+//   - conversions of strings: timestamp("2030-01-01T00:00:00Z"),
+//     duration("1h"), ip("10.0.0.1") and cidr("10.0.0.0/8")
+//   - the operators, in increasing precedence: ||, &&, the comparisons ==,
+//     !=, <, <=, >, >= and in, the binary + and -, and the unary ! and -.
+//
+// The types are bool, number, string, timestamp, duration, ip, cidr and
+// lists of numbers or strings. in tests whether a value is in a list, or an
+// ip in a cidr. + adds numbers and durations, concatenates strings and adds
+// durations to timestamps, - subtracts them and timestamps from timestamps.
+//
+// Every name that is not a parameter is a context variable. The types of
+// parameters and context variables are inferred from their use, or taken
+// from their JSON value if their use leaves them open. A string value
+// converts to a timestamp, duration, ip or cidr where one is needed.
+type Condition struct {
+	root *conditionNode
+	// params are the converted values of the parameters the expression
+	// uses, contextTypes the types of its context variables.
+	params       map[string]interface{}
+	contextTypes map[string]valueType
+}
+
+// CompileCondition parses and type checks expression with params, and
+// converts the parameters to the types the expression uses them as. It fails
+// with InvalidConditionError.
+func CompileCondition(expression string, params ConditionParams) (*Condition, error) {
+	if len(expression) > MaxConditionLength {
+		return nil, InvalidConditionError{Reason: fmt.Sprintf("longer than %d bytes", MaxConditionLength)}
+	}
+	values, err := params.Map()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := values[nowVariable]; ok {
+		return nil, InvalidConditionError{Reason: nowVariable + " is not a valid parameter name"}
+	}
+	p := &conditionParser{input: expression}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != endToken {
+		return nil, p.errorf(token, "unexpected %s", token.text)
+	}
+
+	// Uses of a variable may only fix its type after the first one, so the
+	// check repeats while it binds more variables, and only its last run
+	// reports unresolved types.
+	c := &conditionChecker{params: values, types: map[string]valueType{}, checked: map[checkKey]checkResult{}}
+	for bound := -1; bound < len(c.types); {
+		bound = len(c.types)
+		c.check(root, boolType)
+	}
+	c.strict, c.checked = true, map[checkKey]checkResult{}
+	t, err := c.check(root, boolType)
+	if err != nil {
+		return nil, err
+	}
+	if t != boolType {
+		return nil, InvalidConditionError{Reason: fmt.Sprintf("the expression is a %s, not a bool", t)}
+	}
+
+	condition := &Condition{root: root, params: map[string]interface{}{}, contextTypes: map[string]valueType{}}
+	for name, t := range c.types {
+		value, ok := values[name]
+		if !ok {
+			condition.contextTypes[name] = t
+			continue
+		}
+		converted, err := convertValue(value, t)
+		if err != nil {
+			return nil, InvalidConditionError{Reason: fmt.Sprintf("param %s: %s", name, err.Error())}
+		}
+		condition.params[name] = converted
+	}
+	if err := condition.fold(root); err != nil {
+		return nil, err
+	}
+	return condition, nil
+}
+
+// fold evaluates the conversions of literals and parameters, so a string
+// that does not convert fails the compilation rather than the checks.
+func (c *Condition) fold(node *conditionNode) error {
+	for _, operand := range node.operands {
+		if err := c.fold(operand); err != nil {
+			return err
+		}
+	}
+	if node.op != callOp {
+		return nil
+	}
+	argument := node.operands[0]
+	var s string
+	switch {
+	case argument.op == literalOp:
+		s = argument.value.(string)
+	case argument.op == variableOp && c.params[argument.name] != nil:
+		s = c.params[argument.name].(string)
+	default:
+		return nil
+	}
+	value, err := convertString(s, conversions[node.name])
+	if err != nil {
+		return InvalidConditionError{Reason: fmt.Sprintf("at %d: %s", node.pos, err.Error())}
+	}
+	node.op, node.value, node.operands = literalOp, value, nil
+	return nil
+}
+
+// Holds evaluates the condition with the context variables of context, at
+// the time now. It fails with MissingContextError if the evaluation needs a
+// context variable context does not have, and with ConditionContextError if
+// one does not convert to its type.
+func (c *Condition) Holds(context ConditionContext, now time.Time) (bool, error) {
+	e := conditionEvaluation{condition: c, context: context, now: now}
+	value, err := e.eval(c.root)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+type valueType string
+
+const (
+	unknownType    valueType = ""
+	boolType       valueType = "bool"
+	numberType     valueType = "number"
+	stringType     valueType = "string"
+	timestampType  valueType = "timestamp"
+	durationType   valueType = "duration"
+	ipType         valueType = "ip"
+	cidrType       valueType = "cidr"
+	numberListType valueType = "list of numbers"
+	stringListType valueType = "list of strings"
+)
+
+const nowVariable = "now"
+
+// conversions are the functions of the language, which convert a string.
+var conversions = map[string]valueType{
+	"timestamp": timestampType,
+	"duration":  durationType,
+	"ip":        ipType,
+	"cidr":      cidrType,
+}
+
+type conditionNode struct {
+	// op is the operator, or literal, variable, call or list
+	op       string
+	name     string
+	value    interface{}
+	operands []*conditionNode
+	pos      int
+}
+
+const (
+	literalOp  = "literal"
+	variableOp = "variable"
+	callOp     = "call"
+	listOp     = "list"
+)
+
+type tokenKind int
+
+const (
+	endToken tokenKind = iota
+	numberToken
+	stringToken
+	nameToken
+	operatorToken
+)
+
+type conditionToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type conditionParser struct {
+	input  string
+	tokens []conditionToken
+	pos    int
+	// depth is how deep the node being parsed is nested
+	depth int
+}
+
+func (p *conditionParser) errorf(token conditionToken, format string, args ...interface{}) error {
+	return InvalidConditionError{Reason: fmt.Sprintf("at %d: ", token.pos) + fmt.Sprintf(format, args...)}
+}
+
+var conditionOperators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "(", ")", "[", "]", ","}
+
+func (p *conditionParser) tokenize() error {
+	input := p.input
+	for i := 0; i < len(input); {
+		ch := rune(input[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+			continue
+		case ch == '"':
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return InvalidConditionError{Reason: fmt.Sprintf("at %d: unterminated string", i)}
+			}
+			s, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return InvalidConditionError{Reason: fmt.Sprintf("at %d: invalid string", i)}
+			}
+			p.tokens = append(p.tokens, conditionToken{kind: stringToken, text: s, pos: i})
+			i = end + 1
+			continue
+		case ch >= '0' && ch <= '9' || ch == '.':
+			end := i
+			for end < len(input) && (input[end] >= '0' && input[end] <= '9' || input[end] == '.') {
+				end++
+			}
+			p.tokens = append(p.tokens, conditionToken{kind: numberToken, text: input[i:end], pos: i})
+			i = end
+			continue
+		case isNameByte(input[i]) && !(ch >= '0' && ch <= '9'):
+			end := i
+			for end < len(input) && isNameByte(input[end]) {
+				end++
+			}
+			p.tokens = append(p.tokens, conditionToken{kind: nameToken, text: input[i:end], pos: i})
+			i = end
+			continue
+		}
+		matched := false
+		for _, operator := range conditionOperators {
+			if strings.HasPrefix(input[i:], operator) {
+				p.tokens = append(p.tokens, conditionToken{kind: operatorToken, text: operator, pos: i})
+				i += len(operator)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return InvalidConditionError{Reason: fmt.Sprintf("at %d: unexpected %q", i, ch)}
+		}
+	}
+	p.tokens = append(p.tokens, conditionToken{kind: endToken, text: "end of expression", pos: len(input)})
+	return nil
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+func (p *conditionParser) peek() conditionToken {
+	return p.tokens[p.pos]
+}
+
+func (p *conditionParser) next() conditionToken {
+	token := p.tokens[p.pos]
+	if token.kind != endToken {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the operator or keyword text.
+func (p *conditionParser) accept(text string) bool {
+	token := p.peek()
+	if (token.kind == operatorToken || token.kind == nameToken) && token.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *conditionParser) expect(text string) error {
+	if token := p.peek(); !p.accept(text) {
+		return p.errorf(token, "expected %s, got %s", text, token.text)
+	}
+	return nil
+}
+
+// nest enters a nested node at token, and fails if it is nested deeper than
+// MaxConditionDepth. The caller leaves it with unnest.
+func (p *conditionParser) nest(token conditionToken) error {
+	if p.depth++; p.depth > MaxConditionDepth {
+		return p.errorf(token, "nested deeper than %d", MaxConditionDepth)
+	}
+	return nil
+}
+
+func (p *conditionParser) unnest() {
+	p.depth--
+}
+
+func (p *conditionParser) or() (*conditionNode, error) {
+	return p.binary(p.and, "||")
+}
+
+func (p *conditionParser) and() (*conditionNode, error) {
+	return p.binary(p.comparison, "&&")
+}
+
+// comparison does not chain, a < b < c is an error.
+func (p *conditionParser) comparison() (*conditionNode, error) {
+	left, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	token := p.peek()
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(operator) {
+			right, err := p.sum()
+			if err != nil {
+				return nil, err
+			}
+			return &conditionNode{op: operator, operands: []*conditionNode{left, right}, pos: token.pos}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) sum() (*conditionNode, error) {
+	return p.binary(p.unary, "+", "-")
+}
+
+// binary parses the left associative operators of one precedence.
+func (p *conditionParser) binary(operand func() (*conditionNode, error), operators ...string) (*conditionNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		token := p.peek()
+		matched := ""
+		for _, operator := range operators {
+			if token.kind == operatorToken && token.text == operator {
+				matched = operator
+			}
+		}
+		if matched == "" {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &conditionNode{op: matched, operands: []*conditionNode{left, right}, pos: token.pos}
+	}
+}
+
+func (p *conditionParser) unary() (*conditionNode, error) {
+	token := p.peek()
+	if p.accept("!") || p.accept("-") {
+		defer p.unnest()
+		if err := p.nest(token); err != nil {
+			return nil, err
+		}
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &conditionNode{op: "unary" + token.text, operands: []*conditionNode{operand}, pos: token.pos}, nil
+	}
+	return p.primary()
+}
+
+func (p *conditionParser) primary() (*conditionNode, error) {
+	token := p.next()
+	switch token.kind {
+	case numberToken:
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, p.errorf(token, "invalid number %s", token.text)
+		}
+		return &conditionNode{op: literalOp, value: value, pos: token.pos}, nil
+	case stringToken:
+		return &conditionNode{op: literalOp, value: token.text, pos: token.pos}, nil
+	case nameToken:
+		switch token.text {
+		case "true", "false":
+			return &conditionNode{op: literalOp, value: token.text == "true", pos: token.pos}, nil
+		case "in":
+			return nil, p.errorf(token, "unexpected in")
+		}
+		if !p.accept("(") {
+			return &conditionNode{op: variableOp, name: token.text, pos: token.pos}, nil
+		}
+		if _, ok := conversions[token.text]; !ok {
+			return nil, p.errorf(token, "unknown function %s", token.text)
+		}
+		defer p.unnest()
+		if err := p.nest(token); err != nil {
+			return nil, err
+		}
+		argument, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &conditionNode{op: callOp, name: token.text, operands: []*conditionNode{argument}, pos: token.pos}, nil
+	case operatorToken:
+		if token.text == "(" || token.text == "[" {
+			defer p.unnest()
+			if err := p.nest(token); err != nil {
+				return nil, err
+			}
+		}
+		switch token.text {
+		case "(":
+			node, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			return node, p.expect(")")
+		case "[":
+			node := &conditionNode{op: listOp, pos: token.pos}
+			for !p.accept("]") {
+				if len(node.operands) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				element, err := p.or()
+				if err != nil {
+					return nil, err
+				}
+				node.operands = append(node.operands, element)
+			}
+			return node, nil
+		}
+	}
+	if token.kind == endToken {
+		return nil, p.errorf(token, "unexpected end of expression")
+	}
+	return nil, p.errorf(token, "unexpected %s", token.text)
+}
+
+// conditionChecker infers the types of the variables of an expression and
+// checks its operators. Unless strict, it leaves the types it can not infer
+// yet unknown instead of failing.
+type conditionChecker struct {
+	params map[string]interface{}
+	types  map[string]valueType
+	strict bool
+	// checked memoizes check, which returns the same while no variable is
+	// bound. Without it operands, checking a left operand again with the
+	// type of the right one, would take time exponential in the nesting.
+	checked map[checkKey]checkResult
+}
+
+// checkKey is a check of node with hint, while bound variables were typed.
+type checkKey struct {
+	node  *conditionNode
+	hint  valueType
+	bound int
+}
+
+type checkResult struct {
+	t   valueType
+	err error
+}
+
+func (c *conditionChecker) errorf(node *conditionNode, format string, args ...interface{}) error {
+	return InvalidConditionError{Reason: fmt.Sprintf("at %d: ", node.pos) + fmt.Sprintf(format, args...)}
+}
+
+// check returns the type of node, hint is the type its parent expects if it
+// knows it, which fixes the type of a variable not typed yet.
+func (c *conditionChecker) check(node *conditionNode, hint valueType) (valueType, error) {
+	key := checkKey{node: node, hint: hint, bound: len(c.types)}
+	if result, ok := c.checked[key]; ok {
+		return result.t, result.err
+	}
+	t, err := c.infer(node, hint)
+	c.checked[key] = checkResult{t: t, err: err}
+	return t, err
+}
+
+func (c *conditionChecker) infer(node *conditionNode, hint valueType) (valueType, error) {
+	switch node.op {
+	case literalOp:
+		return typeOf(node.value), nil
+	case variableOp:
+		return c.variable(node, hint)
+	case callOp:
+		t, err := c.check(node.operands[0], stringType)
+		if err != nil || t == unknownType {
+			return unknownType, err
+		}
+		if t != stringType {
+			return unknownType, c.errorf(node, "%s takes a string, not a %s", node.name, t)
+		}
+		return conversions[node.name], nil
+	case listOp:
+		return c.list(node, hint)
+	case "unary!":
+		t, err := c.check(node.operands[0], boolType)
+		if err != nil || t == unknownType {
+			return unknownType, err
+		}
+		if t != boolType {
+			return unknownType, c.errorf(node, "! takes a bool, not a %s", t)
+		}
+		return boolType, nil
+	case "unary-":
+		t, err := c.check(node.operands[0], hint)
+		if err != nil || t == unknownType {
+			return unknownType, c.unresolved(node, err)
+		}
+		if t != numberType && t != durationType {
+			return unknownType, c.errorf(node, "- takes a number or duration, not a %s", t)
+		}
+		return t, nil
+	case "&&", "||":
+		for _, operand := range node.operands {
+			t, err := c.check(operand, boolType)
+			if err != nil {
+				return unknownType, err
+			}
+			if t != boolType && t != unknownType {
+				return unknownType, c.errorf(node, "%s takes bools, not a %s", node.op, t)
+			}
+		}
+		return boolType, nil
+	}
+
+	left, right, err := c.operands(node)
+	if err != nil || left == unknownType || right == unknownType {
+		return unknownType, c.unresolved(node, err)
+	}
+	switch node.op {
+	case "==", "!=":
+		if left != right || left == numberListType || left == stringListType {
+			return unknownType, c.errorf(node, "can not compare a %s with a %s", left, right)
+		}
+		return boolType, nil
+	case "<", "<=", ">", ">=":
+		if left != right || left != numberType && left != stringType && left != timestampType && left != durationType {
+			return unknownType, c.errorf(node, "can not order a %s and a %s", left, right)
+		}
+		return boolType, nil
+	case "in":
+		if elementType(right) != left {
+			return unknownType, c.errorf(node, "can not test a %s in a %s", left, right)
+		}
+		return boolType, nil
+	}
+	if t, ok := arithmetic(node.op, left, right); ok {
+		return t, nil
+	}
+	return unknownType, c.errorf(node, "can not apply %s to a %s and a %s", node.op, left, right)
+}
+
+// operands checks the operands of a binary operator other than && and ||,
+// each hinting the type of the other.
+func (c *conditionChecker) operands(node *conditionNode) (valueType, valueType, error) {
+	left, err := c.check(node.operands[0], unknownType)
+	if err != nil {
+		return unknownType, unknownType, err
+	}
+	right, err := c.check(node.operands[1], otherOperand(node.op, left, true))
+	if err != nil {
+		return unknownType, unknownType, err
+	}
+	if left == unknownType && right != unknownType {
+		left, err = c.check(node.operands[0], otherOperand(node.op, right, false))
+	}
+	return left, right, err
+}
+
+// otherOperand returns the type the other operand of op likely has, given
+// the type of the left operand, or of the right if left is false.
+func otherOperand(op string, t valueType, left bool) valueType {
+	switch {
+	case t == unknownType:
+		return unknownType
+	case op == "in" && left:
+		switch t {
+		case ipType:
+			return cidrType
+		case numberType:
+			return numberListType
+		case stringType:
+			return stringListType
+		}
+		return unknownType
+	case op == "in":
+		return elementType(t)
+	case (op == "+" || op == "-") && t == timestampType:
+		return durationType
+	}
+	return t
+}
+
+// unresolved reports err, or in a strict check that the types of the
+// operands of node are left open.
+func (c *conditionChecker) unresolved(node *conditionNode, err error) error {
+	if err != nil || !c.strict {
+		return err
+	}
+	return c.errorf(node, "can not infer the types of the operands of %s, convert one of them", strings.TrimPrefix(node.op, "unary"))
+}
+
+func (c *conditionChecker) variable(node *conditionNode, hint valueType) (valueType, error) {
+	if node.name == nowVariable {
+		return timestampType, nil
+	}
+	if t, ok := c.types[node.name]; ok {
+		return t, nil
+	}
+	if hint == unknownType {
+		value, ok := c.params[node.name]
+		if !ok {
+			return unknownType, nil
+		}
+		if hint = typeOf(value); hint == unknownType {
+			return unknownType, c.errorf(node, "param %s has no type of the language", node.name)
+		}
+	}
+	c.types[node.name] = hint
+	return hint, nil
+}
+
+func (c *conditionChecker) list(node *conditionNode, hint valueType) (valueType, error) {
+	element := elementType(hint)
+	for _, operand := range node.operands {
+		t, err := c.check(operand, element)
+		if err != nil {
+			return unknownType, err
+		}
+		if t == unknownType {
+			continue
+		}
+		if element != unknownType && t != element || t != numberType && t != stringType {
+			return unknownType, c.errorf(node, "lists hold numbers or strings of one type")
+		}
+		element = t
+	}
+	switch element {
+	case numberType:
+		return numberListType, nil
+	case stringType:
+		return stringListType, nil
+	}
+	return unknownType, nil
+}
+
+// arithmetic returns the type of left op right for + and -.
+func arithmetic(op string, left, right valueType) (valueType, bool) {
+	switch {
+	case left == numberType && right == numberType,
+		left == durationType && right == durationType:
+		return left, true
+	case op == "+" && left == stringType && right == stringType:
+		return stringType, true
+	case left == timestampType && right == durationType:
+		return timestampType, true
+	case op == "-" && left == timestampType && right == timestampType:
+		return durationType, true
+	}
+	return unknownType, false
+}
+
+func elementType(t valueType) valueType {
+	switch t {
+	case numberListType:
+		return numberType
+	case stringListType:
+		return stringType
+	case cidrType:
+		return ipType
+	}
+	return unknownType
+}
+
+// typeOf returns the type of a value of the language, or of a JSON value
+// before any conversion.
+func typeOf(value interface{}) valueType {
+	switch value := value.(type) {
+	case bool:
+		return boolType
+	case float64:
+		return numberType
+	case string:
+		return stringType
+	case time.Time:
+		return timestampType
+	case time.Duration:
+		return durationType
+	case netip.Addr:
+		return ipType
+	case netip.Prefix:
+		return cidrType
+	case []float64:
+		return numberListType
+	case []string:
+		return stringListType
+	case []interface{}:
+		if len(value) == 0 {
+			return stringListType
+		}
+		t := typeOf(value[0])
+		for _, element := range value[1:] {
+			if typeOf(element) != t {
+				return unknownType
+			}
+		}
+		switch t {
+		case numberType:
+			return numberListType
+		case stringType:
+			return stringListType
+		}
+	}
+	return unknownType
+}
+
+// convertValue converts a JSON value to a value of type t.
+func convertValue(value interface{}, t valueType) (interface{}, error) {
+	mismatch := fmt.Errorf("want a %s", t)
+	switch t {
+	case boolType, numberType:
+		if typeOf(value) != t {
+			return nil, mismatch
+		}
+		return value, nil
+	case numberListType, stringListType:
+		values, ok := value.([]interface{})
+		if !ok || typeOf(value) != t {
+			return nil, mismatch
+		}
+		if t == numberListType {
+			numbers := make([]float64, len(values))
+			for i, v := range values {
+				numbers[i] = v.(float64)
+			}
+			return numbers, nil
+		}
+		strs := make([]string, len(values))
+		for i, v := range values {
+			strs[i] = v.(string)
+		}
+		return strs, nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, mismatch
+	}
+	return convertString(s, t)
+}
+
+func convertString(s string, t valueType) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch t {
+	case stringType:
+		return s, nil
+	case timestampType:
+		value, err = time.Parse(time.RFC3339, s)
+	case durationType:
+		value, err = time.ParseDuration(s)
+	case ipType:
+		var addr netip.Addr
+		addr, err = netip.ParseAddr(s)
+		value = addr.Unmap()
+	case cidrType:
+		var prefix netip.Prefix
+		prefix, err = netip.ParsePrefix(s)
+		value = prefix.Masked()
+	default:
+		return nil, fmt.Errorf("want a %s", t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a %s", s, t)
+	}
+	return value, nil
+}
+
+type conditionEvaluation struct {
+	condition *Condition
+	context   ConditionContext
+	now       time.Time
+}
+
+// eval evaluates a node the checker typed, so its operands have the types
+// its operator takes.
+func (e *conditionEvaluation) eval(node *conditionNode) (interface{}, error) {
+	switch node.op {
+	case literalOp:
+		return node.value, nil
+	case variableOp:
+		return e.variable(node.name)
+	case listOp:
+		values := make([]interface{}, len(node.operands))
+		for i, operand := range node.operands {
+			value, err := e.eval(operand)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return convertValue(values, typeOf(values))
+	case "&&", "||":
+		left, err := e.eval(node.operands[0])
+		if err != nil {
+			return nil, err
+		}
+		if left.(bool) == (node.op == "||") {
+			return left, nil
+		}
+		return e.eval(node.operands[1])
+	}
+
+	operands := make([]interface{}, len(node.operands))
+	for i, operand := range node.operands {
+		value, err := e.eval(operand)
+		if err != nil {
+			return nil, err
+		}
+		operands[i] = value
+	}
+	switch node.op {
+	case callOp:
+		value, err := convertString(operands[0].(string), conversions[node.name])
+		if err != nil {
+			return nil, ConditionContextError{Reason: err.Error()}
+		}
+		return value, nil
+	case "unary!":
+		return !operands[0].(bool), nil
+	case "unary-":
+		if d, ok := operands[0].(time.Duration); ok {
+			return -d, nil
+		}
+		return -operands[0].(float64), nil
+	case "==":
+		return equal(operands[0], operands[1]), nil
+	case "!=":
+		return !equal(operands[0], operands[1]), nil
+	case "<", "<=", ">", ">=":
+		return order(node.op, compare(operands[0], operands[1])), nil
+	case "in":
+		return contains(operands[1], operands[0]), nil
+	case "+":
+		switch left := operands[0].(type) {
+		case float64:
+			return left + operands[1].(float64), nil
+		case string:
+			return left + operands[1].(string), nil
+		case time.Time:
+			return left.Add(operands[1].(time.Duration)), nil
+		case time.Duration:
+			return left + operands[1].(time.Duration), nil
+		}
+	case "-":
+		switch left := operands[0].(type) {
+		case float64:
+			return left - operands[1].(float64), nil
+		case time.Time:
+			if right, ok := operands[1].(time.Time); ok {
+				return left.Sub(right), nil
+			}
+			return left.Add(-operands[1].(time.Duration)), nil
+		case time.Duration:
+			return left - operands[1].(time.Duration), nil
+		}
+	}
+	return nil, fmt.Errorf("can not evaluate %s", node.op)
+}
+
+func (e *conditionEvaluation) variable(name string) (interface{}, error) {
+	if name == nowVariable {
+		return e.now, nil
+	}
+	if value, ok := e.condition.params[name]; ok {
+		return value, nil
+	}
+	value, ok := e.context[name]
+	if !ok {
+		return nil, MissingContextError{Name: name}
+	}
+	t := e.condition.contextTypes[name]
+	converted, err := convertValue(value, t)
+	if err != nil {
+		return nil, ConditionContextError{Reason: fmt.Sprintf("%s: %s", name, err.Error())}
+	}
+	return converted, nil
+}
+
+func equal(a, b interface{}) bool {
+	if a, ok := a.(time.Time); ok {
+		return a.Equal(b.(time.Time))
+	}
+	return a == b
+}
+
+// compare returns -1, 0 or 1 as a is before, equal to or after b.
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case float64:
+		b := b.(float64)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case time.Duration:
+		b := b.(time.Duration)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+func order(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func contains(collection, value interface{}) bool {
+	switch collection := collection.(type) {
+	case netip.Prefix:
+		return collection.Contains(value.(netip.Addr))
+	case []float64:
+		for _, element := range collection {
+			if element == value {
+				return true
+			}
+		}
+	case []string:
+		for _, element := range collection {
+			if element == value {
+				return true
+			}
+		}
+	}
+	return false
+}
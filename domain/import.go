@@ -0,0 +1,37 @@
+package domain
+
+// Phases of a bulk import, in order. The relations are validated against the
+// graph, then committing in the transaction of the import, which checks
+// again what changed since.
+const (
+	ImportStaging    = "staging"
+	ImportValidating = "validating"
+	ImportCommitting = "committing"
+)
+
+// ImportProgress reports how far a bulk import got. Staged counts the valid
+// relations read so far, repeated ones included.
+type ImportProgress struct {
+	Phase  string `json:"phase"`
+	Lines  int    `json:"lines"`
+	Staged int    `json:"staged"`
+}
+
+// ImportLineError is the error of one skipped line of a bulk import, lines
+// count from 1.
+type ImportLineError struct {
+	Line int    `json:"line"`
+	Err  string `json:"error"`
+}
+
+type ImportResult struct {
+	Lines int `json:"lines"`
+	// Imported counts the created relations, the others existed already or
+	// were repeated in the import.
+	Imported int `json:"imported"`
+	// ErrorCount counts all skipped lines, Errors holds only the first ones.
+	ErrorCount int               `json:"error_count"`
+	Errors     []ImportLineError `json:"errors"`
+	// Token is the consistency token of the import.
+	Token string `json:"token,omitempty"`
+}
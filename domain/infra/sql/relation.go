@@ -1,29 +1,165 @@
 package sqldom
 
 import (
+	"context"
+	"time"
+
 	"github.com/skyrocketOoO/zanazibar-dag/domain"
 )
 
 type Relation struct {
 	ID               uint   `gorm:"primarykey"`
-	AllColumns       string `gorm:"index"`
+	AllColumns       string `gorm:"uniqueIndex:uidx_relations_all_columns,where:deleted_rev IS NULL"`
 	ObjectNamespace  string `gorm:"index:idx_object"`
 	ObjectName       string `gorm:"index:idx_object"`
 	Relation         string `gorm:"index:idx_object"`
 	SubjectNamespace string `gorm:"index:idx_subject"`
 	SubjectName      string `gorm:"index:idx_subject"`
 	SubjectRelation  string `gorm:"index:idx_subject"`
+	Condition        string
+	ConditionParams  string
+	// ExpiresAt is nil for a relation that does not expire.
+	ExpiresAt *time.Time
+	// CreatedRev and DeletedRev are the revisions the relation was created
+	// and deleted at. Deleted relations are kept for snapshot reads until
+	// they are older than the snapshot retention.
+	CreatedRev uint64
+	DeletedRev *uint64
+	DeletedAt  *time.Time
 }
 
+// RelationRepository stores the relations. Its methods stop waiting on the
+// storage once ctx is done.
 type RelationRepository interface {
-	Create(relation domain.Relation) error
-	Delete(relation domain.Relation) error
-	DeleteByQueries(queries []domain.Relation) error
-	BatchOperation(operations []domain.Operation) error
-	GetAll(options ...PageOptions) (relations []domain.Relation, lastID uint, err error)
-	Query(query domain.Relation) ([]domain.Relation, error)
-	GetAllNamespaces() ([]string, error)
-	DeleteAll() error
+	// Create returns domain.AlreadyExistsError if the relation exists.
+	Create(ctx context.Context, relation domain.Relation) error
+	// CreateIfNotExists is Create which does nothing if the relation exists.
+	CreateIfNotExists(ctx context.Context, relation domain.Relation) error
+	Delete(ctx context.Context, relation domain.Relation) error
+	DeleteByQueries(ctx context.Context, queries []domain.Relation) error
+	BatchOperation(ctx context.Context, operations []domain.Operation) error
+	GetAll(ctx context.Context, options ...PageOptions) (relations []domain.Relation, lastID uint, err error)
+	Query(ctx context.Context, query domain.Relation) ([]domain.Relation, error)
+	// QueryBySubjects returns the relations whose subject is any of subjects,
+	// so a whole BFS frontier costs one call. An empty node relation matches
+	// every relation, like an empty field of Query.
+	QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error)
+	// QueryByObjects is QueryBySubjects for the object side.
+	QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error)
+	GetAllNamespaces(ctx context.Context) ([]string, error)
+	DeleteAll(ctx context.Context) error
+	// WithTransaction runs fn with a repository bound to one transaction,
+	// which commits if fn returns nil and rolls back otherwise. Transactions
+	// are serialized against each other, also across server replicas, so a
+	// check made inside one still holds when it commits.
+	WithTransaction(ctx context.Context, fn func(tx RelationRepository) error) error
+}
+
+// DeleteTransactionRepository is implemented by backends that can run a
+// transaction which only deletes without serializing it against the
+// others. A delete closes no cycle and makes no check of another
+// transaction wrong, so fn may query and delete but must not create.
+type DeleteTransactionRepository interface {
+	WithDeleteTransaction(ctx context.Context, fn func(tx RelationRepository) error) error
+}
+
+// ReachabilityRepository is implemented by backends that can search the
+// graph inside the storage engine, instead of one Query per visited node.
+// A maxDepth of 0 or less means no depth limit. The searches cross relations
+// whatever their condition, as cycle checks must.
+type ReachabilityRepository interface {
+	Reachable(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (bool, error)
+	// Search is the search of a read, like Reachable with the shortest path
+	// if path is set. It reports whether it met relations the read must
+	// evaluate itself, see SearchResult.
+	Search(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int, path bool) (SearchResult, error)
+}
+
+// SearchResult is the outcome of Search. The relations it met are those
+// leaving subject or a node it reached.
+type SearchResult struct {
+	// Found reports whether object was reached, Path is the shortest path
+	// to it if it was asked for.
+	Found bool
+	Path  []domain.Relation
+	// Conditional reports whether the search met a relation with a
+	// condition, which it can not evaluate, so Found and Path do not answer
+	// the read. Expiring reports whether it met one with an expiry, which
+	// changes the answer once it expires.
+	Conditional bool
+	Expiring    bool
+}
+
+// ExpiryRepository is implemented by backends that find the expired
+// relations without a scan of all of them. Reads otherwise do not see
+// expired relations, so this is how a sweeper finds what to delete.
+type ExpiryRepository interface {
+	// QueryExpired returns up to limit relations expired at now, those
+	// expired first first.
+	QueryExpired(ctx context.Context, now time.Time, limit int) ([]domain.Relation, error)
+}
+
+// PrimaryRepository is implemented by backends that serve reads from
+// replicas. Primary returns a repository reading from the primary, for reads
+// that must see the writes made before them.
+type PrimaryRepository interface {
+	Primary() RelationRepository
+}
+
+// BulkImportRepository is implemented by backends with a faster path than one
+// insert per relation. Relations are staged under an import id outside of any
+// transaction, and merged into the graph at once inside one. Those backends
+// number their writes, see RevisionRepository.
+type BulkImportRepository interface {
+	// StageImport stages relations as the ones of the import from line on,
+	// counting from 0.
+	StageImport(ctx context.Context, importID string, line int, relations []domain.Relation) error
+	// CommitImport creates the staged relations that do not exist yet, of
+	// repeated ones the first, and returns how many were created.
+	CommitImport(ctx context.Context, importID string) (created int, err error)
+	// ReadImport returns up to limit staged relations from line on.
+	ReadImport(ctx context.Context, importID string, line int, limit int) ([]domain.Relation, error)
+	// ImportGraph returns a repository whose QueryBySubjects and
+	// QueryByObjects read the graph as if the import were committed, so it
+	// can be checked before the transaction that commits it.
+	ImportGraph(importID string) RelationRepository
+	// CreatedSince returns the live relations created after revision, the
+	// changes a check made as of revision did not see.
+	CreatedSince(ctx context.Context, revision uint64) ([]domain.Relation, error)
+	DropImport(ctx context.Context, importID string) error
+}
+
+// RevisionRepository is implemented by backends that number their writes.
+// Every write transaction commits as the next revision.
+type RevisionRepository interface {
+	// Revision returns the revision of the last write the repository sees,
+	// inside a transaction that wrote it is the revision it commits as.
+	Revision(ctx context.Context) (uint64, error)
+}
+
+// SnapshotRepository is implemented by backends that keep the history of
+// recent revisions. The returned repositories are for reads only.
+type SnapshotRepository interface {
+	// AtLeast returns a repository reading a graph at least as fresh as
+	// revision, a replica that caught up with it or else the primary.
+	AtLeast(ctx context.Context, revision uint64) (RelationRepository, error)
+	// AtRevision returns a repository reading the relations exactly as of
+	// revision, with the relations that had not expired when it was written.
+	// It fails with
+	// domain.SnapshotUnavailableError if the history of the revision was
+	// collected already.
+	AtRevision(ctx context.Context, revision uint64) (RelationRepository, error)
+}
+
+// SchemaRepository is implemented by backends that store the namespace
+// schema. Every write keeps the previous versions.
+type SchemaRepository interface {
+	// ReadSchema returns the latest version of the schema, the empty version
+	// 0 if none was written.
+	ReadSchema(ctx context.Context) (domain.Schema, error)
+	// WriteSchema stores namespaces as the next version of the schema and
+	// returns it.
+	WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error)
 }
 
 type PageOptions struct {
@@ -5,7 +5,9 @@
 package sqldom
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	domain "github.com/skyrocketOoO/zanazibar-dag/domain"
@@ -35,79 +37,93 @@ func (m *MockRelationRepository) EXPECT() *MockRelationRepositoryMockRecorder {
 }
 
 // BatchOperation mocks base method.
-func (m *MockRelationRepository) BatchOperation(operations []domain.Operation) error {
+func (m *MockRelationRepository) BatchOperation(ctx context.Context, operations []domain.Operation) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BatchOperation", operations)
+	ret := m.ctrl.Call(m, "BatchOperation", ctx, operations)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // BatchOperation indicates an expected call of BatchOperation.
-func (mr *MockRelationRepositoryMockRecorder) BatchOperation(operations interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) BatchOperation(ctx, operations interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchOperation", reflect.TypeOf((*MockRelationRepository)(nil).BatchOperation), operations)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchOperation", reflect.TypeOf((*MockRelationRepository)(nil).BatchOperation), ctx, operations)
 }
 
 // Create mocks base method.
-func (m *MockRelationRepository) Create(relation domain.Relation) error {
+func (m *MockRelationRepository) Create(ctx context.Context, relation domain.Relation) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", relation)
+	ret := m.ctrl.Call(m, "Create", ctx, relation)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockRelationRepositoryMockRecorder) Create(relation interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) Create(ctx, relation interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRelationRepository)(nil).Create), relation)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRelationRepository)(nil).Create), ctx, relation)
+}
+
+// CreateIfNotExists mocks base method.
+func (m *MockRelationRepository) CreateIfNotExists(ctx context.Context, relation domain.Relation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIfNotExists", ctx, relation)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIfNotExists indicates an expected call of CreateIfNotExists.
+func (mr *MockRelationRepositoryMockRecorder) CreateIfNotExists(ctx, relation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIfNotExists", reflect.TypeOf((*MockRelationRepository)(nil).CreateIfNotExists), ctx, relation)
 }
 
 // Delete mocks base method.
-func (m *MockRelationRepository) Delete(relation domain.Relation) error {
+func (m *MockRelationRepository) Delete(ctx context.Context, relation domain.Relation) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", relation)
+	ret := m.ctrl.Call(m, "Delete", ctx, relation)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockRelationRepositoryMockRecorder) Delete(relation interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) Delete(ctx, relation interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRelationRepository)(nil).Delete), relation)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRelationRepository)(nil).Delete), ctx, relation)
 }
 
 // DeleteAll mocks base method.
-func (m *MockRelationRepository) DeleteAll() error {
+func (m *MockRelationRepository) DeleteAll(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAll")
+	ret := m.ctrl.Call(m, "DeleteAll", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteAll indicates an expected call of DeleteAll.
-func (mr *MockRelationRepositoryMockRecorder) DeleteAll() *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) DeleteAll(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockRelationRepository)(nil).DeleteAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAll", reflect.TypeOf((*MockRelationRepository)(nil).DeleteAll), ctx)
 }
 
 // DeleteByQueries mocks base method.
-func (m *MockRelationRepository) DeleteByQueries(queries []domain.Relation) error {
+func (m *MockRelationRepository) DeleteByQueries(ctx context.Context, queries []domain.Relation) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteByQueries", queries)
+	ret := m.ctrl.Call(m, "DeleteByQueries", ctx, queries)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteByQueries indicates an expected call of DeleteByQueries.
-func (mr *MockRelationRepositoryMockRecorder) DeleteByQueries(queries interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) DeleteByQueries(ctx, queries interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByQueries", reflect.TypeOf((*MockRelationRepository)(nil).DeleteByQueries), queries)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByQueries", reflect.TypeOf((*MockRelationRepository)(nil).DeleteByQueries), ctx, queries)
 }
 
 // GetAll mocks base method.
-func (m *MockRelationRepository) GetAll(options ...PageOptions) ([]domain.Relation, uint, error) {
+func (m *MockRelationRepository) GetAll(ctx context.Context, options ...PageOptions) ([]domain.Relation, uint, error) {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{}
+	varargs := []interface{}{ctx}
 	for _, a := range options {
 		varargs = append(varargs, a)
 	}
@@ -119,37 +135,501 @@ func (m *MockRelationRepository) GetAll(options ...PageOptions) ([]domain.Relati
 }
 
 // GetAll indicates an expected call of GetAll.
-func (mr *MockRelationRepositoryMockRecorder) GetAll(options ...interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) GetAll(ctx interface{}, options ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRelationRepository)(nil).GetAll), options...)
+	varargs := append([]interface{}{ctx}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockRelationRepository)(nil).GetAll), varargs...)
 }
 
 // GetAllNamespaces mocks base method.
-func (m *MockRelationRepository) GetAllNamespaces() ([]string, error) {
+func (m *MockRelationRepository) GetAllNamespaces(ctx context.Context) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllNamespaces")
+	ret := m.ctrl.Call(m, "GetAllNamespaces", ctx)
 	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllNamespaces indicates an expected call of GetAllNamespaces.
-func (mr *MockRelationRepositoryMockRecorder) GetAllNamespaces() *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) GetAllNamespaces(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNamespaces", reflect.TypeOf((*MockRelationRepository)(nil).GetAllNamespaces))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllNamespaces", reflect.TypeOf((*MockRelationRepository)(nil).GetAllNamespaces), ctx)
 }
 
 // Query mocks base method.
-func (m *MockRelationRepository) Query(query domain.Relation) ([]domain.Relation, error) {
+func (m *MockRelationRepository) Query(ctx context.Context, query domain.Relation) ([]domain.Relation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Query", query)
+	ret := m.ctrl.Call(m, "Query", ctx, query)
 	ret0, _ := ret[0].([]domain.Relation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Query indicates an expected call of Query.
-func (mr *MockRelationRepositoryMockRecorder) Query(query interface{}) *gomock.Call {
+func (mr *MockRelationRepositoryMockRecorder) Query(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockRelationRepository)(nil).Query), ctx, query)
+}
+
+// QueryByObjects mocks base method.
+func (m *MockRelationRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryByObjects", ctx, objects)
+	ret0, _ := ret[0].([]domain.Relation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryByObjects indicates an expected call of QueryByObjects.
+func (mr *MockRelationRepositoryMockRecorder) QueryByObjects(ctx, objects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryByObjects", reflect.TypeOf((*MockRelationRepository)(nil).QueryByObjects), ctx, objects)
+}
+
+// QueryBySubjects mocks base method.
+func (m *MockRelationRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryBySubjects", ctx, subjects)
+	ret0, _ := ret[0].([]domain.Relation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryBySubjects indicates an expected call of QueryBySubjects.
+func (mr *MockRelationRepositoryMockRecorder) QueryBySubjects(ctx, subjects interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryBySubjects", reflect.TypeOf((*MockRelationRepository)(nil).QueryBySubjects), ctx, subjects)
+}
+
+// WithTransaction mocks base method.
+func (m *MockRelationRepository) WithTransaction(ctx context.Context, fn func(RelationRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTransaction indicates an expected call of WithTransaction.
+func (mr *MockRelationRepositoryMockRecorder) WithTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTransaction", reflect.TypeOf((*MockRelationRepository)(nil).WithTransaction), ctx, fn)
+}
+
+// MockDeleteTransactionRepository is a mock of DeleteTransactionRepository interface.
+type MockDeleteTransactionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeleteTransactionRepositoryMockRecorder
+}
+
+// MockDeleteTransactionRepositoryMockRecorder is the mock recorder for MockDeleteTransactionRepository.
+type MockDeleteTransactionRepositoryMockRecorder struct {
+	mock *MockDeleteTransactionRepository
+}
+
+// NewMockDeleteTransactionRepository creates a new mock instance.
+func NewMockDeleteTransactionRepository(ctrl *gomock.Controller) *MockDeleteTransactionRepository {
+	mock := &MockDeleteTransactionRepository{ctrl: ctrl}
+	mock.recorder = &MockDeleteTransactionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeleteTransactionRepository) EXPECT() *MockDeleteTransactionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// WithDeleteTransaction mocks base method.
+func (m *MockDeleteTransactionRepository) WithDeleteTransaction(ctx context.Context, fn func(RelationRepository) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithDeleteTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithDeleteTransaction indicates an expected call of WithDeleteTransaction.
+func (mr *MockDeleteTransactionRepositoryMockRecorder) WithDeleteTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithDeleteTransaction", reflect.TypeOf((*MockDeleteTransactionRepository)(nil).WithDeleteTransaction), ctx, fn)
+}
+
+// MockReachabilityRepository is a mock of ReachabilityRepository interface.
+type MockReachabilityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReachabilityRepositoryMockRecorder
+}
+
+// MockReachabilityRepositoryMockRecorder is the mock recorder for MockReachabilityRepository.
+type MockReachabilityRepositoryMockRecorder struct {
+	mock *MockReachabilityRepository
+}
+
+// NewMockReachabilityRepository creates a new mock instance.
+func NewMockReachabilityRepository(ctrl *gomock.Controller) *MockReachabilityRepository {
+	mock := &MockReachabilityRepository{ctrl: ctrl}
+	mock.recorder = &MockReachabilityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReachabilityRepository) EXPECT() *MockReachabilityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Reachable mocks base method.
+func (m *MockReachabilityRepository) Reachable(ctx context.Context, subject, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reachable", ctx, subject, object, searchCondition, maxDepth)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reachable indicates an expected call of Reachable.
+func (mr *MockReachabilityRepositoryMockRecorder) Reachable(ctx, subject, object, searchCondition, maxDepth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reachable", reflect.TypeOf((*MockReachabilityRepository)(nil).Reachable), ctx, subject, object, searchCondition, maxDepth)
+}
+
+// Search mocks base method.
+func (m *MockReachabilityRepository) Search(ctx context.Context, subject, object domain.Node, searchCondition domain.SearchCondition, maxDepth int, path bool) (SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, subject, object, searchCondition, maxDepth, path)
+	ret0, _ := ret[0].(SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockReachabilityRepositoryMockRecorder) Search(ctx, subject, object, searchCondition, maxDepth, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockReachabilityRepository)(nil).Search), ctx, subject, object, searchCondition, maxDepth, path)
+}
+
+// MockExpiryRepository is a mock of ExpiryRepository interface.
+type MockExpiryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpiryRepositoryMockRecorder
+}
+
+// MockExpiryRepositoryMockRecorder is the mock recorder for MockExpiryRepository.
+type MockExpiryRepositoryMockRecorder struct {
+	mock *MockExpiryRepository
+}
+
+// NewMockExpiryRepository creates a new mock instance.
+func NewMockExpiryRepository(ctrl *gomock.Controller) *MockExpiryRepository {
+	mock := &MockExpiryRepository{ctrl: ctrl}
+	mock.recorder = &MockExpiryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpiryRepository) EXPECT() *MockExpiryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// QueryExpired mocks base method.
+func (m *MockExpiryRepository) QueryExpired(ctx context.Context, now time.Time, limit int) ([]domain.Relation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryExpired", ctx, now, limit)
+	ret0, _ := ret[0].([]domain.Relation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryExpired indicates an expected call of QueryExpired.
+func (mr *MockExpiryRepositoryMockRecorder) QueryExpired(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryExpired", reflect.TypeOf((*MockExpiryRepository)(nil).QueryExpired), ctx, now, limit)
+}
+
+// MockPrimaryRepository is a mock of PrimaryRepository interface.
+type MockPrimaryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrimaryRepositoryMockRecorder
+}
+
+// MockPrimaryRepositoryMockRecorder is the mock recorder for MockPrimaryRepository.
+type MockPrimaryRepositoryMockRecorder struct {
+	mock *MockPrimaryRepository
+}
+
+// NewMockPrimaryRepository creates a new mock instance.
+func NewMockPrimaryRepository(ctrl *gomock.Controller) *MockPrimaryRepository {
+	mock := &MockPrimaryRepository{ctrl: ctrl}
+	mock.recorder = &MockPrimaryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrimaryRepository) EXPECT() *MockPrimaryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Primary mocks base method.
+func (m *MockPrimaryRepository) Primary() RelationRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Primary")
+	ret0, _ := ret[0].(RelationRepository)
+	return ret0
+}
+
+// Primary indicates an expected call of Primary.
+func (mr *MockPrimaryRepositoryMockRecorder) Primary() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Primary", reflect.TypeOf((*MockPrimaryRepository)(nil).Primary))
+}
+
+// MockBulkImportRepository is a mock of BulkImportRepository interface.
+type MockBulkImportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBulkImportRepositoryMockRecorder
+}
+
+// MockBulkImportRepositoryMockRecorder is the mock recorder for MockBulkImportRepository.
+type MockBulkImportRepositoryMockRecorder struct {
+	mock *MockBulkImportRepository
+}
+
+// NewMockBulkImportRepository creates a new mock instance.
+func NewMockBulkImportRepository(ctrl *gomock.Controller) *MockBulkImportRepository {
+	mock := &MockBulkImportRepository{ctrl: ctrl}
+	mock.recorder = &MockBulkImportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBulkImportRepository) EXPECT() *MockBulkImportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CommitImport mocks base method.
+func (m *MockBulkImportRepository) CommitImport(ctx context.Context, importID string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitImport", ctx, importID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitImport indicates an expected call of CommitImport.
+func (mr *MockBulkImportRepositoryMockRecorder) CommitImport(ctx, importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitImport", reflect.TypeOf((*MockBulkImportRepository)(nil).CommitImport), ctx, importID)
+}
+
+// CreatedSince mocks base method.
+func (m *MockBulkImportRepository) CreatedSince(ctx context.Context, revision uint64) ([]domain.Relation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatedSince", ctx, revision)
+	ret0, _ := ret[0].([]domain.Relation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatedSince indicates an expected call of CreatedSince.
+func (mr *MockBulkImportRepositoryMockRecorder) CreatedSince(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatedSince", reflect.TypeOf((*MockBulkImportRepository)(nil).CreatedSince), ctx, revision)
+}
+
+// DropImport mocks base method.
+func (m *MockBulkImportRepository) DropImport(ctx context.Context, importID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropImport", ctx, importID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropImport indicates an expected call of DropImport.
+func (mr *MockBulkImportRepositoryMockRecorder) DropImport(ctx, importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropImport", reflect.TypeOf((*MockBulkImportRepository)(nil).DropImport), ctx, importID)
+}
+
+// ImportGraph mocks base method.
+func (m *MockBulkImportRepository) ImportGraph(importID string) RelationRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportGraph", importID)
+	ret0, _ := ret[0].(RelationRepository)
+	return ret0
+}
+
+// ImportGraph indicates an expected call of ImportGraph.
+func (mr *MockBulkImportRepositoryMockRecorder) ImportGraph(importID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportGraph", reflect.TypeOf((*MockBulkImportRepository)(nil).ImportGraph), importID)
+}
+
+// ReadImport mocks base method.
+func (m *MockBulkImportRepository) ReadImport(ctx context.Context, importID string, line, limit int) ([]domain.Relation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadImport", ctx, importID, line, limit)
+	ret0, _ := ret[0].([]domain.Relation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadImport indicates an expected call of ReadImport.
+func (mr *MockBulkImportRepositoryMockRecorder) ReadImport(ctx, importID, line, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadImport", reflect.TypeOf((*MockBulkImportRepository)(nil).ReadImport), ctx, importID, line, limit)
+}
+
+// StageImport mocks base method.
+func (m *MockBulkImportRepository) StageImport(ctx context.Context, importID string, line int, relations []domain.Relation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StageImport", ctx, importID, line, relations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StageImport indicates an expected call of StageImport.
+func (mr *MockBulkImportRepositoryMockRecorder) StageImport(ctx, importID, line, relations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StageImport", reflect.TypeOf((*MockBulkImportRepository)(nil).StageImport), ctx, importID, line, relations)
+}
+
+// MockRevisionRepository is a mock of RevisionRepository interface.
+type MockRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevisionRepositoryMockRecorder
+}
+
+// MockRevisionRepositoryMockRecorder is the mock recorder for MockRevisionRepository.
+type MockRevisionRepositoryMockRecorder struct {
+	mock *MockRevisionRepository
+}
+
+// NewMockRevisionRepository creates a new mock instance.
+func NewMockRevisionRepository(ctrl *gomock.Controller) *MockRevisionRepository {
+	mock := &MockRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevisionRepository) EXPECT() *MockRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Revision mocks base method.
+func (m *MockRevisionRepository) Revision(ctx context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revision", ctx)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revision indicates an expected call of Revision.
+func (mr *MockRevisionRepositoryMockRecorder) Revision(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revision", reflect.TypeOf((*MockRevisionRepository)(nil).Revision), ctx)
+}
+
+// MockSnapshotRepository is a mock of SnapshotRepository interface.
+type MockSnapshotRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnapshotRepositoryMockRecorder
+}
+
+// MockSnapshotRepositoryMockRecorder is the mock recorder for MockSnapshotRepository.
+type MockSnapshotRepositoryMockRecorder struct {
+	mock *MockSnapshotRepository
+}
+
+// NewMockSnapshotRepository creates a new mock instance.
+func NewMockSnapshotRepository(ctrl *gomock.Controller) *MockSnapshotRepository {
+	mock := &MockSnapshotRepository{ctrl: ctrl}
+	mock.recorder = &MockSnapshotRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnapshotRepository) EXPECT() *MockSnapshotRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AtLeast mocks base method.
+func (m *MockSnapshotRepository) AtLeast(ctx context.Context, revision uint64) (RelationRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AtLeast", ctx, revision)
+	ret0, _ := ret[0].(RelationRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AtLeast indicates an expected call of AtLeast.
+func (mr *MockSnapshotRepositoryMockRecorder) AtLeast(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AtLeast", reflect.TypeOf((*MockSnapshotRepository)(nil).AtLeast), ctx, revision)
+}
+
+// AtRevision mocks base method.
+func (m *MockSnapshotRepository) AtRevision(ctx context.Context, revision uint64) (RelationRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AtRevision", ctx, revision)
+	ret0, _ := ret[0].(RelationRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AtRevision indicates an expected call of AtRevision.
+func (mr *MockSnapshotRepositoryMockRecorder) AtRevision(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AtRevision", reflect.TypeOf((*MockSnapshotRepository)(nil).AtRevision), ctx, revision)
+}
+
+// MockSchemaRepository is a mock of SchemaRepository interface.
+type MockSchemaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchemaRepositoryMockRecorder
+}
+
+// MockSchemaRepositoryMockRecorder is the mock recorder for MockSchemaRepository.
+type MockSchemaRepositoryMockRecorder struct {
+	mock *MockSchemaRepository
+}
+
+// NewMockSchemaRepository creates a new mock instance.
+func NewMockSchemaRepository(ctrl *gomock.Controller) *MockSchemaRepository {
+	mock := &MockSchemaRepository{ctrl: ctrl}
+	mock.recorder = &MockSchemaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchemaRepository) EXPECT() *MockSchemaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ReadSchema mocks base method.
+func (m *MockSchemaRepository) ReadSchema(ctx context.Context) (domain.Schema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadSchema", ctx)
+	ret0, _ := ret[0].(domain.Schema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadSchema indicates an expected call of ReadSchema.
+func (mr *MockSchemaRepositoryMockRecorder) ReadSchema(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadSchema", reflect.TypeOf((*MockSchemaRepository)(nil).ReadSchema), ctx)
+}
+
+// WriteSchema mocks base method.
+func (m *MockSchemaRepository) WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteSchema", ctx, namespaces)
+	ret0, _ := ret[0].(domain.Schema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteSchema indicates an expected call of WriteSchema.
+func (mr *MockSchemaRepositoryMockRecorder) WriteSchema(ctx, namespaces interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockRelationRepository)(nil).Query), query)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteSchema", reflect.TypeOf((*MockSchemaRepository)(nil).WriteSchema), ctx, namespaces)
 }
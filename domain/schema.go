@@ -0,0 +1,393 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Schema is a version of the namespace definitions. Version 0 is the empty
+// schema in effect before the first one is written.
+type Schema struct {
+	Version    uint64                `json:"version"`
+	Namespaces []NamespaceDefinition `json:"namespaces" yaml:"namespaces"`
+}
+
+// NamespaceDefinition declares the relations of a namespace, relations of
+// its objects are written to these only. A namespace without definition
+// takes any relation.
+type NamespaceDefinition struct {
+	Name      string               `json:"name" yaml:"name"`
+	Relations []RelationDefinition `json:"relations" yaml:"relations"`
+}
+
+// RelationDefinition defines a relation by its Rewrite, an expression of
+// terms and the operators
+//   - a + b, union: the subjects of a or b
+//   - a & b, intersection: the subjects of both a and b
+//   - a - b, exclusion: the subjects of a that are not subjects of b
+//
+// which are applied left to right unless grouped by parentheses. Names may
+// contain "-", so exclusion takes spaces around it. A term is one of
+//   - direct, the stored relations of the relation itself
+//   - a relation of the same object, like editor
+//   - tupleset->relation, the relation of every object the object points to
+//     by its tupleset relation, like parent->viewer
+//
+// For example "direct + editor + parent->viewer" or "reader - banned". An
+// empty Rewrite is direct.
+//
+// SubjectTypes are the subjects the stored relations may have, a namespace
+// like user, a subject set like group#member or the wildcard of a namespace
+// like user:*, which a namespace does not allow by itself. Any subject is
+// allowed if there are none, and no stored relation if the rewrite is not
+// direct.
+type RelationDefinition struct {
+	Name         string   `json:"name" yaml:"name"`
+	Rewrite      string   `json:"rewrite" yaml:"rewrite"`
+	SubjectTypes []string `json:"subject_types,omitempty" yaml:"subject_types"`
+}
+
+// DirectRewrite is the term of a rewrite standing for the stored relations.
+const DirectRewrite = "direct"
+
+type RewriteOperator string
+
+const (
+	UnionOperator        RewriteOperator = "+"
+	IntersectionOperator RewriteOperator = "&"
+	ExclusionOperator    RewriteOperator = "-"
+)
+
+// RewriteTerm is one term of a parsed rewrite. Both fields are empty for
+// direct, and Tupleset is empty for a relation of the same object.
+type RewriteTerm struct {
+	Tupleset string
+	Relation string
+}
+
+func (t RewriteTerm) Direct() bool {
+	return t == RewriteTerm{}
+}
+
+func (t RewriteTerm) String() string {
+	switch {
+	case t.Direct():
+		return DirectRewrite
+	case t.Tupleset == "":
+		return t.Relation
+	}
+	return t.Tupleset + "->" + t.Relation
+}
+
+// Rewrite is a parsed rewrite, a term if Operator is empty. A union or an
+// intersection has two or more operands, an exclusion the subjects it
+// keeps and the ones it removes.
+type Rewrite struct {
+	Operator RewriteOperator
+	Term     RewriteTerm
+	Operands []Rewrite
+}
+
+// Terms returns the terms of r in order.
+func (r Rewrite) Terms() []RewriteTerm {
+	if r.Operator == "" {
+		return []RewriteTerm{r.Term}
+	}
+	terms := []RewriteTerm{}
+	for _, operand := range r.Operands {
+		terms = append(terms, operand.Terms()...)
+	}
+	return terms
+}
+
+// Union reports whether r is a term or a union of terms, which the graph
+// traversals follow like stored relations.
+func (r Rewrite) Union() bool {
+	if r.Operator == "" {
+		return true
+	}
+	if r.Operator != UnionOperator {
+		return false
+	}
+	for _, operand := range r.Operands {
+		if !operand.Union() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r Rewrite) String() string {
+	if r.Operator == "" {
+		return r.Term.String()
+	}
+	operands := make([]string, len(r.Operands))
+	for i, operand := range r.Operands {
+		operands[i] = operand.String()
+		if operand.Operator != "" {
+			operands[i] = "(" + operands[i] + ")"
+		}
+	}
+	return strings.Join(operands, " "+string(r.Operator)+" ")
+}
+
+// ParseRewrite parses the rewrite of a relation definition.
+func ParseRewrite(rewrite string) (Rewrite, error) {
+	if strings.TrimSpace(rewrite) == "" {
+		return Rewrite{}, nil
+	}
+	p := &rewriteParser{input: rewrite}
+	r, err := p.expression()
+	if err != nil {
+		return Rewrite{}, err
+	}
+	if p.peek() != "" {
+		return Rewrite{}, p.errorf("unexpected %q", p.peek())
+	}
+	return r, nil
+}
+
+type rewriteParser struct {
+	input string
+	pos   int
+}
+
+// expression parses operands joined by operators, left to right.
+func (p *rewriteParser) expression() (Rewrite, error) {
+	r, err := p.operand()
+	if err != nil {
+		return Rewrite{}, err
+	}
+	for {
+		operator := RewriteOperator(p.peek())
+		switch operator {
+		case UnionOperator, IntersectionOperator, ExclusionOperator:
+		default:
+			return r, nil
+		}
+		p.next()
+		operand, err := p.operand()
+		if err != nil {
+			return Rewrite{}, err
+		}
+		// a chain of unions or intersections is one node
+		if r.Operator == operator && operator != ExclusionOperator {
+			r.Operands = append(r.Operands, operand)
+		} else {
+			r = Rewrite{Operator: operator, Operands: []Rewrite{r, operand}}
+		}
+	}
+}
+
+func (p *rewriteParser) operand() (Rewrite, error) {
+	token := p.next()
+	switch token {
+	case "(":
+		r, err := p.expression()
+		if err != nil {
+			return Rewrite{}, err
+		}
+		if p.next() != ")" {
+			return Rewrite{}, p.errorf("missing )")
+		}
+		return r, nil
+	case DirectRewrite:
+		return Rewrite{}, nil
+	}
+	if !validName(token) {
+		if token == "" {
+			return Rewrite{}, p.errorf("missing term")
+		}
+		return Rewrite{}, p.errorf("invalid rewrite term %q", token)
+	}
+	if p.peek() != "->" {
+		return Rewrite{Term: RewriteTerm{Relation: token}}, nil
+	}
+	p.next()
+	relation := p.next()
+	if !validName(relation) || relation == DirectRewrite {
+		return Rewrite{}, p.errorf("invalid rewrite term %q", token+"->"+relation)
+	}
+	return Rewrite{Term: RewriteTerm{Tupleset: token, Relation: relation}}, nil
+}
+
+// peek returns the next token without consuming it, "" at the end.
+func (p *rewriteParser) peek() string {
+	pos := p.pos
+	token := p.next()
+	p.pos = pos
+	return token
+}
+
+// next consumes and returns the next token: an operator, a parenthesis, "->"
+// or a name, which runs up to the next space, operator other than "-", or
+// "->".
+func (p *rewriteParser) next() string {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+	if p.pos == len(p.input) {
+		return ""
+	}
+	start := p.pos
+	switch {
+	case strings.HasPrefix(p.input[p.pos:], "->"):
+		p.pos += 2
+		return "->"
+	case strings.ContainsRune("+&-()", rune(p.input[p.pos])):
+		p.pos++
+		return p.input[start:p.pos]
+	}
+	for p.pos < len(p.input) && !strings.ContainsRune(" \t+&()", rune(p.input[p.pos])) &&
+		!strings.HasPrefix(p.input[p.pos:], "->") {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *rewriteParser) errorf(format string, args ...interface{}) error {
+	return InvalidSchemaError{Reason: fmt.Sprintf("rewrite %q: ", p.input) + fmt.Sprintf(format, args...)}
+}
+
+// Direct reports whether r includes the stored relations of its relation.
+func (r Rewrite) Direct() bool {
+	for _, term := range r.Terms() {
+		if term.Direct() {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSubjectType splits a subject type into its namespace and relation,
+// which is empty for a namespace and its wildcard.
+func ParseSubjectType(subjectType string) (namespace string, relation string, err error) {
+	if namespace, ok := strings.CutSuffix(subjectType, ":"+Wildcard); ok && validName(namespace) {
+		return namespace, "", nil
+	}
+	namespace, relation, isSet := strings.Cut(subjectType, "#")
+	if !validName(namespace) || isSet && !validName(relation) {
+		return "", "", InvalidSchemaError{Reason: fmt.Sprintf("invalid subject type %q", subjectType)}
+	}
+	return namespace, relation, nil
+}
+
+// Validate checks that the names are unique and valid and the rewrites
+// parse, and that no relation is defined through itself within its object,
+// whatever the operators in between. The relations a rewrite refers to in
+// its own namespace, and the subject types, must be declared, and relations
+// with subject types must be direct.
+func (s Schema) Validate() error {
+	namespaces := map[string]struct{}{}
+	for _, namespace := range s.Namespaces {
+		if !validName(namespace.Name) {
+			return InvalidSchemaError{Reason: fmt.Sprintf("invalid namespace name %q", namespace.Name)}
+		}
+		if _, ok := namespaces[namespace.Name]; ok {
+			return InvalidSchemaError{Reason: fmt.Sprintf("namespace %s is defined twice", namespace.Name)}
+		}
+		namespaces[namespace.Name] = struct{}{}
+
+		// the relations of the same object each relation is computed from
+		computed := map[string][]string{}
+		for _, relation := range namespace.Relations {
+			if !validName(relation.Name) || relation.Name == DirectRewrite {
+				return InvalidSchemaError{Reason: fmt.Sprintf("invalid relation name %s#%q", namespace.Name, relation.Name)}
+			}
+			if _, ok := computed[relation.Name]; ok {
+				return InvalidSchemaError{Reason: fmt.Sprintf("relation %s#%s is defined twice", namespace.Name, relation.Name)}
+			}
+			rewrite, err := ParseRewrite(relation.Rewrite)
+			if err != nil {
+				return err
+			}
+			if len(relation.SubjectTypes) > 0 && !rewrite.Direct() {
+				return InvalidSchemaError{Reason: fmt.Sprintf("relation %s#%s has subject types but is not direct", namespace.Name, relation.Name)}
+			}
+			computed[relation.Name] = []string{}
+			for _, term := range rewrite.Terms() {
+				if !term.Direct() && term.Tupleset == "" {
+					computed[relation.Name] = append(computed[relation.Name], term.Relation)
+				}
+			}
+		}
+		for relation := range computed {
+			if computesItself(computed, relation, relation, map[string]struct{}{}) {
+				return InvalidSchemaError{Reason: fmt.Sprintf("relation %s#%s is defined through itself", namespace.Name, relation)}
+			}
+		}
+	}
+	for _, namespace := range s.Namespaces {
+		for _, relation := range namespace.Relations {
+			rewrite, _ := ParseRewrite(relation.Rewrite)
+			for _, term := range rewrite.Terms() {
+				// the relation of a tupleset term is one of the objects the
+				// tupleset points to
+				name := term.Relation
+				if term.Tupleset != "" {
+					name = term.Tupleset
+				}
+				if !term.Direct() && !s.Declares(namespace.Name, name) {
+					return InvalidSchemaError{Reason: fmt.Sprintf("%s#%s is not declared, %s#%s refers to it", namespace.Name, name, namespace.Name, relation.Name)}
+				}
+			}
+			for _, subjectType := range relation.SubjectTypes {
+				subjectNamespace, subjectRelation, err := ParseSubjectType(subjectType)
+				if err != nil {
+					return err
+				}
+				if subjectRelation != "" && !s.Declares(subjectNamespace, subjectRelation) {
+					return InvalidSchemaError{Reason: fmt.Sprintf("subject type %s of %s#%s is not declared", subjectType, namespace.Name, relation.Name)}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Declares reports whether relation may be written in namespace, which is
+// the case if the namespace is not declared or declares the relation.
+func (s Schema) Declares(namespace, relation string) bool {
+	for _, definition := range s.Namespaces {
+		if definition.Name != namespace {
+			continue
+		}
+		for _, r := range definition.Relations {
+			if r.Name == relation {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func computesItself(computed map[string][]string, relation string, from string, visited map[string]struct{}) bool {
+	for _, next := range computed[from] {
+		if next == relation {
+			return true
+		}
+		if _, ok := visited[next]; ok {
+			continue
+		}
+		visited[next] = struct{}{}
+		if computesItself(computed, relation, next, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// validName reports whether name is a nonempty namespace or relation name
+// of letters, digits, "_" and "-".
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
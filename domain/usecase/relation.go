@@ -1,25 +1,106 @@
 package usecasedom
 
 import (
+	"context"
+
 	"github.com/skyrocketOoO/zanazibar-dag/domain"
 )
 
+// RelationUsecase serves the relation graph. The methods taking a ctx abort
+// once it is done and return its error.
 type RelationUsecase interface {
-	Get(relation domain.Relation, options ...PageOptions) (relations []domain.Relation, token string, err error)
-	Create(relation domain.Relation, existOk bool) error
-	Delete(relation domain.Relation) error
-	DeleteByQueries(queries []domain.Relation) error
-	BatchOperation(operations []domain.Operation) error
-
-	GetAllNamespaces() ([]string, error)
-	Check(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) (bool, error)
-	GetShortestPath(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) ([]domain.Relation, error)
-	GetAllPaths(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) ([][]domain.Relation, error)
-	GetAllObjectRelations(subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int) ([]domain.Relation, error)
-	GetAllSubjectRelations(object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int) ([]domain.Relation, error)
-	GetTree(subject domain.Node, maxDepth int) (*domain.TreeNode, error)
-
-	ClearAllRelations() error
+	Get(ctx context.Context, relation domain.Relation, options ...PageOptions) (relations []domain.Relation, token string, err error)
+	// The writes return the consistency token of the revision they committed
+	// as, which is empty if the repository does not number its writes.
+	Create(ctx context.Context, relation domain.Relation, existOk bool) (token string, err error)
+	Delete(ctx context.Context, relation domain.Relation) (token string, err error)
+	DeleteByQueries(ctx context.Context, queries []domain.Relation) (token string, err error)
+	BatchOperation(ctx context.Context, operations []domain.Operation) (token string, err error)
+
+	GetAllNamespaces(ctx context.Context) ([]string, error)
+	Check(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...ReadOptions) (bool, error)
+	// Explain decides like Check and returns the branches of the relation
+	// definitions the decision rests on.
+	Explain(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...ReadOptions) (*domain.Explanation, error)
+	GetShortestPath(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...ReadOptions) ([]domain.Relation, error)
+	// GetKShortestPaths returns the k shortest paths, or as many as the
+	// budget allows, paths of the same length ranked by preference.
+	GetKShortestPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, k int, preference domain.PathPreference, options ...ReadOptions) ([][]domain.Relation, error)
+	// GetAllPaths returns truncated paths once the budget ran out of paths,
+	// depth, nodes or time, instead of failing.
+	GetAllPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...ReadOptions) (paths [][]domain.Relation, truncated bool, err error)
+	GetAllObjectRelations(ctx context.Context, subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, options ...ReadOptions) ([]domain.Relation, error)
+	GetAllSubjectRelations(ctx context.Context, object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, options ...ReadOptions) ([]domain.Relation, error)
+	// The Stream variants call emit with every path or relation as the
+	// traversal finds it, instead of collecting them. An error returned by
+	// emit stops the traversal and is returned.
+	StreamAllPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, emit func(path []domain.Relation) error, options ...ReadOptions) (truncated bool, err error)
+	StreamAllObjectRelations(ctx context.Context, subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, emit func(relation domain.Relation) error, options ...ReadOptions) error
+	StreamAllSubjectRelations(ctx context.Context, object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, emit func(relation domain.Relation) error, options ...ReadOptions) error
+	GetTree(ctx context.Context, subject domain.Node, maxDepth int) (*domain.TreeNode, error)
+	// Expand returns the tree of the subjects holding the relation of object,
+	// directly or through subject sets, down to depth levels. A depth of 0
+	// takes the depth of the budget.
+	Expand(ctx context.Context, object domain.Node, depth int, options ...ReadOptions) (*domain.UsersetTree, error)
+
+	ClearAllRelations(ctx context.Context) (token string, err error)
+
+	// ReadSchema returns the latest namespace schema. WriteSchema validates
+	// the namespace definitions and stores them as its next version, which
+	// the traversals apply and writes are checked against from then on,
+	// unless they are the latest version already. WriteNamespace and
+	// DeleteNamespace change the definition of one namespace the same way.
+	ReadSchema(ctx context.Context) (domain.Schema, error)
+	WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error)
+	WriteNamespace(ctx context.Context, namespace domain.NamespaceDefinition) (domain.Schema, error)
+	DeleteNamespace(ctx context.Context, name string) (domain.Schema, error)
+
+	// BulkImport creates the relations read from relations, skipping the ones
+	// that exist, and checks once for the whole import that the graph stays
+	// acyclic. A cycle fails the whole import, an invalid line only itself.
+	// progress, if not nil, is called as the import proceeds.
+	BulkImport(ctx context.Context, relations RelationReader, progress func(domain.ImportProgress)) (domain.ImportResult, error)
+
+	// CacheStats returns the counters of the cache, which are zero if it is
+	// disabled.
+	CacheStats() domain.CacheStats
+
+	// Primary returns the usecase whose reads go to the primary database, for
+	// requests that must read their own writes.
+	Primary() RelationUsecase
+}
+
+// RelationReader yields the relations of a bulk import, one per line. Read
+// returns io.EOF after the last one. An error wrapping
+// domain.RequestBodyError rejects the current line only, any other error
+// aborts the import.
+type RelationReader interface {
+	Read() (domain.Relation, error)
+}
+
+// ReadOptions are the optional settings of a traversal.
+type ReadOptions struct {
+	Consistency
+	// Budget overrides the server defaults of the traversal budget, field by
+	// field. A traversal running out of it fails with
+	// domain.BudgetExceededError.
+	Budget domain.Budget
+	// Context holds the context variables the conditions of relations are
+	// evaluated with. A traversal does not cross a relation whose condition
+	// does not hold, or needs a variable Context does not have. Under an
+	// exclusion, where not crossing it could grant access, the latter is
+	// crossed.
+	Context domain.ConditionContext
+}
+
+// Consistency takes a token returned by a write. At most one field is set.
+type Consistency struct {
+	// AtLeastAsFresh makes the read see at least the writes up to the token.
+	AtLeastAsFresh string
+	// ExactSnapshot makes the read see the graph exactly as of the token. It
+	// fails with domain.SnapshotUnavailableError if the backend keeps no
+	// history, or no longer keeps that of the token.
+	ExactSnapshot string
 }
 
 type PageOptions struct {
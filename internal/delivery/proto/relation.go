@@ -2,10 +2,14 @@ package proto
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"time"
 
 	"github.com/skyrocketOoO/zanazibar-dag/domain"
 	usecasedomain "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -19,6 +23,102 @@ func NewRelationHandler(relationUsecase usecasedomain.RelationUsecase) *GrpcHand
 	}
 }
 
+// ReadYourWritesKey is the metadata key that makes a read go to the primary
+// database when set to true, so it sees the writes made before it.
+const ReadYourWritesKey = "x-read-your-writes"
+
+// reader returns the usecase serving the reads of the request.
+func (h *GrpcHandler) reader(c context.Context) usecasedomain.RelationUsecase {
+	for _, value := range metadata.ValueFromIncomingContext(c, ReadYourWritesKey) {
+		if readYourWrites, _ := strconv.ParseBool(value); readYourWrites {
+			return h.RelationUsecase.Primary()
+		}
+	}
+	return h.RelationUsecase
+}
+
+// consistency converts the consistency of a read request, which may be nil.
+func consistency(c *Consistency) usecasedomain.Consistency {
+	return usecasedomain.Consistency{
+		AtLeastAsFresh: c.GetAtLeastAsFresh(),
+		ExactSnapshot:  c.GetExactSnapshot(),
+	}
+}
+
+// readOptions converts the consistency and budget of a read request, either
+// may be nil.
+func readOptions(c *Consistency, b *Budget) usecasedomain.ReadOptions {
+	return usecasedomain.ReadOptions{
+		Consistency: consistency(c),
+		Budget: domain.Budget{
+			MaxDepth: int(b.GetMaxDepth()),
+			MaxNodes: int(b.GetMaxVisitedNodes()),
+			MaxPaths: int(b.GetMaxPaths()),
+			Timeout:  time.Duration(b.GetTimeoutMs()) * time.Millisecond,
+		},
+	}
+}
+
+// checkOptions converts the read options of a check, with its context.
+func checkOptions(req *CheckRequest) usecasedomain.ReadOptions {
+	options := readOptions(req.Consistency, req.Budget)
+	options.Context = req.GetContext().AsMap()
+	return options
+}
+
+// internalError maps an error the request is not to blame for to its status:
+// DeadlineExceeded or Canceled if the request ended before it was served, and
+// Internal otherwise.
+func internalError(c context.Context, err error) error {
+	done := c.Err()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || done == context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled) || done == context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// readError maps the error of a read to its status.
+func readError(c context.Context, err error) error {
+	switch err.(type) {
+	case domain.RequestBodyError, domain.InvalidTokenError, domain.CauseCycleError, domain.InvalidSchemaError,
+		domain.ConditionContextError:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case domain.SnapshotUnavailableError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case domain.BudgetExceededError:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return internalError(c, err)
+}
+
+// toDomainRelation converts a relation of a request. Condition params that
+// are no JSON object fail with a domain.RequestBodyError.
+func toDomainRelation(relation *Relation) (domain.Relation, error) {
+	params, err := domain.NewConditionParams(relation.GetConditionParams().AsMap())
+	if err != nil {
+		return domain.Relation{}, domain.RequestBodyError{}
+	}
+	var expiresAt *time.Time
+	if relation.GetExpiresAt() != nil {
+		t := relation.GetExpiresAt().AsTime()
+		expiresAt = &t
+	}
+	return domain.Relation{
+		ObjectNamespace:  relation.GetObjectNamespace(),
+		ObjectName:       relation.GetObjectName(),
+		Relation:         relation.GetRelation(),
+		SubjectNamespace: relation.GetSubjectNamespace(),
+		SubjectName:      relation.GetSubjectName(),
+		SubjectRelation:  relation.GetSubjectRelation(),
+		Condition:        relation.GetCondition(),
+		ConditionParams:  params,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
 func (h *GrpcHandler) Get(c context.Context, relation *Relation) (*RelationsResponse, error) {
 	requestRelation := domain.Relation{
 		ObjectNamespace:  relation.ObjectNamespace,
@@ -28,46 +128,41 @@ func (h *GrpcHandler) Get(c context.Context, relation *Relation) (*RelationsResp
 		SubjectName:      relation.SubjectName,
 		SubjectRelation:  relation.SubjectRelation,
 	}
-	relations, _, err := h.RelationUsecase.Get(requestRelation)
+	relations, _, err := h.reader(c).Get(c, requestRelation)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
 	protoRelations := make([]*Relation, len(relations))
 	for i, rel := range relations {
-		protoRelations[i] = &Relation{
-			ObjectNamespace:  rel.ObjectNamespace,
-			ObjectName:       rel.ObjectName,
-			Relation:         rel.Relation,
-			SubjectNamespace: rel.SubjectNamespace,
-			SubjectName:      rel.SubjectName,
-			SubjectRelation:  rel.SubjectRelation,
-		}
+		protoRelations[i] = toProtoRelation(rel)
 	}
 	response := &RelationsResponse{
 		Relations: protoRelations,
 	}
 	return response, nil
 }
-func (h *GrpcHandler) Create(c context.Context, req *RelationCreateRequest) (*Empty, error) {
-	requestRelation := domain.Relation{
-		ObjectNamespace:  req.Relation.ObjectNamespace,
-		ObjectName:       req.Relation.ObjectName,
-		Relation:         req.Relation.Relation,
-		SubjectNamespace: req.Relation.SubjectNamespace,
-		SubjectName:      req.Relation.SubjectName,
-		SubjectRelation:  req.Relation.SubjectRelation,
-	}
-	err := h.RelationUsecase.Create(requestRelation, req.ExistOk)
+func (h *GrpcHandler) Create(c context.Context, req *RelationCreateRequest) (*WriteResponse, error) {
+	requestRelation, err := toDomainRelation(req.Relation)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	token, err := h.RelationUsecase.Create(c, requestRelation, req.ExistOk)
 	if err != nil {
 		if _, ok := err.(domain.CauseCycleError); ok {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
+		} else if _, ok := err.(domain.SchemaViolationError); ok {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		} else if _, ok := err.(domain.InvalidConditionError); ok {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		} else if _, ok := err.(domain.AlreadyExistsError); ok {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
 		}
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
-	return &Empty{}, nil
+	return &WriteResponse{Token: token}, nil
 }
 
-func (h *GrpcHandler) Delete(c context.Context, relation *Relation) (*Empty, error) {
+func (h *GrpcHandler) Delete(c context.Context, relation *Relation) (*WriteResponse, error) {
 	requestRelation := domain.Relation{
 		ObjectNamespace:  relation.ObjectNamespace,
 		ObjectName:       relation.ObjectName,
@@ -76,17 +171,17 @@ func (h *GrpcHandler) Delete(c context.Context, relation *Relation) (*Empty, err
 		SubjectName:      relation.SubjectName,
 		SubjectRelation:  relation.SubjectRelation,
 	}
-	err := h.RelationUsecase.Delete(requestRelation)
+	token, err := h.RelationUsecase.Delete(c, requestRelation)
 	if err != nil {
 		if _, ok := err.(domain.CauseCycleError); ok {
 			return nil, status.Error(codes.InvalidArgument, err.Error())
 		}
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
-	return &Empty{}, nil
+	return &WriteResponse{Token: token}, nil
 }
 
-func (h *GrpcHandler) DeleteByQueries(c context.Context, req *DeleteByQueriesRequest) (*Empty, error) {
+func (h *GrpcHandler) DeleteByQueries(c context.Context, req *DeleteByQueriesRequest) (*WriteResponse, error) {
 	queries := make([]domain.Relation, len(req.Queries))
 	for i, q := range req.Queries {
 		queries[i] = domain.Relation{
@@ -98,44 +193,49 @@ func (h *GrpcHandler) DeleteByQueries(c context.Context, req *DeleteByQueriesReq
 			SubjectRelation:  q.SubjectRelation,
 		}
 	}
-	err := h.RelationUsecase.DeleteByQueries(queries)
+	token, err := h.RelationUsecase.DeleteByQueries(c, queries)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
 
-	return &Empty{}, nil
+	return &WriteResponse{Token: token}, nil
 }
 
-func (h *GrpcHandler) BatchOperation(c context.Context, req *BatchOperationRequest) (*Empty, error) {
+func (h *GrpcHandler) BatchOperation(c context.Context, req *BatchOperationRequest) (*WriteResponse, error) {
 	operations := make([]domain.Operation, len(req.Operations))
 	for i, o := range req.Operations {
+		relation, err := toDomainRelation(o.Relation)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, domain.BatchOperationError{Index: i, Err: err}.Error())
+		}
 		operations[i] = domain.Operation{
-			Type: domain.Action(o.Type),
-			Relation: domain.Relation{
-				ObjectNamespace:  o.Relation.ObjectNamespace,
-				ObjectName:       o.Relation.ObjectName,
-				Relation:         o.Relation.Relation,
-				SubjectNamespace: o.Relation.SubjectNamespace,
-				SubjectName:      o.Relation.SubjectName,
-				SubjectRelation:  o.Relation.SubjectRelation,
-			},
+			Type:     domain.Action(o.Type),
+			Relation: relation,
 		}
 	}
-	err := h.RelationUsecase.BatchOperation(operations)
+	token, err := h.RelationUsecase.BatchOperation(c, operations)
 	if err != nil {
-		if _, ok := err.(domain.CauseCycleError); ok {
+		// the message keeps the index of the failed operation
+		cause := err
+		if batchErr, ok := err.(domain.BatchOperationError); ok {
+			cause = batchErr.Err
+		}
+		switch cause.(type) {
+		case domain.CauseCycleError, domain.RequestBodyError, domain.SchemaViolationError, domain.InvalidConditionError:
 			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case domain.AlreadyExistsError:
+			return nil, status.Error(codes.AlreadyExists, err.Error())
 		}
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
 
-	return &Empty{}, nil
+	return &WriteResponse{Token: token}, nil
 }
 
 func (h *GrpcHandler) GetAllNamespaces(c context.Context, empty *Empty) (*StringsResponse, error) {
-	namespaces, err := h.RelationUsecase.GetAllNamespaces()
+	namespaces, err := h.reader(c).GetAllNamespaces(c)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, internalError(c, err)
 	}
 	req := StringsResponse{
 		Strings: namespaces,
@@ -161,12 +261,9 @@ func (h *GrpcHandler) Check(c context.Context, req *CheckRequest) (*Empty, error
 			Relations:  req.SearchCondition.In.Relation,
 		},
 	}
-	ok, err := h.RelationUsecase.Check(subject, object, searchCondition)
+	ok, err := h.reader(c).Check(c, subject, object, searchCondition, checkOptions(req))
 	if err != nil {
-		if _, ok := err.(domain.CauseCycleError); ok {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
 	if !ok {
 		return nil, status.Errorf(codes.PermissionDenied, "")
@@ -174,6 +271,53 @@ func (h *GrpcHandler) Check(c context.Context, req *CheckRequest) (*Empty, error
 	return nil, nil
 }
 
+func (h *GrpcHandler) Explain(c context.Context, req *CheckRequest) (*Explanation, error) {
+	subject := domain.Node{
+		Namespace: req.Subject.Namespace,
+		Name:      req.Subject.Name,
+		Relation:  req.Subject.Relation,
+	}
+	object := domain.Node{
+		Namespace: req.Object.Namespace,
+		Name:      req.Object.Name,
+		Relation:  req.Object.Relation,
+	}
+	searchCondition := domain.SearchCondition{
+		In: domain.Compare{
+			Namespaces: req.SearchCondition.In.Namespaces,
+			Names:      req.SearchCondition.In.Name,
+			Relations:  req.SearchCondition.In.Relation,
+		},
+	}
+	explanation, err := h.reader(c).Explain(c, subject, object, searchCondition, checkOptions(req))
+	if err != nil {
+		return nil, readError(c, err)
+	}
+	return toProtoExplanation(*explanation), nil
+}
+
+func toProtoExplanation(explanation domain.Explanation) *Explanation {
+	children := make([]*Explanation, len(explanation.Children))
+	for i, child := range explanation.Children {
+		children[i] = toProtoExplanation(child)
+	}
+	var through *Relation
+	if explanation.Through != nil {
+		through = toProtoRelation(*explanation.Through)
+	}
+	return &Explanation{
+		Object: &Node{
+			Namespace: explanation.Object.Namespace,
+			Name:      explanation.Object.Name,
+			Relation:  explanation.Object.Relation,
+		},
+		Rewrite:  explanation.Rewrite,
+		Allowed:  explanation.Allowed,
+		Through:  through,
+		Children: children,
+	}
+}
+
 func (h *GrpcHandler) GetShortestPath(c context.Context, req *GetShortestPathRequest) (*PathResponse, error) {
 	subject := domain.Node{
 		Namespace: req.Subject.Namespace,
@@ -192,20 +336,13 @@ func (h *GrpcHandler) GetShortestPath(c context.Context, req *GetShortestPathReq
 			Relations:  req.SearchCondition.In.Relation,
 		},
 	}
-	paths, err := h.RelationUsecase.GetShortestPath(subject, object, searchCondition)
+	paths, err := h.reader(c).GetShortestPath(c, subject, object, searchCondition, readOptions(req.Consistency, req.Budget))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
 	protoPaths := make([]*Relation, len(paths))
 	for i, path := range paths {
-		protoPaths[i] = &Relation{
-			ObjectNamespace:  path.ObjectNamespace,
-			ObjectName:       path.ObjectName,
-			Relation:         path.Relation,
-			SubjectNamespace: path.SubjectNamespace,
-			SubjectName:      path.SubjectName,
-			SubjectRelation:  path.SubjectRelation,
-		}
+		protoPaths[i] = toProtoRelation(path)
 	}
 	resp := PathResponse{
 		Relations: protoPaths,
@@ -231,33 +368,66 @@ func (h *GrpcHandler) GetAllPaths(c context.Context, req *GetAllPathsRequest) (*
 			Relations:  req.SearchCondition.In.Relation,
 		},
 	}
-	allPaths, err := h.RelationUsecase.GetAllPaths(subject, object, searchCondition)
+	allPaths, truncated, err := h.reader(c).GetAllPaths(c, subject, object, searchCondition, readOptions(req.Consistency, req.Budget))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
 	paths := make([]*PathResponse, len(allPaths))
 	for i, path := range allPaths {
 		relations := make([]*Relation, len(path))
 		for j, rel := range path {
-			relations[j] = &Relation{
-				ObjectNamespace:  rel.ObjectNamespace,
-				ObjectName:       rel.ObjectName,
-				Relation:         rel.Relation,
-				SubjectNamespace: rel.SubjectNamespace,
-				SubjectName:      rel.SubjectName,
-				SubjectRelation:  rel.SubjectRelation,
-			}
+			relations[j] = toProtoRelation(rel)
 		}
 		paths[i] = &PathResponse{
 			Relations: relations,
 		}
 	}
 	resp := PathsResponse{
-		Path: paths,
+		Path:      paths,
+		Truncated: truncated,
 	}
 	return &resp, nil
 }
 
+func (h *GrpcHandler) GetKShortestPaths(c context.Context, req *GetKShortestPathsRequest) (*PathsResponse, error) {
+	subject := domain.Node{
+		Namespace: req.Subject.Namespace,
+		Name:      req.Subject.Name,
+		Relation:  req.Subject.Relation,
+	}
+	object := domain.Node{
+		Namespace: req.Object.Namespace,
+		Name:      req.Object.Name,
+		Relation:  req.Object.Relation,
+	}
+	searchCondition := domain.SearchCondition{
+		In: domain.Compare{
+			Namespaces: req.SearchCondition.In.Namespaces,
+			Names:      req.SearchCondition.In.Name,
+			Relations:  req.SearchCondition.In.Relation,
+		},
+	}
+	preference := domain.PathPreference{
+		Namespaces: req.Preference.GetNamespaces(),
+		Relations:  req.Preference.GetRelations(),
+	}
+	rankedPaths, err := h.reader(c).GetKShortestPaths(c, subject, object, searchCondition, int(req.K), preference, readOptions(req.Consistency, req.Budget))
+	if err != nil {
+		return nil, readError(c, err)
+	}
+	paths := make([]*PathResponse, len(rankedPaths))
+	for i, path := range rankedPaths {
+		relations := make([]*Relation, len(path))
+		for j, rel := range path {
+			relations[j] = toProtoRelation(rel)
+		}
+		paths[i] = &PathResponse{
+			Relations: relations,
+		}
+	}
+	return &PathsResponse{Path: paths}, nil
+}
+
 func (h *GrpcHandler) GetAllObjectRelations(c context.Context, req *GetAllObjectRelationsRequest) (*RelationsResponse, error) {
 	subject := domain.Node{
 		Namespace: req.Subject.Namespace,
@@ -278,20 +448,13 @@ func (h *GrpcHandler) GetAllObjectRelations(c context.Context, req *GetAllObject
 			Relations:  req.CollectCondition.In.Relation,
 		},
 	}
-	relations, err := h.RelationUsecase.GetAllObjectRelations(subject, searchCondition, collectCondition, int(req.MaxDepth))
+	relations, err := h.reader(c).GetAllObjectRelations(c, subject, searchCondition, collectCondition, int(req.MaxDepth), readOptions(req.Consistency, req.Budget))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
 	protoRelations := make([]*Relation, len(relations))
 	for i, rel := range relations {
-		protoRelations[i] = &Relation{
-			ObjectNamespace:  rel.ObjectNamespace,
-			ObjectName:       rel.ObjectName,
-			Relation:         rel.Relation,
-			SubjectNamespace: rel.SubjectNamespace,
-			SubjectName:      rel.SubjectName,
-			SubjectRelation:  rel.SubjectRelation,
-		}
+		protoRelations[i] = toProtoRelation(rel)
 	}
 	resp := RelationsResponse{
 		Relations: protoRelations,
@@ -319,20 +482,13 @@ func (h *GrpcHandler) GetAllSubjectRelations(c context.Context, req *GetAllSubje
 			Relations:  req.CollectCondition.In.Relation,
 		},
 	}
-	relations, err := h.RelationUsecase.GetAllObjectRelations(object, searchCondition, collectCondition, int(req.MaxDepth))
+	relations, err := h.reader(c).GetAllSubjectRelations(c, object, searchCondition, collectCondition, int(req.MaxDepth), readOptions(req.Consistency, req.Budget))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
 	protoRelations := make([]*Relation, len(relations))
 	for i, rel := range relations {
-		protoRelations[i] = &Relation{
-			ObjectNamespace:  rel.ObjectNamespace,
-			ObjectName:       rel.ObjectName,
-			Relation:         rel.Relation,
-			SubjectNamespace: rel.SubjectNamespace,
-			SubjectName:      rel.SubjectName,
-			SubjectRelation:  rel.SubjectRelation,
-		}
+		protoRelations[i] = toProtoRelation(rel)
 	}
 	resp := RelationsResponse{
 		Relations: protoRelations,
@@ -340,13 +496,137 @@ func (h *GrpcHandler) GetAllSubjectRelations(c context.Context, req *GetAllSubje
 	return &resp, nil
 }
 
-func (h *GrpcHandler) ClearAllRelations(c context.Context, empty *Empty) (*Empty, error) {
-	err := h.RelationUsecase.ClearAllRelations()
+func (h *GrpcHandler) Expand(c context.Context, req *ExpandRequest) (*UsersetTree, error) {
+	object := domain.Node{
+		Namespace: req.Object.Namespace,
+		Name:      req.Object.Name,
+		Relation:  req.Object.Relation,
+	}
+	tree, err := h.reader(c).Expand(c, object, int(req.Depth), readOptions(req.Consistency, req.Budget))
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, err.Error())
+		return nil, readError(c, err)
 	}
-	return nil, nil
+	return toProtoTree(*tree), nil
+}
+
+func toProtoTree(tree domain.UsersetTree) *UsersetTree {
+	children := make([]*UsersetTree, len(tree.Children))
+	for i, child := range tree.Children {
+		children[i] = toProtoTree(child)
+	}
+	return &UsersetTree{
+		Node: &Node{
+			Namespace: tree.Namespace,
+			Name:      tree.Name,
+			Relation:  tree.Relation,
+		},
+		Children:  children,
+		Truncated: tree.Truncated,
+	}
+}
+
+func (h *GrpcHandler) ReadSchema(c context.Context, empty *Empty) (*Schema, error) {
+	schema, err := h.RelationUsecase.ReadSchema(c)
+	if err != nil {
+		return nil, internalError(c, err)
+	}
+	return toProtoSchema(schema), nil
+}
+
+func (h *GrpcHandler) WriteSchema(c context.Context, req *WriteSchemaRequest) (*Schema, error) {
+	namespaces := make([]domain.NamespaceDefinition, len(req.Namespaces))
+	for i, namespace := range req.Namespaces {
+		namespaces[i] = toNamespaceDefinition(namespace)
+	}
+	schema, err := h.RelationUsecase.WriteSchema(c, namespaces)
+	if err != nil {
+		return nil, readError(c, err)
+	}
+	return toProtoSchema(schema), nil
+}
+
+func (h *GrpcHandler) WriteNamespace(c context.Context, req *NamespaceDefinition) (*Schema, error) {
+	schema, err := h.RelationUsecase.WriteNamespace(c, toNamespaceDefinition(req))
+	if err != nil {
+		return nil, readError(c, err)
+	}
+	return toProtoSchema(schema), nil
+}
+
+func (h *GrpcHandler) DeleteNamespace(c context.Context, req *DeleteNamespaceRequest) (*Schema, error) {
+	schema, err := h.RelationUsecase.DeleteNamespace(c, req.Name)
+	if err != nil {
+		return nil, readError(c, err)
+	}
+	return toProtoSchema(schema), nil
+}
+
+func toNamespaceDefinition(namespace *NamespaceDefinition) domain.NamespaceDefinition {
+	relations := make([]domain.RelationDefinition, len(namespace.Relations))
+	for i, relation := range namespace.Relations {
+		relations[i] = domain.RelationDefinition{Name: relation.Name, Rewrite: relation.Rewrite, SubjectTypes: relation.SubjectTypes}
+	}
+	return domain.NamespaceDefinition{Name: namespace.Name, Relations: relations}
+}
+
+func toProtoSchema(schema domain.Schema) *Schema {
+	namespaces := make([]*NamespaceDefinition, len(schema.Namespaces))
+	for i, namespace := range schema.Namespaces {
+		relations := make([]*RelationDefinition, len(namespace.Relations))
+		for j, relation := range namespace.Relations {
+			relations[j] = &RelationDefinition{Name: relation.Name, Rewrite: relation.Rewrite, SubjectTypes: relation.SubjectTypes}
+		}
+		namespaces[i] = &NamespaceDefinition{Name: namespace.Name, Relations: relations}
+	}
+	return &Schema{Version: schema.Version, Namespaces: namespaces}
+}
+
+func (h *GrpcHandler) ClearAllRelations(c context.Context, empty *Empty) (*WriteResponse, error) {
+	token, err := h.RelationUsecase.ClearAllRelations(c)
+	if err != nil {
+		return nil, internalError(c, err)
+	}
+	return &WriteResponse{Token: token}, nil
 }
 
 func (*GrpcHandler) mustEmbedUnimplementedRelationServiceServer() {
 }
+
+// BulkImport reads relations from the client stream, the response reports
+// the counts and the first line errors of the import.
+func (h *GrpcHandler) BulkImport(stream RelationService_BulkImportServer) error {
+	result, err := h.RelationUsecase.BulkImport(stream.Context(), relationStreamReader{stream: stream}, nil)
+	if err != nil {
+		if _, ok := err.(domain.CauseCycleError); ok {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		return internalError(stream.Context(), err)
+	}
+
+	lineErrors := make([]*ImportLineError, len(result.Errors))
+	for i, lineErr := range result.Errors {
+		lineErrors[i] = &ImportLineError{
+			Line:  int64(lineErr.Line),
+			Error: lineErr.Err,
+		}
+	}
+	return stream.SendAndClose(&BulkImportResponse{
+		Lines:      int64(result.Lines),
+		Imported:   int64(result.Imported),
+		ErrorCount: int64(result.ErrorCount),
+		Errors:     lineErrors,
+		Token:      result.Token,
+	})
+}
+
+type relationStreamReader struct {
+	stream RelationService_BulkImportServer
+}
+
+func (r relationStreamReader) Read() (domain.Relation, error) {
+	relation, err := r.stream.Recv()
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	return toDomainRelation(relation)
+}
@@ -19,18 +19,29 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	RelationService_Get_FullMethodName                    = "/proto.RelationService/Get"
-	RelationService_Create_FullMethodName                 = "/proto.RelationService/Create"
-	RelationService_Delete_FullMethodName                 = "/proto.RelationService/Delete"
-	RelationService_DeleteByQueries_FullMethodName        = "/proto.RelationService/DeleteByQueries"
-	RelationService_BatchOperation_FullMethodName         = "/proto.RelationService/BatchOperation"
-	RelationService_GetAllNamespaces_FullMethodName       = "/proto.RelationService/GetAllNamespaces"
-	RelationService_Check_FullMethodName                  = "/proto.RelationService/Check"
-	RelationService_GetShortestPath_FullMethodName        = "/proto.RelationService/GetShortestPath"
-	RelationService_GetAllPaths_FullMethodName            = "/proto.RelationService/GetAllPaths"
-	RelationService_GetAllObjectRelations_FullMethodName  = "/proto.RelationService/GetAllObjectRelations"
-	RelationService_GetAllSubjectRelations_FullMethodName = "/proto.RelationService/GetAllSubjectRelations"
-	RelationService_ClearAllRelations_FullMethodName      = "/proto.RelationService/ClearAllRelations"
+	RelationService_Get_FullMethodName                       = "/proto.RelationService/Get"
+	RelationService_Create_FullMethodName                    = "/proto.RelationService/Create"
+	RelationService_Delete_FullMethodName                    = "/proto.RelationService/Delete"
+	RelationService_DeleteByQueries_FullMethodName           = "/proto.RelationService/DeleteByQueries"
+	RelationService_BatchOperation_FullMethodName            = "/proto.RelationService/BatchOperation"
+	RelationService_GetAllNamespaces_FullMethodName          = "/proto.RelationService/GetAllNamespaces"
+	RelationService_Check_FullMethodName                     = "/proto.RelationService/Check"
+	RelationService_Explain_FullMethodName                   = "/proto.RelationService/Explain"
+	RelationService_GetShortestPath_FullMethodName           = "/proto.RelationService/GetShortestPath"
+	RelationService_GetAllPaths_FullMethodName               = "/proto.RelationService/GetAllPaths"
+	RelationService_GetKShortestPaths_FullMethodName         = "/proto.RelationService/GetKShortestPaths"
+	RelationService_GetAllObjectRelations_FullMethodName     = "/proto.RelationService/GetAllObjectRelations"
+	RelationService_GetAllSubjectRelations_FullMethodName    = "/proto.RelationService/GetAllSubjectRelations"
+	RelationService_StreamAllPaths_FullMethodName            = "/proto.RelationService/StreamAllPaths"
+	RelationService_StreamAllObjectRelations_FullMethodName  = "/proto.RelationService/StreamAllObjectRelations"
+	RelationService_StreamAllSubjectRelations_FullMethodName = "/proto.RelationService/StreamAllSubjectRelations"
+	RelationService_Expand_FullMethodName                    = "/proto.RelationService/Expand"
+	RelationService_ClearAllRelations_FullMethodName         = "/proto.RelationService/ClearAllRelations"
+	RelationService_BulkImport_FullMethodName                = "/proto.RelationService/BulkImport"
+	RelationService_ReadSchema_FullMethodName                = "/proto.RelationService/ReadSchema"
+	RelationService_WriteSchema_FullMethodName               = "/proto.RelationService/WriteSchema"
+	RelationService_WriteNamespace_FullMethodName            = "/proto.RelationService/WriteNamespace"
+	RelationService_DeleteNamespace_FullMethodName           = "/proto.RelationService/DeleteNamespace"
 )
 
 // RelationServiceClient is the client API for RelationService service.
@@ -38,17 +49,30 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RelationServiceClient interface {
 	Get(ctx context.Context, in *Relation, opts ...grpc.CallOption) (*RelationsResponse, error)
-	Create(ctx context.Context, in *RelationCreateRequest, opts ...grpc.CallOption) (*Empty, error)
-	Delete(ctx context.Context, in *Relation, opts ...grpc.CallOption) (*Empty, error)
-	DeleteByQueries(ctx context.Context, in *DeleteByQueriesRequest, opts ...grpc.CallOption) (*Empty, error)
-	BatchOperation(ctx context.Context, in *BatchOperationRequest, opts ...grpc.CallOption) (*Empty, error)
+	Create(ctx context.Context, in *RelationCreateRequest, opts ...grpc.CallOption) (*WriteResponse, error)
+	Delete(ctx context.Context, in *Relation, opts ...grpc.CallOption) (*WriteResponse, error)
+	DeleteByQueries(ctx context.Context, in *DeleteByQueriesRequest, opts ...grpc.CallOption) (*WriteResponse, error)
+	BatchOperation(ctx context.Context, in *BatchOperationRequest, opts ...grpc.CallOption) (*WriteResponse, error)
 	GetAllNamespaces(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*StringsResponse, error)
 	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*Empty, error)
+	Explain(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*Explanation, error)
 	GetShortestPath(ctx context.Context, in *GetShortestPathRequest, opts ...grpc.CallOption) (*PathResponse, error)
 	GetAllPaths(ctx context.Context, in *GetAllPathsRequest, opts ...grpc.CallOption) (*PathsResponse, error)
+	GetKShortestPaths(ctx context.Context, in *GetKShortestPathsRequest, opts ...grpc.CallOption) (*PathsResponse, error)
 	GetAllObjectRelations(ctx context.Context, in *GetAllObjectRelationsRequest, opts ...grpc.CallOption) (*RelationsResponse, error)
 	GetAllSubjectRelations(ctx context.Context, in *GetAllSubjectRelationsRequest, opts ...grpc.CallOption) (*RelationsResponse, error)
-	ClearAllRelations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	// The Stream variants send every path or relation as the traversal
+	// finds it.
+	StreamAllPaths(ctx context.Context, in *GetAllPathsRequest, opts ...grpc.CallOption) (RelationService_StreamAllPathsClient, error)
+	StreamAllObjectRelations(ctx context.Context, in *GetAllObjectRelationsRequest, opts ...grpc.CallOption) (RelationService_StreamAllObjectRelationsClient, error)
+	StreamAllSubjectRelations(ctx context.Context, in *GetAllSubjectRelationsRequest, opts ...grpc.CallOption) (RelationService_StreamAllSubjectRelationsClient, error)
+	Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (*UsersetTree, error)
+	ClearAllRelations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WriteResponse, error)
+	BulkImport(ctx context.Context, opts ...grpc.CallOption) (RelationService_BulkImportClient, error)
+	ReadSchema(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Schema, error)
+	WriteSchema(ctx context.Context, in *WriteSchemaRequest, opts ...grpc.CallOption) (*Schema, error)
+	WriteNamespace(ctx context.Context, in *NamespaceDefinition, opts ...grpc.CallOption) (*Schema, error)
+	DeleteNamespace(ctx context.Context, in *DeleteNamespaceRequest, opts ...grpc.CallOption) (*Schema, error)
 }
 
 type relationServiceClient struct {
@@ -68,8 +92,8 @@ func (c *relationServiceClient) Get(ctx context.Context, in *Relation, opts ...g
 	return out, nil
 }
 
-func (c *relationServiceClient) Create(ctx context.Context, in *RelationCreateRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *relationServiceClient) Create(ctx context.Context, in *RelationCreateRequest, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
 	err := c.cc.Invoke(ctx, RelationService_Create_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -77,8 +101,8 @@ func (c *relationServiceClient) Create(ctx context.Context, in *RelationCreateRe
 	return out, nil
 }
 
-func (c *relationServiceClient) Delete(ctx context.Context, in *Relation, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *relationServiceClient) Delete(ctx context.Context, in *Relation, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
 	err := c.cc.Invoke(ctx, RelationService_Delete_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -86,8 +110,8 @@ func (c *relationServiceClient) Delete(ctx context.Context, in *Relation, opts .
 	return out, nil
 }
 
-func (c *relationServiceClient) DeleteByQueries(ctx context.Context, in *DeleteByQueriesRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *relationServiceClient) DeleteByQueries(ctx context.Context, in *DeleteByQueriesRequest, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
 	err := c.cc.Invoke(ctx, RelationService_DeleteByQueries_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -95,8 +119,8 @@ func (c *relationServiceClient) DeleteByQueries(ctx context.Context, in *DeleteB
 	return out, nil
 }
 
-func (c *relationServiceClient) BatchOperation(ctx context.Context, in *BatchOperationRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *relationServiceClient) BatchOperation(ctx context.Context, in *BatchOperationRequest, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
 	err := c.cc.Invoke(ctx, RelationService_BatchOperation_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -122,6 +146,15 @@ func (c *relationServiceClient) Check(ctx context.Context, in *CheckRequest, opt
 	return out, nil
 }
 
+func (c *relationServiceClient) Explain(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*Explanation, error) {
+	out := new(Explanation)
+	err := c.cc.Invoke(ctx, RelationService_Explain_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *relationServiceClient) GetShortestPath(ctx context.Context, in *GetShortestPathRequest, opts ...grpc.CallOption) (*PathResponse, error) {
 	out := new(PathResponse)
 	err := c.cc.Invoke(ctx, RelationService_GetShortestPath_FullMethodName, in, out, opts...)
@@ -140,6 +173,15 @@ func (c *relationServiceClient) GetAllPaths(ctx context.Context, in *GetAllPaths
 	return out, nil
 }
 
+func (c *relationServiceClient) GetKShortestPaths(ctx context.Context, in *GetKShortestPathsRequest, opts ...grpc.CallOption) (*PathsResponse, error) {
+	out := new(PathsResponse)
+	err := c.cc.Invoke(ctx, RelationService_GetKShortestPaths_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *relationServiceClient) GetAllObjectRelations(ctx context.Context, in *GetAllObjectRelationsRequest, opts ...grpc.CallOption) (*RelationsResponse, error) {
 	out := new(RelationsResponse)
 	err := c.cc.Invoke(ctx, RelationService_GetAllObjectRelations_FullMethodName, in, out, opts...)
@@ -158,8 +200,113 @@ func (c *relationServiceClient) GetAllSubjectRelations(ctx context.Context, in *
 	return out, nil
 }
 
-func (c *relationServiceClient) ClearAllRelations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *relationServiceClient) StreamAllPaths(ctx context.Context, in *GetAllPathsRequest, opts ...grpc.CallOption) (RelationService_StreamAllPathsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RelationService_ServiceDesc.Streams[0], RelationService_StreamAllPaths_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &relationServiceStreamAllPathsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RelationService_StreamAllPathsClient interface {
+	Recv() (*PathStreamResponse, error)
+	grpc.ClientStream
+}
+
+type relationServiceStreamAllPathsClient struct {
+	grpc.ClientStream
+}
+
+func (x *relationServiceStreamAllPathsClient) Recv() (*PathStreamResponse, error) {
+	m := new(PathStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *relationServiceClient) StreamAllObjectRelations(ctx context.Context, in *GetAllObjectRelationsRequest, opts ...grpc.CallOption) (RelationService_StreamAllObjectRelationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RelationService_ServiceDesc.Streams[1], RelationService_StreamAllObjectRelations_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &relationServiceStreamAllObjectRelationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RelationService_StreamAllObjectRelationsClient interface {
+	Recv() (*Relation, error)
+	grpc.ClientStream
+}
+
+type relationServiceStreamAllObjectRelationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *relationServiceStreamAllObjectRelationsClient) Recv() (*Relation, error) {
+	m := new(Relation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *relationServiceClient) StreamAllSubjectRelations(ctx context.Context, in *GetAllSubjectRelationsRequest, opts ...grpc.CallOption) (RelationService_StreamAllSubjectRelationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RelationService_ServiceDesc.Streams[2], RelationService_StreamAllSubjectRelations_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &relationServiceStreamAllSubjectRelationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RelationService_StreamAllSubjectRelationsClient interface {
+	Recv() (*Relation, error)
+	grpc.ClientStream
+}
+
+type relationServiceStreamAllSubjectRelationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *relationServiceStreamAllSubjectRelationsClient) Recv() (*Relation, error) {
+	m := new(Relation)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *relationServiceClient) Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (*UsersetTree, error) {
+	out := new(UsersetTree)
+	err := c.cc.Invoke(ctx, RelationService_Expand_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relationServiceClient) ClearAllRelations(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*WriteResponse, error) {
+	out := new(WriteResponse)
 	err := c.cc.Invoke(ctx, RelationService_ClearAllRelations_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -167,22 +314,105 @@ func (c *relationServiceClient) ClearAllRelations(ctx context.Context, in *Empty
 	return out, nil
 }
 
+func (c *relationServiceClient) BulkImport(ctx context.Context, opts ...grpc.CallOption) (RelationService_BulkImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &RelationService_ServiceDesc.Streams[3], RelationService_BulkImport_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &relationServiceBulkImportClient{stream}
+	return x, nil
+}
+
+type RelationService_BulkImportClient interface {
+	Send(*Relation) error
+	CloseAndRecv() (*BulkImportResponse, error)
+	grpc.ClientStream
+}
+
+type relationServiceBulkImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *relationServiceBulkImportClient) Send(m *Relation) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *relationServiceBulkImportClient) CloseAndRecv() (*BulkImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *relationServiceClient) ReadSchema(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Schema, error) {
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, RelationService_ReadSchema_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relationServiceClient) WriteSchema(ctx context.Context, in *WriteSchemaRequest, opts ...grpc.CallOption) (*Schema, error) {
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, RelationService_WriteSchema_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relationServiceClient) WriteNamespace(ctx context.Context, in *NamespaceDefinition, opts ...grpc.CallOption) (*Schema, error) {
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, RelationService_WriteNamespace_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relationServiceClient) DeleteNamespace(ctx context.Context, in *DeleteNamespaceRequest, opts ...grpc.CallOption) (*Schema, error) {
+	out := new(Schema)
+	err := c.cc.Invoke(ctx, RelationService_DeleteNamespace_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RelationServiceServer is the server API for RelationService service.
 // All implementations must embed UnimplementedRelationServiceServer
 // for forward compatibility
 type RelationServiceServer interface {
 	Get(context.Context, *Relation) (*RelationsResponse, error)
-	Create(context.Context, *RelationCreateRequest) (*Empty, error)
-	Delete(context.Context, *Relation) (*Empty, error)
-	DeleteByQueries(context.Context, *DeleteByQueriesRequest) (*Empty, error)
-	BatchOperation(context.Context, *BatchOperationRequest) (*Empty, error)
+	Create(context.Context, *RelationCreateRequest) (*WriteResponse, error)
+	Delete(context.Context, *Relation) (*WriteResponse, error)
+	DeleteByQueries(context.Context, *DeleteByQueriesRequest) (*WriteResponse, error)
+	BatchOperation(context.Context, *BatchOperationRequest) (*WriteResponse, error)
 	GetAllNamespaces(context.Context, *Empty) (*StringsResponse, error)
 	Check(context.Context, *CheckRequest) (*Empty, error)
+	Explain(context.Context, *CheckRequest) (*Explanation, error)
 	GetShortestPath(context.Context, *GetShortestPathRequest) (*PathResponse, error)
 	GetAllPaths(context.Context, *GetAllPathsRequest) (*PathsResponse, error)
+	GetKShortestPaths(context.Context, *GetKShortestPathsRequest) (*PathsResponse, error)
 	GetAllObjectRelations(context.Context, *GetAllObjectRelationsRequest) (*RelationsResponse, error)
 	GetAllSubjectRelations(context.Context, *GetAllSubjectRelationsRequest) (*RelationsResponse, error)
-	ClearAllRelations(context.Context, *Empty) (*Empty, error)
+	// The Stream variants send every path or relation as the traversal
+	// finds it.
+	StreamAllPaths(*GetAllPathsRequest, RelationService_StreamAllPathsServer) error
+	StreamAllObjectRelations(*GetAllObjectRelationsRequest, RelationService_StreamAllObjectRelationsServer) error
+	StreamAllSubjectRelations(*GetAllSubjectRelationsRequest, RelationService_StreamAllSubjectRelationsServer) error
+	Expand(context.Context, *ExpandRequest) (*UsersetTree, error)
+	ClearAllRelations(context.Context, *Empty) (*WriteResponse, error)
+	BulkImport(RelationService_BulkImportServer) error
+	ReadSchema(context.Context, *Empty) (*Schema, error)
+	WriteSchema(context.Context, *WriteSchemaRequest) (*Schema, error)
+	WriteNamespace(context.Context, *NamespaceDefinition) (*Schema, error)
+	DeleteNamespace(context.Context, *DeleteNamespaceRequest) (*Schema, error)
 	mustEmbedUnimplementedRelationServiceServer()
 }
 
@@ -193,16 +423,16 @@ type UnimplementedRelationServiceServer struct {
 func (UnimplementedRelationServiceServer) Get(context.Context, *Relation) (*RelationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
 }
-func (UnimplementedRelationServiceServer) Create(context.Context, *RelationCreateRequest) (*Empty, error) {
+func (UnimplementedRelationServiceServer) Create(context.Context, *RelationCreateRequest) (*WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
-func (UnimplementedRelationServiceServer) Delete(context.Context, *Relation) (*Empty, error) {
+func (UnimplementedRelationServiceServer) Delete(context.Context, *Relation) (*WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
-func (UnimplementedRelationServiceServer) DeleteByQueries(context.Context, *DeleteByQueriesRequest) (*Empty, error) {
+func (UnimplementedRelationServiceServer) DeleteByQueries(context.Context, *DeleteByQueriesRequest) (*WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteByQueries not implemented")
 }
-func (UnimplementedRelationServiceServer) BatchOperation(context.Context, *BatchOperationRequest) (*Empty, error) {
+func (UnimplementedRelationServiceServer) BatchOperation(context.Context, *BatchOperationRequest) (*WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BatchOperation not implemented")
 }
 func (UnimplementedRelationServiceServer) GetAllNamespaces(context.Context, *Empty) (*StringsResponse, error) {
@@ -211,21 +441,54 @@ func (UnimplementedRelationServiceServer) GetAllNamespaces(context.Context, *Emp
 func (UnimplementedRelationServiceServer) Check(context.Context, *CheckRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
 }
+func (UnimplementedRelationServiceServer) Explain(context.Context, *CheckRequest) (*Explanation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Explain not implemented")
+}
 func (UnimplementedRelationServiceServer) GetShortestPath(context.Context, *GetShortestPathRequest) (*PathResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetShortestPath not implemented")
 }
 func (UnimplementedRelationServiceServer) GetAllPaths(context.Context, *GetAllPathsRequest) (*PathsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllPaths not implemented")
 }
+func (UnimplementedRelationServiceServer) GetKShortestPaths(context.Context, *GetKShortestPathsRequest) (*PathsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKShortestPaths not implemented")
+}
 func (UnimplementedRelationServiceServer) GetAllObjectRelations(context.Context, *GetAllObjectRelationsRequest) (*RelationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllObjectRelations not implemented")
 }
 func (UnimplementedRelationServiceServer) GetAllSubjectRelations(context.Context, *GetAllSubjectRelationsRequest) (*RelationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllSubjectRelations not implemented")
 }
-func (UnimplementedRelationServiceServer) ClearAllRelations(context.Context, *Empty) (*Empty, error) {
+func (UnimplementedRelationServiceServer) StreamAllPaths(*GetAllPathsRequest, RelationService_StreamAllPathsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAllPaths not implemented")
+}
+func (UnimplementedRelationServiceServer) StreamAllObjectRelations(*GetAllObjectRelationsRequest, RelationService_StreamAllObjectRelationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAllObjectRelations not implemented")
+}
+func (UnimplementedRelationServiceServer) StreamAllSubjectRelations(*GetAllSubjectRelationsRequest, RelationService_StreamAllSubjectRelationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamAllSubjectRelations not implemented")
+}
+func (UnimplementedRelationServiceServer) Expand(context.Context, *ExpandRequest) (*UsersetTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
+func (UnimplementedRelationServiceServer) ClearAllRelations(context.Context, *Empty) (*WriteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ClearAllRelations not implemented")
 }
+func (UnimplementedRelationServiceServer) BulkImport(RelationService_BulkImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkImport not implemented")
+}
+func (UnimplementedRelationServiceServer) ReadSchema(context.Context, *Empty) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadSchema not implemented")
+}
+func (UnimplementedRelationServiceServer) WriteSchema(context.Context, *WriteSchemaRequest) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteSchema not implemented")
+}
+func (UnimplementedRelationServiceServer) WriteNamespace(context.Context, *NamespaceDefinition) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteNamespace not implemented")
+}
+func (UnimplementedRelationServiceServer) DeleteNamespace(context.Context, *DeleteNamespaceRequest) (*Schema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNamespace not implemented")
+}
 func (UnimplementedRelationServiceServer) mustEmbedUnimplementedRelationServiceServer() {}
 
 // UnsafeRelationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -365,6 +628,24 @@ func _RelationService_Check_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelationService_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).Explain(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RelationService_GetShortestPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetShortestPathRequest)
 	if err := dec(in); err != nil {
@@ -401,6 +682,24 @@ func _RelationService_GetAllPaths_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelationService_GetKShortestPaths_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKShortestPathsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).GetKShortestPaths(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_GetKShortestPaths_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).GetKShortestPaths(ctx, req.(*GetKShortestPathsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RelationService_GetAllObjectRelations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetAllObjectRelationsRequest)
 	if err := dec(in); err != nil {
@@ -437,6 +736,87 @@ func _RelationService_GetAllSubjectRelations_Handler(srv interface{}, ctx contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelationService_StreamAllPaths_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAllPathsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RelationServiceServer).StreamAllPaths(m, &relationServiceStreamAllPathsServer{stream})
+}
+
+type RelationService_StreamAllPathsServer interface {
+	Send(*PathStreamResponse) error
+	grpc.ServerStream
+}
+
+type relationServiceStreamAllPathsServer struct {
+	grpc.ServerStream
+}
+
+func (x *relationServiceStreamAllPathsServer) Send(m *PathStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RelationService_StreamAllObjectRelations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAllObjectRelationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RelationServiceServer).StreamAllObjectRelations(m, &relationServiceStreamAllObjectRelationsServer{stream})
+}
+
+type RelationService_StreamAllObjectRelationsServer interface {
+	Send(*Relation) error
+	grpc.ServerStream
+}
+
+type relationServiceStreamAllObjectRelationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *relationServiceStreamAllObjectRelationsServer) Send(m *Relation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RelationService_StreamAllSubjectRelations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetAllSubjectRelationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RelationServiceServer).StreamAllSubjectRelations(m, &relationServiceStreamAllSubjectRelationsServer{stream})
+}
+
+type RelationService_StreamAllSubjectRelationsServer interface {
+	Send(*Relation) error
+	grpc.ServerStream
+}
+
+type relationServiceStreamAllSubjectRelationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *relationServiceStreamAllSubjectRelationsServer) Send(m *Relation) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _RelationService_Expand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExpandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).Expand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_Expand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).Expand(ctx, req.(*ExpandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RelationService_ClearAllRelations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -455,6 +835,104 @@ func _RelationService_ClearAllRelations_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelationService_BulkImport_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RelationServiceServer).BulkImport(&relationServiceBulkImportServer{stream})
+}
+
+type RelationService_BulkImportServer interface {
+	SendAndClose(*BulkImportResponse) error
+	Recv() (*Relation, error)
+	grpc.ServerStream
+}
+
+type relationServiceBulkImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *relationServiceBulkImportServer) SendAndClose(m *BulkImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *relationServiceBulkImportServer) Recv() (*Relation, error) {
+	m := new(Relation)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _RelationService_ReadSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).ReadSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_ReadSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).ReadSchema(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelationService_WriteSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteSchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).WriteSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_WriteSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).WriteSchema(ctx, req.(*WriteSchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelationService_WriteNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NamespaceDefinition)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).WriteNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_WriteNamespace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).WriteNamespace(ctx, req.(*NamespaceDefinition))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelationService_DeleteNamespace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNamespaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelationServiceServer).DeleteNamespace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelationService_DeleteNamespace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelationServiceServer).DeleteNamespace(ctx, req.(*DeleteNamespaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RelationService_ServiceDesc is the grpc.ServiceDesc for RelationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -490,6 +968,10 @@ var RelationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Check",
 			Handler:    _RelationService_Check_Handler,
 		},
+		{
+			MethodName: "Explain",
+			Handler:    _RelationService_Explain_Handler,
+		},
 		{
 			MethodName: "GetShortestPath",
 			Handler:    _RelationService_GetShortestPath_Handler,
@@ -498,6 +980,10 @@ var RelationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllPaths",
 			Handler:    _RelationService_GetAllPaths_Handler,
 		},
+		{
+			MethodName: "GetKShortestPaths",
+			Handler:    _RelationService_GetKShortestPaths_Handler,
+		},
 		{
 			MethodName: "GetAllObjectRelations",
 			Handler:    _RelationService_GetAllObjectRelations_Handler,
@@ -506,11 +992,52 @@ var RelationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllSubjectRelations",
 			Handler:    _RelationService_GetAllSubjectRelations_Handler,
 		},
+		{
+			MethodName: "Expand",
+			Handler:    _RelationService_Expand_Handler,
+		},
 		{
 			MethodName: "ClearAllRelations",
 			Handler:    _RelationService_ClearAllRelations_Handler,
 		},
+		{
+			MethodName: "ReadSchema",
+			Handler:    _RelationService_ReadSchema_Handler,
+		},
+		{
+			MethodName: "WriteSchema",
+			Handler:    _RelationService_WriteSchema_Handler,
+		},
+		{
+			MethodName: "WriteNamespace",
+			Handler:    _RelationService_WriteNamespace_Handler,
+		},
+		{
+			MethodName: "DeleteNamespace",
+			Handler:    _RelationService_DeleteNamespace_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAllPaths",
+			Handler:       _RelationService_StreamAllPaths_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAllObjectRelations",
+			Handler:       _RelationService_StreamAllObjectRelations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAllSubjectRelations",
+			Handler:       _RelationService_StreamAllSubjectRelations_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkImport",
+			Handler:       _RelationService_BulkImport_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "domain/delivery/proto/service.proto",
 }
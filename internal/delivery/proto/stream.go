@@ -0,0 +1,131 @@
+package proto
+
+import (
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StreamAllPaths sends the paths as GetAllPaths finds them, so the client
+// may cancel once it has seen enough.
+func (h *GrpcHandler) StreamAllPaths(req *GetAllPathsRequest, stream RelationService_StreamAllPathsServer) error {
+	c := stream.Context()
+	subject := domain.Node{
+		Namespace: req.Subject.Namespace,
+		Name:      req.Subject.Name,
+		Relation:  req.Subject.Relation,
+	}
+	object := domain.Node{
+		Namespace: req.Object.Namespace,
+		Name:      req.Object.Name,
+		Relation:  req.Object.Relation,
+	}
+	searchCondition := domain.SearchCondition{
+		In: domain.Compare{
+			Namespaces: req.SearchCondition.In.Namespaces,
+			Names:      req.SearchCondition.In.Name,
+			Relations:  req.SearchCondition.In.Relation,
+		},
+	}
+	truncated, err := h.reader(c).StreamAllPaths(c, subject, object, searchCondition, func(path []domain.Relation) error {
+		relations := make([]*Relation, len(path))
+		for i, rel := range path {
+			relations[i] = toProtoRelation(rel)
+		}
+		return stream.Send(&PathStreamResponse{
+			Path: &PathResponse{Relations: relations},
+		})
+	}, readOptions(req.Consistency, req.Budget))
+	if err != nil {
+		return readError(c, err)
+	}
+	if truncated {
+		return stream.Send(&PathStreamResponse{Truncated: true})
+	}
+	return nil
+}
+
+// StreamAllObjectRelations sends the relations as GetAllObjectRelations
+// finds them.
+func (h *GrpcHandler) StreamAllObjectRelations(req *GetAllObjectRelationsRequest, stream RelationService_StreamAllObjectRelationsServer) error {
+	c := stream.Context()
+	subject := domain.Node{
+		Namespace: req.Subject.Namespace,
+		Name:      req.Subject.Name,
+		Relation:  req.Subject.Relation,
+	}
+	searchCondition := domain.SearchCondition{
+		In: domain.Compare{
+			Namespaces: req.SearchCondition.In.Namespaces,
+			Names:      req.SearchCondition.In.Name,
+			Relations:  req.SearchCondition.In.Relation,
+		},
+	}
+	collectCondition := domain.CollectCondition{
+		In: domain.Compare{
+			Namespaces: req.CollectCondition.In.Namespaces,
+			Names:      req.CollectCondition.In.Name,
+			Relations:  req.CollectCondition.In.Relation,
+		},
+	}
+	err := h.reader(c).StreamAllObjectRelations(c, subject, searchCondition, collectCondition, int(req.MaxDepth), func(relation domain.Relation) error {
+		return stream.Send(toProtoRelation(relation))
+	}, readOptions(req.Consistency, req.Budget))
+	if err != nil {
+		return readError(c, err)
+	}
+	return nil
+}
+
+// StreamAllSubjectRelations sends the relations as GetAllSubjectRelations
+// finds them.
+func (h *GrpcHandler) StreamAllSubjectRelations(req *GetAllSubjectRelationsRequest, stream RelationService_StreamAllSubjectRelationsServer) error {
+	c := stream.Context()
+	object := domain.Node{
+		Namespace: req.Object.Namespace,
+		Name:      req.Object.Name,
+		Relation:  req.Object.Relation,
+	}
+	searchCondition := domain.SearchCondition{
+		In: domain.Compare{
+			Namespaces: req.SearchCondition.In.Namespaces,
+			Names:      req.SearchCondition.In.Name,
+			Relations:  req.SearchCondition.In.Relation,
+		},
+	}
+	collectCondition := domain.CollectCondition{
+		In: domain.Compare{
+			Namespaces: req.CollectCondition.In.Namespaces,
+			Names:      req.CollectCondition.In.Name,
+			Relations:  req.CollectCondition.In.Relation,
+		},
+	}
+	err := h.reader(c).StreamAllSubjectRelations(c, object, searchCondition, collectCondition, int(req.MaxDepth), func(relation domain.Relation) error {
+		return stream.Send(toProtoRelation(relation))
+	}, readOptions(req.Consistency, req.Budget))
+	if err != nil {
+		return readError(c, err)
+	}
+	return nil
+}
+
+func toProtoRelation(relation domain.Relation) *Relation {
+	protoRelation := &Relation{
+		ObjectNamespace:  relation.ObjectNamespace,
+		ObjectName:       relation.ObjectName,
+		Relation:         relation.Relation,
+		SubjectNamespace: relation.SubjectNamespace,
+		SubjectName:      relation.SubjectName,
+		SubjectRelation:  relation.SubjectRelation,
+		Condition:        relation.Condition,
+	}
+	// the params were validated as a JSON object when the relation was
+	// written
+	if params, _ := relation.ConditionParams.Map(); len(params) > 0 {
+		protoRelation.ConditionParams, _ = structpb.NewStruct(params)
+	}
+	if relation.ExpiresAt != nil {
+		protoRelation.ExpiresAt = timestamppb.New(*relation.ExpiresAt)
+	}
+	return protoRelation
+}
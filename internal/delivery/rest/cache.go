@@ -0,0 +1,17 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get the cache counters
+// @Description Hits, misses and entries of the check decision cache and of the adjacency lookup cache. All counters are zero if the cache is disabled.
+// @Tags Cache
+// @Produce json
+// @Success 200 {object} domain.CacheStats
+// @Router /cache/stats [get]
+func (h *RelationHandler) GetCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.RelationUsecase.CacheStats())
+}
@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportLineSize bounds one NDJSON line of a bulk import.
+const maxImportLineSize = 1 << 20
+
+// importEvent is one line of the NDJSON response of BulkImport, exactly one
+// of its fields is set.
+type importEvent struct {
+	Progress *domain.ImportProgress `json:"progress,omitempty"`
+	Result   *domain.ImportResult   `json:"result,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+}
+
+// @Summary Import relations in bulk
+// @Description Reads an NDJSON body with one relation object per line. Relations that exist are skipped, invalid lines are reported and skipped, and a cycle fails the whole import.
+// @Description The response is NDJSON as well: progress lines while the import runs, then one line with either the result or the error.
+// @Tags Relation
+// @Accept application/x-ndjson
+// @Produce application/x-ndjson
+// @Param relations body domain.Relation true "One relation per line"
+// @Success 200 {object} domain.ImportResult
+// @Router /relation/import [post]
+func (h *RelationHandler) BulkImport(c *gin.Context) {
+	// progress is written while the body is still read, which HTTP/1
+	// servers only allow in full duplex mode
+	_ = http.NewResponseController(c.Writer).EnableFullDuplex()
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	encoder := json.NewEncoder(c.Writer)
+	send := func(event importEvent) {
+		encoder.Encode(event)
+		c.Writer.Flush()
+	}
+
+	result, err := h.RelationUsecase.BulkImport(
+		c.Request.Context(),
+		newNdjsonReader(c.Request.Body),
+		func(progress domain.ImportProgress) {
+			send(importEvent{Progress: &progress})
+		},
+	)
+	if err != nil {
+		send(importEvent{Error: err.Error()})
+		return
+	}
+	send(importEvent{Result: &result})
+}
+
+// ndjsonReader reads one relation per line.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+}
+
+func newNdjsonReader(r io.Reader) *ndjsonReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+	return &ndjsonReader{scanner: scanner}
+}
+
+func (r *ndjsonReader) Read() (domain.Relation, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return domain.Relation{}, err
+		}
+		return domain.Relation{}, io.EOF
+	}
+	var relation domain.Relation
+	decoder := json.NewDecoder(bytes.NewReader(r.scanner.Bytes()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&relation); err != nil {
+		return domain.Relation{}, fmt.Errorf("%w: %s", domain.RequestBodyError{}, err)
+	}
+	return relation, nil
+}
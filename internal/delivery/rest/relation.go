@@ -1,8 +1,11 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/skyrocketOoO/zanazibar-dag/domain"
 	usecasedomain "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
@@ -20,6 +23,81 @@ func NewRelationHandler(permissionUsecase usecasedomain.RelationUsecase) *Relati
 	}
 }
 
+// ReadYourWritesHeader makes a read go to the primary database when set to
+// true, so it sees the writes made before it.
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
+// reader returns the usecase serving the reads of the request.
+func (h *RelationHandler) reader(c *gin.Context) usecasedomain.RelationUsecase {
+	if readYourWrites, _ := strconv.ParseBool(c.GetHeader(ReadYourWritesHeader)); readYourWrites {
+		return h.RelationUsecase.Primary()
+	}
+	return h.RelationUsecase
+}
+
+// consistency is embedded in the request bodies of the reads taking a
+// consistency token.
+type consistency struct {
+	AtLeastAsFresh string `json:"at_least_as_fresh"`
+	ExactSnapshot  string `json:"exact_snapshot"`
+}
+
+func (c consistency) toUsecase() usecasedomain.Consistency {
+	return usecasedomain.Consistency{AtLeastAsFresh: c.AtLeastAsFresh, ExactSnapshot: c.ExactSnapshot}
+}
+
+// budget is embedded in the request bodies of the graph queries, its fields
+// override the server limits when set. Lookups take their depth from
+// max_depth of the request.
+type budget struct {
+	MaxDepth        int `json:"max_depth"`
+	MaxVisitedNodes int `json:"max_visited_nodes"`
+	MaxPaths        int `json:"max_paths"`
+	TimeoutMs       int `json:"timeout_ms"`
+}
+
+func readOptions(c consistency, b budget) usecasedomain.ReadOptions {
+	return usecasedomain.ReadOptions{
+		Consistency: c.toUsecase(),
+		Budget: domain.Budget{
+			MaxDepth: b.MaxDepth,
+			MaxNodes: b.MaxVisitedNodes,
+			MaxPaths: b.MaxPaths,
+			Timeout:  time.Duration(b.TimeoutMs) * time.Millisecond,
+		},
+	}
+}
+
+// badRequest reports whether the error of a read is caused by its request.
+func badRequest(err error) bool {
+	switch err.(type) {
+	case domain.RequestBodyError, domain.InvalidTokenError, domain.SnapshotUnavailableError, domain.InvalidSchemaError, domain.SchemaViolationError,
+		domain.ConditionContextError:
+		return true
+	}
+	return false
+}
+
+// StatusClientClosedRequest is the status, borrowed from nginx, of a request
+// whose client went away before the response.
+const StatusClientClosedRequest = 499
+
+// errorStatus returns the status of an error the request is not to blame for:
+// 422 if a graph query ran out of its budget, 504 if the request ran past its
+// deadline, 499 if its client went away, and 500 otherwise.
+func errorStatus(c *gin.Context, err error) int {
+	done := c.Request.Context().Err()
+	switch {
+	case errors.As(err, &domain.BudgetExceededError{}):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded) || done == context.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled) || done == context.Canceled:
+		return StatusClientClosedRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // @Summary Query relations based on parameters
 // @Description Query relations based on specified parameters.
 // @Tags Relation
@@ -33,8 +111,11 @@ func NewRelationHandler(permissionUsecase usecasedomain.RelationUsecase) *Relati
 // @Param subject-relation query string false "Subject Relation"
 // @Param page-token query string false "Page token"
 // @Param page-size query string false "Page size"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
 // @Success 200 {object} delivery.Get.respBody
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
 // @Router /relation/ [get]
 func (h *RelationHandler) Get(c *gin.Context) {
 	relation := domain.Relation{
@@ -53,12 +134,12 @@ func (h *RelationHandler) Get(c *gin.Context) {
 		})
 		return
 	}
-	relations, token, err := h.RelationUsecase.Get(relation, usecasedomain.PageOptions{
+	relations, token, err := h.reader(c).Get(c.Request.Context(), relation, usecasedomain.PageOptions{
 		PageToken: pageToken,
 		PageSize:  pageSize,
 	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -79,9 +160,12 @@ func (h *RelationHandler) Get(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param relation body delivery.Create.requestBody true "Relation object to be created"
-// @Success 200
+// @Success 200 {object} domain.TokenResponse
 // @Failure 400 {object} domain.ErrResponse
+// @Failure 409 {object} domain.ErrResponse
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
 // @Router /relation/ [post]
 func (h *RelationHandler) Create(c *gin.Context) {
 	type requestBody struct {
@@ -95,8 +179,14 @@ func (h *RelationHandler) Create(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.RelationUsecase.Create(reqBody.Relation, reqBody.ExistOk); err != nil {
-		if _, ok := err.(domain.CauseCycleError); ok {
+	token, err := h.RelationUsecase.Create(c.Request.Context(), reqBody.Relation, reqBody.ExistOk)
+	if err != nil {
+		if _, ok := err.(domain.AlreadyExistsError); ok {
+			c.JSON(http.StatusConflict, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		} else if _, ok := err.(domain.CauseCycleError); ok {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
@@ -106,13 +196,23 @@ func (h *RelationHandler) Create(c *gin.Context) {
 				Error: err.Error(),
 			})
 			return
+		} else if _, ok := err.(domain.SchemaViolationError); ok {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		} else if _, ok := err.(domain.InvalidConditionError); ok {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, domain.TokenResponse{Token: token})
 }
 
 // @Summary Delete a relation
@@ -121,9 +221,11 @@ func (h *RelationHandler) Create(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param relation body delivery.Delete.requestBody true "Relation object to be deleted"
-// @Success 200
+// @Success 200 {object} domain.TokenResponse
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
 // @Router /relation/ [delete]
 func (h *RelationHandler) Delete(c *gin.Context) {
 	type requestBody struct {
@@ -136,19 +238,20 @@ func (h *RelationHandler) Delete(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.RelationUsecase.Delete(reqBody.Relation); err != nil {
+	token, err := h.RelationUsecase.Delete(c.Request.Context(), reqBody.Relation)
+	if err != nil {
 		if _, ok := err.(domain.RequestBodyError); ok {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, domain.TokenResponse{Token: token})
 }
 
 func (h *RelationHandler) DeleteByQueries(c *gin.Context) {
@@ -162,15 +265,29 @@ func (h *RelationHandler) DeleteByQueries(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.RelationUsecase.DeleteByQueries(body.Queries); err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+	token, err := h.RelationUsecase.DeleteByQueries(c.Request.Context(), body.Queries)
+	if err != nil {
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, domain.TokenResponse{Token: token})
 }
 
+// @Summary Apply create and delete operations atomically
+// @Description Apply all operations in one transaction. Creates are cycle checked against the graph including earlier operations of the batch, and a failure reports the index of the failed operation.
+// @Tags Relation
+// @Accept json
+// @Produce json
+// @Param operations body delivery.BatchOperation.requestBody true "Operations to apply in order"
+// @Success 200 {object} domain.TokenResponse
+// @Failure 400 {object} domain.BatchErrResponse
+// @Failure 409 {object} domain.BatchErrResponse
+// @Failure 500 {object} domain.BatchErrResponse
+// @Failure 499 {object} domain.BatchErrResponse
+// @Failure 504 {object} domain.BatchErrResponse
+// @Router /relation/batch-operation [post]
 func (h *RelationHandler) BatchOperation(c *gin.Context) {
 	type requestBody struct {
 		Operations []domain.Operation `json:"operations"`
@@ -182,32 +299,42 @@ func (h *RelationHandler) BatchOperation(c *gin.Context) {
 		})
 		return
 	}
-	if err := h.RelationUsecase.BatchOperation(body.Operations); err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
-			c.JSON(http.StatusBadRequest, domain.ErrResponse{
-				Error: err.Error(),
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+	token, err := h.RelationUsecase.BatchOperation(c.Request.Context(), body.Operations)
+	if err != nil {
+		resp := domain.BatchErrResponse{
 			Error: err.Error(),
-		})
+		}
+		if batchErr, ok := err.(domain.BatchOperationError); ok {
+			resp.Index = &batchErr.Index
+			err = batchErr.Err
+		}
+		switch err.(type) {
+		case domain.AlreadyExistsError:
+			c.JSON(http.StatusConflict, resp)
+		case domain.CauseCycleError, domain.RequestBodyError, domain.SchemaViolationError, domain.InvalidConditionError:
+			c.JSON(http.StatusBadRequest, resp)
+		default:
+			c.JSON(errorStatus(c, err), resp)
+		}
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, domain.TokenResponse{Token: token})
 }
 
 // @Summary Get all unique namespaces
 // @Description Retrieve all unique namespaces for relations.
 // @Tags Relation
 // @Produce json
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
 // @Success 200 {object} domain.StringsResponse
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
 // @Router /relation/get-all-namespaces [post]
 func (h *RelationHandler) GetAllNamespaces(c *gin.Context) {
-	namespaces, err := h.RelationUsecase.GetAllNamespaces()
+	namespaces, err := h.reader(c).GetAllNamespaces(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -218,21 +345,30 @@ func (h *RelationHandler) GetAllNamespaces(c *gin.Context) {
 }
 
 // @Summary Check if a relation link exists
-// @Description Check if a relation link exists between two entities
+// @Description Check if a relation link exists between two entities. Relations with a condition are only crossed when it holds in the context of the request.
 // @Tags Relation
 // @Accept json
 // @Produce json
 // @Param relation body delivery.Check.requestBody true "comment"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
 // @Success 200
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 403
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
 // @Router /relation/check [post]
 func (h *RelationHandler) Check(c *gin.Context) {
 	type requestBody struct {
 		Subject         domain.Node            `json:"subject" binding:"required"`
 		Object          domain.Node            `json:"object" binding:"required"`
 		SearchCondition domain.SearchCondition `json:"search_condition"`
+		// Context holds the variables the conditions of relations are
+		// evaluated with.
+		Context domain.ConditionContext `json:"context"`
+		consistency
+		budget
 	}
 	body := requestBody{}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -241,15 +377,17 @@ func (h *RelationHandler) Check(c *gin.Context) {
 		})
 		return
 	}
-	ok, err := h.RelationUsecase.Check(body.Subject, body.Object, body.SearchCondition)
+	options := readOptions(body.consistency, body.budget)
+	options.Context = body.Context
+	ok, err := h.reader(c).Check(c.Request.Context(), body.Subject, body.Object, body.SearchCondition, options)
 	if err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
+		if badRequest(err) {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -260,22 +398,83 @@ func (h *RelationHandler) Check(c *gin.Context) {
 	c.Status(http.StatusOK)
 }
 
+// @Summary Explain a permission check
+// @Description Decide like check whether the subject holds the relation of the object under the namespace schema, and return the branches the decision rests on: for an allowed union the branch that allowed, for a denied intersection or exclusion the branch that denied.
+// @Tags Relation
+// @Accept json
+// @Produce json
+// @Param relation body delivery.Explain.requestBody true "Subject, object and search condition"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Success 200 {object} delivery.Explain.response "Explanation, allowed at its top if access is granted"
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
+// @Router /relation/explain [post]
+func (h *RelationHandler) Explain(c *gin.Context) {
+	type requestBody struct {
+		Subject         domain.Node            `json:"subject" binding:"required"`
+		Object          domain.Node            `json:"object" binding:"required"`
+		SearchCondition domain.SearchCondition `json:"search_condition"`
+		// Context holds the variables the conditions of relations are
+		// evaluated with.
+		Context domain.ConditionContext `json:"context"`
+		consistency
+		budget
+	}
+	body := requestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	options := readOptions(body.consistency, body.budget)
+	options.Context = body.Context
+	explanation, err := h.reader(c).Explain(c.Request.Context(), body.Subject, body.Object, body.SearchCondition, options)
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	type response struct {
+		Explanation domain.Explanation `json:"explanation"`
+	}
+	c.JSON(http.StatusOK, response{
+		Explanation: *explanation,
+	})
+}
+
 // @Summary Get the shortest path between two entities in a relation graph
 // @Description Get the shortest path between two entities in a relation graph
 // @Tags Relation
 // @Accept json
 // @Produce json
 // @Param relation body delivery.GetShortestPath.requestBody true "comment"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
 // @Success 200 {object} domain.DataResponse "Shortest path between entities"
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 403
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
 // @Router /relation/get-shortest-path [post]
 func (h *RelationHandler) GetShortestPath(c *gin.Context) {
 	type requestBody struct {
 		Subject         domain.Node            `json:"subject" binding:"required"`
 		Object          domain.Node            `json:"object" binding:"required"`
 		SearchCondition domain.SearchCondition `json:"search_condition"`
+		consistency
+		budget
 	}
 	body := requestBody{}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -284,15 +483,15 @@ func (h *RelationHandler) GetShortestPath(c *gin.Context) {
 		})
 		return
 	}
-	paths, err := h.RelationUsecase.GetShortestPath(body.Subject, body.Object, body.SearchCondition)
+	paths, err := h.reader(c).GetShortestPath(c.Request.Context(), body.Subject, body.Object, body.SearchCondition, readOptions(body.consistency, body.budget))
 	if err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
+		if badRequest(err) {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -310,17 +509,25 @@ func (h *RelationHandler) GetShortestPath(c *gin.Context) {
 // @Tags Relation
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Param relation body delivery.GetAllPaths.requestBody true "Relation object specifying the entities"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Param Accept header string false "application/x-ndjson streams the results as they are found, one per line, then a done or error line"
 // @Success 200 {object} delivery.GetAllPaths.response "All paths between entities"
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 403
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
 // @Router /relation/get-all-paths [post]
 func (h *RelationHandler) GetAllPaths(c *gin.Context) {
 	type requestBody struct {
 		Subject         domain.Node            `json:"subject" binding:"required"`
 		Object          domain.Node            `json:"object" binding:"required"`
 		SearchCondition domain.SearchCondition `json:"search_condition"`
+		consistency
+		budget
 	}
 	body := requestBody{}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -329,15 +536,19 @@ func (h *RelationHandler) GetAllPaths(c *gin.Context) {
 		})
 		return
 	}
-	paths, err := h.RelationUsecase.GetAllPaths(body.Subject, body.Object, body.SearchCondition)
+	if streams(c) {
+		h.streamAllPaths(c, body.Subject, body.Object, body.SearchCondition, readOptions(body.consistency, body.budget))
+		return
+	}
+	paths, truncated, err := h.reader(c).GetAllPaths(c.Request.Context(), body.Subject, body.Object, body.SearchCondition, readOptions(body.consistency, body.budget))
 	if err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
+		if badRequest(err) {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -345,6 +556,61 @@ func (h *RelationHandler) GetAllPaths(c *gin.Context) {
 	if len(paths) == 0 {
 		c.Status(http.StatusForbidden)
 	}
+	type response struct {
+		Data [][]domain.Relation `json:"data"`
+		// Truncated is set when a budget ran out before every path was found.
+		Truncated bool `json:"truncated"`
+	}
+	c.JSON(http.StatusOK, response{
+		Data:      paths,
+		Truncated: truncated,
+	})
+}
+
+// @Summary Get the k shortest paths between two entities in a relation graph
+// @Description Get up to k paths between two entities, shortest first. Paths of the same length are ranked by the preference, through the earlier listed namespaces and relations first.
+// @Tags Relation
+// @Accept json
+// @Produce json
+// @Param relation body delivery.GetKShortestPaths.requestBody true "Entities, k and preference"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Success 200 {object} delivery.GetKShortestPaths.response "Ranked paths between entities"
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
+// @Router /relation/get-k-shortest-paths [post]
+func (h *RelationHandler) GetKShortestPaths(c *gin.Context) {
+	type requestBody struct {
+		Subject         domain.Node            `json:"subject" binding:"required"`
+		Object          domain.Node            `json:"object" binding:"required"`
+		SearchCondition domain.SearchCondition `json:"search_condition"`
+		K               int                    `json:"k" binding:"required"`
+		Preference      domain.PathPreference  `json:"preference"`
+		consistency
+		budget
+	}
+	body := requestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	paths, err := h.reader(c).GetKShortestPaths(c.Request.Context(), body.Subject, body.Object, body.SearchCondition, body.K, body.Preference, readOptions(body.consistency, body.budget))
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
 	type response struct {
 		Data [][]domain.Relation `json:"data"`
 	}
@@ -358,11 +624,17 @@ func (h *RelationHandler) GetAllPaths(c *gin.Context) {
 // @Tags Relation
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Param subject body delivery.GetAllObjectRelations.requestBody true "Object information (namespace, name, relation)"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Param Accept header string false "application/x-ndjson streams the results as they are found, one per line, then a done or error line"
 // @Success 200 {object} domain.DataResponse "All relations for the specified object"
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 403
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
 // @Router /relation/get-all-object-relations [post]
 func (h *RelationHandler) GetAllObjectRelations(c *gin.Context) {
 	type requestBody struct {
@@ -370,6 +642,8 @@ func (h *RelationHandler) GetAllObjectRelations(c *gin.Context) {
 		SearchCondition  domain.SearchCondition  `json:"search_condition"`
 		CollectCondition domain.CollectCondition `json:"collect_condition"`
 		MaxDepth         int                     `json:"max_depth"`
+		consistency
+		budget
 	}
 	body := requestBody{}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -378,20 +652,36 @@ func (h *RelationHandler) GetAllObjectRelations(c *gin.Context) {
 		})
 		return
 	}
-	relations, err := h.RelationUsecase.GetAllObjectRelations(
+	if streams(c) {
+		streamRelations(c, func(emit func(relation domain.Relation) error) error {
+			return h.reader(c).StreamAllObjectRelations(
+				c.Request.Context(),
+				domain.Node(body.Subject),
+				body.SearchCondition,
+				body.CollectCondition,
+				body.MaxDepth,
+				emit,
+				readOptions(body.consistency, body.budget),
+			)
+		})
+		return
+	}
+	relations, err := h.reader(c).GetAllObjectRelations(
+		c.Request.Context(),
 		domain.Node(body.Subject),
 		body.SearchCondition,
 		body.CollectCondition,
 		body.MaxDepth,
+		readOptions(body.consistency, body.budget),
 	)
 	if err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
+		if badRequest(err) {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -406,11 +696,17 @@ func (h *RelationHandler) GetAllObjectRelations(c *gin.Context) {
 // @Tags Relation
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Param object body delivery.GetAllSubjectRelations.requestBody true "Subject information (namespace, name, relation)"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Param Accept header string false "application/x-ndjson streams the results as they are found, one per line, then a done or error line"
 // @Success 200 {object} domain.DataResponse "All relations for the specified subject"
 // @Failure 400 {object} domain.ErrResponse
 // @Failure 403
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
 // @Router /relation/get-all-subject-relations [post]
 func (h *RelationHandler) GetAllSubjectRelations(c *gin.Context) {
 	type requestBody struct {
@@ -418,6 +714,8 @@ func (h *RelationHandler) GetAllSubjectRelations(c *gin.Context) {
 		SearchCondition  domain.SearchCondition  `json:"search_condition"`
 		CollectCondition domain.CollectCondition `json:"collect_condition"`
 		MaxDepth         int                     `json:"max_depth"`
+		consistency
+		budget
 	}
 	body := requestBody{}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -426,20 +724,36 @@ func (h *RelationHandler) GetAllSubjectRelations(c *gin.Context) {
 		})
 		return
 	}
-	relations, err := h.RelationUsecase.GetAllSubjectRelations(
+	if streams(c) {
+		streamRelations(c, func(emit func(relation domain.Relation) error) error {
+			return h.reader(c).StreamAllSubjectRelations(
+				c.Request.Context(),
+				domain.Node(body.Object),
+				body.SearchCondition,
+				body.CollectCondition,
+				body.MaxDepth,
+				emit,
+				readOptions(body.consistency, body.budget),
+			)
+		})
+		return
+	}
+	relations, err := h.reader(c).GetAllSubjectRelations(
+		c.Request.Context(),
 		domain.Node(body.Object),
 		body.SearchCondition,
 		body.CollectCondition,
 		body.MaxDepth,
+		readOptions(body.consistency, body.budget),
 	)
 	if err != nil {
-		if _, ok := err.(domain.RequestBodyError); ok {
+		if badRequest(err) {
 			c.JSON(http.StatusBadRequest, domain.ErrResponse{
 				Error: err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -449,6 +763,55 @@ func (h *RelationHandler) GetAllSubjectRelations(c *gin.Context) {
 	})
 }
 
+// @Summary Expand the subjects of an object relation
+// @Description Get the tree of the subjects holding the relation of an object: subject sets like group:eng#member with their own subjects below them, and subjects without relation at the bottom.
+// @Tags Relation
+// @Accept json
+// @Produce json
+// @Param relation body delivery.Expand.requestBody true "Object and depth, 0 takes the max_depth of the budget"
+// @Param X-Read-Your-Writes header bool false "Read from the primary database"
+// @Success 200 {object} delivery.Expand.response "Userset tree of the object"
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
+// @Failure 422 {object} domain.ErrResponse
+// @Router /relation/expand [post]
+func (h *RelationHandler) Expand(c *gin.Context) {
+	type requestBody struct {
+		Object domain.Node `json:"object" binding:"required"`
+		Depth  int         `json:"depth"`
+		consistency
+		budget
+	}
+	body := requestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	tree, err := h.reader(c).Expand(c.Request.Context(), body.Object, body.Depth, readOptions(body.consistency, body.budget))
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	type response struct {
+		Tree domain.UsersetTree `json:"tree"`
+	}
+	c.JSON(http.StatusOK, response{
+		Tree: *tree,
+	})
+}
+
 func (h *RelationHandler) GetTree(c *gin.Context) {
 	type requestBody struct {
 		Subject  domain.Node `json:"subject" binding:"required"`
@@ -461,7 +824,8 @@ func (h *RelationHandler) GetTree(c *gin.Context) {
 		})
 		return
 	}
-	tree, err := h.RelationUsecase.GetTree(
+	tree, err := h.reader(c).GetTree(
+		c.Request.Context(),
 		body.Subject,
 		body.MaxDepth,
 	)
@@ -472,7 +836,7 @@ func (h *RelationHandler) GetTree(c *gin.Context) {
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
@@ -490,16 +854,18 @@ func (h *RelationHandler) GetTree(c *gin.Context) {
 // @Tags Relation
 // @Accept json
 // @Produce json
-// @Success 200
+// @Success 200 {object} domain.TokenResponse
 // @Failure 500 {object} domain.ErrResponse
+// @Failure 499 {object} domain.ErrResponse
+// @Failure 504 {object} domain.ErrResponse
 // @Router /relation/clear-all-relations [post]
 func (h *RelationHandler) ClearAllRelations(c *gin.Context) {
-	err := h.RelationUsecase.ClearAllRelations()
+	token, err := h.RelationUsecase.ClearAllRelations(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrResponse{
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
 			Error: err.Error(),
 		})
 		return
 	}
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, domain.TokenResponse{Token: token})
 }
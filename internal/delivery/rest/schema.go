@@ -0,0 +1,158 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+)
+
+// @Summary Get the namespace schema
+// @Description Get the latest version of the namespace definitions, version 0 with no namespaces if none was written.
+// @Tags Schema
+// @Produce json
+// @Success 200 {object} domain.Schema
+// @Failure 500 {object} domain.ErrResponse
+// @Router /schema [get]
+func (h *RelationHandler) ReadSchema(c *gin.Context) {
+	schema, err := h.RelationUsecase.ReadSchema(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+
+// @Summary Write the namespace schema
+// @Description Store the namespace definitions as the next version of the schema. A relation is defined by its rewrite, an expression of direct, relations of the same object and tupleset->relation terms joined by + (union), & (intersection) and - (exclusion), like "direct + editor + parent->viewer" or "reader - banned". Check and the traversals apply the new version from then on.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Param schema body delivery.WriteSchema.requestBody true "Namespace definitions"
+// @Success 200 {object} domain.Schema
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Router /schema [put]
+func (h *RelationHandler) WriteSchema(c *gin.Context) {
+	type requestBody struct {
+		Namespaces []domain.NamespaceDefinition `json:"namespaces"`
+	}
+	body := requestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	schema, err := h.RelationUsecase.WriteSchema(c.Request.Context(), body.Namespaces)
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+
+// @Summary Get a namespace definition
+// @Description Get the relations the latest schema declares for a namespace, with the subject types each one takes.
+// @Tags Schema
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Success 200 {object} domain.NamespaceDefinition
+// @Failure 404 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Router /schema/namespaces/{namespace} [get]
+func (h *RelationHandler) ReadNamespace(c *gin.Context) {
+	schema, err := h.RelationUsecase.ReadSchema(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	for _, namespace := range schema.Namespaces {
+		if namespace.Name == c.Param("namespace") {
+			c.JSON(http.StatusOK, namespace)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, domain.ErrResponse{
+		Error: "namespace " + c.Param("namespace") + " is not defined",
+	})
+}
+
+// @Summary Write a namespace definition
+// @Description Add a namespace to the schema or replace its definition, as the next schema version. Relations of the namespace are written to the declared relations only, with subjects of their subject types, like user or group#member.
+// @Tags Schema
+// @Accept json
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Param definition body delivery.WriteNamespace.requestBody true "Relation definitions"
+// @Success 200 {object} domain.Schema
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Router /schema/namespaces/{namespace} [put]
+func (h *RelationHandler) WriteNamespace(c *gin.Context) {
+	type requestBody struct {
+		Relations []domain.RelationDefinition `json:"relations"`
+	}
+	body := requestBody{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	schema, err := h.RelationUsecase.WriteNamespace(c.Request.Context(), domain.NamespaceDefinition{
+		Name:      c.Param("namespace"),
+		Relations: body.Relations,
+	})
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
+
+// @Summary Delete a namespace definition
+// @Description Remove a namespace from the schema, as the next schema version. The namespace takes any relation from then on.
+// @Tags Schema
+// @Produce json
+// @Param namespace path string true "Namespace"
+// @Success 200 {object} domain.Schema
+// @Failure 400 {object} domain.ErrResponse
+// @Failure 500 {object} domain.ErrResponse
+// @Router /schema/namespaces/{namespace} [delete]
+func (h *RelationHandler) DeleteNamespace(c *gin.Context) {
+	schema, err := h.RelationUsecase.DeleteNamespace(c.Request.Context(), c.Param("namespace"))
+	if err != nil {
+		if badRequest(err) {
+			c.JSON(http.StatusBadRequest, domain.ErrResponse{
+				Error: err.Error(),
+			})
+			return
+		}
+		c.JSON(errorStatus(c, err), domain.ErrResponse{
+			Error: err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, schema)
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	usecasedomain "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ndjsonContentType, accepted by a request of get-all-paths or the lookups,
+// streams the results as the traversal finds them.
+const ndjsonContentType = "application/x-ndjson"
+
+// streamEvent is one line of a streamed response, exactly one of its fields
+// is set. The last line is either done, with truncated set if a budget ran
+// out, or the error.
+type streamEvent struct {
+	Path      []domain.Relation `json:"path,omitempty"`
+	Relation  *domain.Relation  `json:"relation,omitempty"`
+	Done      bool              `json:"done,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// streams reports whether the request accepts a streamed response.
+func streams(c *gin.Context) bool {
+	return c.NegotiateFormat(binding.MIMEJSON, ndjsonContentType) == ndjsonContentType
+}
+
+type ndjsonWriter struct {
+	c       *gin.Context
+	encoder *json.Encoder
+}
+
+func newNdjsonWriter(c *gin.Context) *ndjsonWriter {
+	return &ndjsonWriter{c: c, encoder: json.NewEncoder(c.Writer)}
+}
+
+// send writes event as one line and flushes it, so the client sees it while
+// the traversal goes on.
+func (w *ndjsonWriter) send(event streamEvent) error {
+	if !w.c.Writer.Written() {
+		w.c.Header("Content-Type", ndjsonContentType)
+		w.c.Status(http.StatusOK)
+	}
+	if err := w.encoder.Encode(event); err != nil {
+		return err
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+// fail answers err with its status while nothing was sent, and as the last
+// line after that.
+func (w *ndjsonWriter) fail(err error) {
+	if w.c.Writer.Written() {
+		w.send(streamEvent{Error: err.Error()})
+		return
+	}
+	status := errorStatus(w.c, err)
+	if badRequest(err) {
+		status = http.StatusBadRequest
+	}
+	w.c.JSON(status, domain.ErrResponse{
+		Error: err.Error(),
+	})
+}
+
+func (h *RelationHandler) streamAllPaths(c *gin.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options usecasedomain.ReadOptions) {
+	w := newNdjsonWriter(c)
+	truncated, err := h.reader(c).StreamAllPaths(c.Request.Context(), subject, object, searchCondition, func(path []domain.Relation) error {
+		return w.send(streamEvent{Path: path})
+	}, options)
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.send(streamEvent{Done: true, Truncated: truncated})
+}
+
+// streamRelations streams the relations a lookup passes to emit.
+func streamRelations(c *gin.Context, lookup func(emit func(relation domain.Relation) error) error) {
+	w := newNdjsonWriter(c)
+	err := lookup(func(relation domain.Relation) error {
+		return w.send(streamEvent{Relation: &relation})
+	})
+	if err != nil {
+		w.fail(err)
+		return
+	}
+	w.send(streamEvent{Done: true})
+}
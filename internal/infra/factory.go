@@ -0,0 +1,37 @@
+package infra
+
+import (
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/inmemory"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/kv"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"github.com/spf13/viper"
+)
+
+// NewRelationRepository creates the storage backend selected by db.type.
+func NewRelationRepository() (sqldomain.RelationRepository, error) {
+	switch viper.GetString("db.type") {
+	case "inmemory":
+		return inmemory.NewRelationRepository(), nil
+	case "kv":
+		path := viper.GetString("db.kv.path")
+		if path == "" {
+			path = "kv.db"
+		}
+		return kv.NewRelationRepository(path)
+	default:
+		db, err := sql.InitDb()
+		if err != nil {
+			return nil, err
+		}
+		sqlRepo, err := sql.NewOrmRepository(db)
+		if err != nil {
+			return nil, err
+		}
+		if sqlRepo.RelationshipRepo.Replicas, err = sql.InitReplicas(db); err != nil {
+			return nil, err
+		}
+		sql.StartGarbageCollector(&sqlRepo.RelationshipRepo)
+		return &sqlRepo.RelationshipRepo, nil
+	}
+}
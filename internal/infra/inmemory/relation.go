@@ -0,0 +1,453 @@
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+// entity is the namespace and name of a node. Relations are indexed by
+// entity rather than by node, because queries may leave the relation empty
+// to match every relation of the entity.
+type entity struct {
+	Namespace string
+	Name      string
+}
+
+type relationSet map[domain.Relation]struct{}
+
+// stored is a relation with its id.
+type stored struct {
+	id       uint
+	relation domain.Relation
+}
+
+type store struct {
+	lock     sync.RWMutex
+	lastID   uint
+	revision uint64
+	// relations are keyed by their Key, the indexes hold them with their
+	// condition
+	relations map[domain.Relation]stored
+	// subject -> relations where it is the subject
+	forward map[entity]relationSet
+	// object -> relations where it is the object
+	reverse map[entity]relationSet
+	// schemas are the versions of the schema, the latest last
+	schemas []domain.Schema
+}
+
+type RelationRepository struct {
+	*store
+	// tx is set on the repository WithTransaction passes to its callback.
+	// The callback runs under the write lock, so such a repository does not
+	// lock, and records how to undo each change instead.
+	tx *transaction
+}
+
+type transaction struct {
+	undo []func()
+	// wrote is set once the transaction took its revision.
+	wrote bool
+}
+
+func NewRelationRepository() *RelationRepository {
+	return &RelationRepository{
+		store: &store{
+			relations: map[domain.Relation]stored{},
+			forward:   map[entity]relationSet{},
+			reverse:   map[entity]relationSet{},
+		},
+	}
+}
+
+func (r *RelationRepository) Create(ctx context.Context, relation domain.Relation) error {
+	defer r.lockForWrite()()
+	return r.create(relation)
+}
+
+func (r *RelationRepository) CreateIfNotExists(ctx context.Context, relation domain.Relation) error {
+	defer r.lockForWrite()()
+	if row, ok := r.relations[relation.Key()]; ok && !row.relation.Expired(time.Now()) {
+		return nil
+	}
+	return r.create(relation)
+}
+
+func (r *RelationRepository) Delete(ctx context.Context, relation domain.Relation) error {
+	defer r.lockForWrite()()
+	r.delete(relation)
+	return nil
+}
+
+func (r *RelationRepository) DeleteByQueries(ctx context.Context, queries []domain.Relation) error {
+	return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		for _, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			relations, err := tx.Query(ctx, query)
+			if err != nil {
+				return err
+			}
+			for _, relation := range relations {
+				if err := tx.Delete(ctx, relation); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RelationRepository) BatchOperation(ctx context.Context, operations []domain.Operation) error {
+	return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		for _, operation := range operations {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var err error
+			switch operation.Type {
+			case domain.CreateOperation:
+				err = tx.Create(ctx, operation.Relation)
+			case domain.CreateIfNotExistOperation:
+				err = tx.CreateIfNotExists(ctx, operation.Relation)
+			case domain.DeleteOperation:
+				err = tx.Delete(ctx, operation.Relation)
+			default:
+				err = errors.New("invalid operation type")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WithTransaction holds the write lock while fn runs, and undoes every
+// change fn made if it returns an error. Nested calls undo only their own
+// changes.
+func (r *RelationRepository) WithTransaction(ctx context.Context, fn func(tx sqldom.RelationRepository) error) error {
+	if r.tx != nil {
+		mark := len(r.tx.undo)
+		if err := fn(r); err != nil {
+			r.tx.rollback(mark)
+			return err
+		}
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	// the lock may have been waited for past the end of ctx
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tx := &RelationRepository{store: r.store, tx: &transaction{}}
+	if err := fn(tx); err != nil {
+		tx.tx.rollback(0)
+		return err
+	}
+	return nil
+}
+
+func (r *RelationRepository) GetAll(ctx context.Context, options ...sqldom.PageOptions) ([]domain.Relation, uint, error) {
+	defer r.lockForRead()()
+
+	var lastID uint
+	pageSize := -1
+	if len(options) > 0 {
+		lastID = options[0].LastID
+		pageSize = options[0].PageSize
+	}
+	rows := []stored{}
+	now := time.Now()
+	for _, row := range r.relations {
+		if row.id > lastID && !row.relation.Expired(now) {
+			rows = append(rows, row)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].id < rows[j].id })
+	if pageSize >= 0 && len(rows) > pageSize {
+		rows = rows[:pageSize]
+	}
+	if len(rows) == 0 {
+		return []domain.Relation{}, 0, nil
+	}
+
+	relations := make([]domain.Relation, len(rows))
+	for i, row := range rows {
+		relations[i] = row.relation
+	}
+	return relations, rows[len(rows)-1].id, nil
+}
+
+func (r *RelationRepository) Query(ctx context.Context, query domain.Relation) ([]domain.Relation, error) {
+	defer r.lockForRead()()
+	return r.query(query), nil
+}
+
+func (r *RelationRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	defer r.lockForRead()()
+
+	// a node with and without relation may match the same relation twice
+	found := relationSet{}
+	relations := []domain.Relation{}
+	for _, subject := range subjects {
+		for _, relation := range r.query(domain.Relation{
+			SubjectNamespace: subject.Namespace,
+			SubjectName:      subject.Name,
+			SubjectRelation:  subject.Relation,
+		}) {
+			if _, ok := found[relation]; !ok {
+				found[relation] = struct{}{}
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations, nil
+}
+
+func (r *RelationRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	defer r.lockForRead()()
+
+	// a node with and without relation may match the same relation twice
+	found := relationSet{}
+	relations := []domain.Relation{}
+	for _, object := range objects {
+		for _, relation := range r.query(domain.Relation{
+			ObjectNamespace: object.Namespace,
+			ObjectName:      object.Name,
+			Relation:        object.Relation,
+		}) {
+			if _, ok := found[relation]; !ok {
+				found[relation] = struct{}{}
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations, nil
+}
+
+func (r *RelationRepository) GetAllNamespaces(ctx context.Context) ([]string, error) {
+	defer r.lockForRead()()
+
+	namespaces := map[string]struct{}{}
+	for e := range r.forward {
+		namespaces[e.Namespace] = struct{}{}
+	}
+	for e := range r.reverse {
+		namespaces[e.Namespace] = struct{}{}
+	}
+	res := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		res = append(res, namespace)
+	}
+	return res, nil
+}
+
+func (r *RelationRepository) DeleteAll(ctx context.Context) error {
+	defer r.lockForWrite()()
+	relations, forward, reverse := r.relations, r.forward, r.reverse
+	r.relations = map[domain.Relation]stored{}
+	r.forward = map[entity]relationSet{}
+	r.reverse = map[entity]relationSet{}
+	r.onRollback(func() {
+		r.relations, r.forward, r.reverse = relations, forward, reverse
+	})
+	return nil
+}
+
+func (r *RelationRepository) Revision(ctx context.Context) (uint64, error) {
+	defer r.lockForRead()()
+	return r.revision, nil
+}
+
+func (r *RelationRepository) ReadSchema(ctx context.Context) (domain.Schema, error) {
+	defer r.lockForRead()()
+	if len(r.schemas) == 0 {
+		return domain.Schema{Namespaces: []domain.NamespaceDefinition{}}, nil
+	}
+	return r.schemas[len(r.schemas)-1], nil
+}
+
+// WriteSchema does not advance the revision, the schema is versioned on its
+// own.
+func (r *RelationRepository) WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error) {
+	if r.tx == nil {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+	}
+	schema := domain.Schema{
+		Version:    uint64(len(r.schemas)) + 1,
+		Namespaces: append([]domain.NamespaceDefinition{}, namespaces...),
+	}
+	r.schemas = append(r.schemas, schema)
+	r.onRollback(func() { r.schemas = r.schemas[:len(r.schemas)-1] })
+	return schema, nil
+}
+
+// lockForWrite also advances the revision, once per transaction.
+func (r *RelationRepository) lockForWrite() (unlock func()) {
+	if r.tx != nil {
+		if !r.tx.wrote {
+			r.tx.wrote = true
+			r.revision++
+			r.onRollback(func() {
+				r.revision--
+				r.tx.wrote = false
+			})
+		}
+		return func() {}
+	}
+	r.lock.Lock()
+	r.revision++
+	return r.lock.Unlock
+}
+
+func (r *RelationRepository) lockForRead() (unlock func()) {
+	if r.tx != nil {
+		return func() {}
+	}
+	r.lock.RLock()
+	return r.lock.RUnlock
+}
+
+// onRollback records how to undo a change made in a transaction.
+func (r *RelationRepository) onRollback(undo func()) {
+	if r.tx != nil {
+		r.tx.undo = append(r.tx.undo, undo)
+	}
+}
+
+// rollback undoes the changes recorded after the first mark ones. Undoing
+// records changes itself, those are dropped as well.
+func (t *transaction) rollback(mark int) {
+	undo := append([]func(){}, t.undo[mark:]...)
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i]()
+	}
+	t.undo = t.undo[:mark]
+}
+
+// create replaces an expired relation.
+func (r *RelationRepository) create(relation domain.Relation) error {
+	if row, ok := r.relations[relation.Key()]; ok {
+		if !row.relation.Expired(time.Now()) {
+			return domain.AlreadyExistsError{}
+		}
+		r.delete(row.relation)
+	}
+	if relation.ExpiresAt != nil {
+		expiresAt := relation.ExpiresAt.UTC()
+		relation.ExpiresAt = &expiresAt
+	}
+	r.lastID++
+	r.insert(relation, r.lastID)
+	r.onRollback(func() { r.delete(relation) })
+	return nil
+}
+
+func (r *RelationRepository) insert(relation domain.Relation, id uint) {
+	r.relations[relation.Key()] = stored{id: id, relation: relation}
+	addToIndex(r.forward, subjectOf(relation), relation)
+	addToIndex(r.reverse, objectOf(relation), relation)
+}
+
+func (r *RelationRepository) delete(relation domain.Relation) {
+	row, ok := r.relations[relation.Key()]
+	if !ok {
+		return
+	}
+	relation = row.relation
+	delete(r.relations, relation.Key())
+	removeFromIndex(r.forward, subjectOf(relation), relation)
+	removeFromIndex(r.reverse, objectOf(relation), relation)
+	r.onRollback(func() { r.insert(relation, row.id) })
+}
+
+// QueryExpired scans all relations, the store keeps no index of their
+// expiry.
+func (r *RelationRepository) QueryExpired(ctx context.Context, now time.Time, limit int) ([]domain.Relation, error) {
+	defer r.lockForRead()()
+	expired := []domain.Relation{}
+	for _, row := range r.relations {
+		if row.relation.Expired(now) {
+			expired = append(expired, row.relation)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(*expired[j].ExpiresAt) })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+// query follows the gorm semantics of the sql repository: empty fields in
+// the query match anything. Expired relations match nothing.
+func (r *RelationRepository) query(query domain.Relation) []domain.Relation {
+	var candidates relationSet
+	switch {
+	case query.SubjectNamespace != "" && query.SubjectName != "":
+		candidates = r.forward[subjectOf(query)]
+	case query.ObjectNamespace != "" && query.ObjectName != "":
+		candidates = r.reverse[objectOf(query)]
+	default:
+		candidates = make(relationSet, len(r.relations))
+		for _, row := range r.relations {
+			candidates[row.relation] = struct{}{}
+		}
+	}
+
+	relations := []domain.Relation{}
+	now := time.Now()
+	for relation := range candidates {
+		if match(query, relation) && !relation.Expired(now) {
+			relations = append(relations, relation)
+		}
+	}
+	return relations
+}
+
+func match(query, relation domain.Relation) bool {
+	return (query.ObjectNamespace == "" || query.ObjectNamespace == relation.ObjectNamespace) &&
+		(query.ObjectName == "" || query.ObjectName == relation.ObjectName) &&
+		(query.Relation == "" || query.Relation == relation.Relation) &&
+		(query.SubjectNamespace == "" || query.SubjectNamespace == relation.SubjectNamespace) &&
+		(query.SubjectName == "" || query.SubjectName == relation.SubjectName) &&
+		(query.SubjectRelation == "" || query.SubjectRelation == relation.SubjectRelation)
+}
+
+func subjectOf(relation domain.Relation) entity {
+	return entity{Namespace: relation.SubjectNamespace, Name: relation.SubjectName}
+}
+
+func objectOf(relation domain.Relation) entity {
+	return entity{Namespace: relation.ObjectNamespace, Name: relation.ObjectName}
+}
+
+func addToIndex(index map[entity]relationSet, key entity, relation domain.Relation) {
+	relations, ok := index[key]
+	if !ok {
+		relations = relationSet{}
+		index[key] = relations
+	}
+	relations[relation] = struct{}{}
+}
+
+func removeFromIndex(index map[entity]relationSet, key entity, relation domain.Relation) {
+	relations, ok := index[key]
+	if !ok {
+		return
+	}
+	delete(relations, relation)
+	if len(relations) == 0 {
+		delete(index, key)
+	}
+}
@@ -0,0 +1,116 @@
+package inmemory_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/inmemory"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/repotest"
+)
+
+func TestRelationRepository(t *testing.T) {
+	repotest.TestRelationRepository(t, func(t *testing.T) sqldom.RelationRepository {
+		return inmemory.NewRelationRepository()
+	})
+}
+
+func TestQueryUsesBothIndexes(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRelationRepository()
+	viewer := domain.Relation{
+		ObjectNamespace:  "doc",
+		ObjectName:       "1",
+		Relation:         "viewer",
+		SubjectNamespace: "group",
+		SubjectName:      "eng",
+		SubjectRelation:  "member",
+	}
+	member := domain.Relation{
+		ObjectNamespace:  "group",
+		ObjectName:       "eng",
+		Relation:         "member",
+		SubjectNamespace: "user",
+		SubjectName:      "alice",
+	}
+	for _, relation := range []domain.Relation{viewer, member} {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	bySubject, _ := repo.Query(ctx, domain.Relation{SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"})
+	if len(bySubject) != 1 || bySubject[0] != viewer {
+		t.Errorf("query by subject = %v, want [%v]", bySubject, viewer)
+	}
+	byObject, _ := repo.Query(ctx, domain.Relation{ObjectNamespace: "group", ObjectName: "eng"})
+	if len(byObject) != 1 || byObject[0] != member {
+		t.Errorf("query by object = %v, want [%v]", byObject, member)
+	}
+	byRelation, _ := repo.Query(ctx, domain.Relation{Relation: "viewer"})
+	if len(byRelation) != 1 || byRelation[0] != viewer {
+		t.Errorf("query by relation = %v, want [%v]", byRelation, viewer)
+	}
+}
+
+func TestBatchOperationIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRelationRepository()
+	existing := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"}
+	if err := repo.Create(ctx, existing); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	added := existing
+	added.SubjectName = "bob"
+	err := repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateOperation, Relation: added},
+		{Type: domain.DeleteOperation, Relation: existing},
+		// fails on the duplicate and must roll back both operations above
+		{Type: domain.CreateOperation, Relation: added},
+	})
+	if err == nil {
+		t.Fatal("BatchOperation: expected duplicate error")
+	}
+
+	relations, _, _ := repo.GetAll(ctx)
+	if len(relations) != 1 || relations[0] != existing {
+		t.Errorf("GetAll = %v, want only %v", relations, existing)
+	}
+}
+
+func TestConcurrentReadersAndWriters(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRelationRepository()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				relation := domain.Relation{
+					ObjectNamespace:  "doc",
+					ObjectName:       fmt.Sprint(j),
+					Relation:         "viewer",
+					SubjectNamespace: "user",
+					SubjectName:      fmt.Sprint(i),
+				}
+				if err := repo.Create(ctx, relation); err != nil {
+					t.Errorf("Create: %v", err)
+				}
+				if _, err := repo.Query(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: fmt.Sprint(j)}); err != nil {
+					t.Errorf("Query: %v", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	relations, _, _ := repo.GetAll(ctx)
+	if len(relations) != 800 {
+		t.Errorf("GetAll returned %d relations, want 800", len(relations))
+	}
+}
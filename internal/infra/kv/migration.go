@@ -0,0 +1,63 @@
+package kv
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	metaBucket = []byte("meta")
+	versionKey = []byte("schema_version")
+	// revisionKey holds the revision of the last write transaction.
+	revisionKey = []byte("revision")
+)
+
+// migrations is the migration set of the key-value layout. The version of a
+// store is the number of them it has applied, recorded in the meta bucket.
+var migrations = []func(tx *bolt.Tx) error{
+	func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{relationsBucket, objectBucket, subjectBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(schemasBucket)
+		return err
+	},
+	func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(expiryBucket)
+		return err
+	},
+}
+
+// migrate applies the pending migrations in one transaction, and refuses a
+// store written by a binary with a newer layout.
+func migrate(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		var version uint64
+		if v := meta.Get(versionKey); v != nil {
+			version = binary.BigEndian.Uint64(v)
+		}
+		if version > uint64(len(migrations)) {
+			return fmt.Errorf(
+				"store schema version %d is newer than the %d this binary supports",
+				version, len(migrations),
+			)
+		}
+		for _, migration := range migrations[version:] {
+			if err := migration(tx); err != nil {
+				return err
+			}
+		}
+		return meta.Put(versionKey, binary.BigEndian.AppendUint64(nil, uint64(len(migrations))))
+	})
+}
@@ -0,0 +1,670 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Relations are stored in three buckets, and indexed by expiry in a fourth:
+//
+//	relations: id -> object key
+//	object:    object namespace, name, relation, subject namespace, name, relation -> id
+//	subject:   subject namespace, name, relation, object namespace, name, relation -> id
+//	expiry:    expiry, id -> object key
+//
+// Every key component is length prefixed, so the keys of one entity or node
+// share a prefix and a query by either side is a range scan. The object key
+// holds the fields identifying the relation, so it doubles as the unique
+// key. The condition of a relation follows the value in the first three
+// buckets, as two more length prefixed strings, and is followed by its
+// expiry if it has one. An expiry is its Unix seconds with the sign bit
+// flipped and its nanoseconds, big endian so expiry keys sort by time.
+var (
+	relationsBucket = []byte("relations")
+	objectBucket    = []byte("object")
+	subjectBucket   = []byte("subject")
+	expiryBucket    = []byte("expiry")
+)
+
+type RelationRepository struct {
+	DB *bolt.DB
+	// tx is set on the repository WithTransaction passes to its callback.
+	tx *bolt.Tx
+	// revised is set once tx advanced the revision.
+	revised *bool
+}
+
+// NewRelationRepository opens the store at path, creating it if needed, and
+// brings its layout up to date.
+func NewRelationRepository(path string) (*RelationRepository, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &RelationRepository{DB: db}, nil
+}
+
+func (r *RelationRepository) Close() error {
+	return r.DB.Close()
+}
+
+func (r *RelationRepository) Create(ctx context.Context, relation domain.Relation) error {
+	return r.update(ctx, func(tx *bolt.Tx) error {
+		return create(tx, relation)
+	})
+}
+
+func (r *RelationRepository) CreateIfNotExists(ctx context.Context, relation domain.Relation) error {
+	return r.update(ctx, func(tx *bolt.Tx) error {
+		if err := create(tx, relation); err != nil && !errors.Is(err, domain.AlreadyExistsError{}) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (r *RelationRepository) Delete(ctx context.Context, relation domain.Relation) error {
+	return r.update(ctx, func(tx *bolt.Tx) error {
+		return remove(tx, relation)
+	})
+}
+
+func (r *RelationRepository) DeleteByQueries(ctx context.Context, queries []domain.Relation) error {
+	return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		for _, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			relations, err := tx.Query(ctx, query)
+			if err != nil {
+				return err
+			}
+			for _, relation := range relations {
+				if err := tx.Delete(ctx, relation); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RelationRepository) BatchOperation(ctx context.Context, operations []domain.Operation) error {
+	return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		for _, operation := range operations {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var err error
+			switch operation.Type {
+			case domain.CreateOperation:
+				err = tx.Create(ctx, operation.Relation)
+			case domain.CreateIfNotExistOperation:
+				err = tx.CreateIfNotExists(ctx, operation.Relation)
+			case domain.DeleteOperation:
+				err = tx.Delete(ctx, operation.Relation)
+			default:
+				err = errors.New("invalid operation type")
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WithTransaction runs fn in one bolt write transaction, which already
+// excludes every other writer. bolt has no savepoints, so a nested call joins
+// the outer transaction and its changes are only rolled back if the error
+// reaches the outermost call.
+func (r *RelationRepository) WithTransaction(ctx context.Context, fn func(tx sqldom.RelationRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+	return r.DB.Update(func(tx *bolt.Tx) error {
+		// the write lock may have been waited for past the end of ctx
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(&RelationRepository{DB: r.DB, tx: tx, revised: new(bool)})
+	})
+}
+
+func (r *RelationRepository) GetAll(ctx context.Context, options ...sqldom.PageOptions) ([]domain.Relation, uint, error) {
+	var lastID uint
+	pageSize := -1
+	if len(options) > 0 {
+		lastID = options[0].LastID
+		pageSize = options[0].PageSize
+	}
+
+	relations := []domain.Relation{}
+	var last uint
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		c := tx.Bucket(relationsBucket).Cursor()
+		now := time.Now()
+		for k, v := c.Seek(idKey(uint64(lastID) + 1)); k != nil; k, v = c.Next() {
+			if pageSize >= 0 && len(relations) >= pageSize {
+				break
+			}
+			relation, err := decodeRelation(v)
+			if err != nil {
+				return err
+			}
+			if relation.Expired(now) {
+				continue
+			}
+			relations = append(relations, relation)
+			last = uint(binary.BigEndian.Uint64(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return relations, last, nil
+}
+
+func (r *RelationRepository) Query(ctx context.Context, query domain.Relation) ([]domain.Relation, error) {
+	relations := []domain.Relation{}
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		var err error
+		relations, err = queryTx(tx, query, relations)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return relations, nil
+}
+
+func (r *RelationRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	queries := make([]domain.Relation, len(subjects))
+	for i, subject := range subjects {
+		queries[i] = domain.Relation{
+			SubjectNamespace: subject.Namespace,
+			SubjectName:      subject.Name,
+			SubjectRelation:  subject.Relation,
+		}
+	}
+	return r.queryAll(ctx, queries)
+}
+
+func (r *RelationRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	queries := make([]domain.Relation, len(objects))
+	for i, object := range objects {
+		queries[i] = domain.Relation{
+			ObjectNamespace: object.Namespace,
+			ObjectName:      object.Name,
+			Relation:        object.Relation,
+		}
+	}
+	return r.queryAll(ctx, queries)
+}
+
+// GetAllNamespaces reads the first key of each namespace in both indexes and
+// then seeks past the namespace, so it costs one seek per namespace rather
+// than a scan of every relation.
+func (r *RelationRepository) GetAllNamespaces(ctx context.Context) ([]string, error) {
+	namespaces := map[string]struct{}{}
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{objectBucket, subjectBucket} {
+			c := tx.Bucket(bucket).Cursor()
+			for k, _ := c.First(); k != nil; {
+				namespace, _, err := readString(k)
+				if err != nil {
+					return err
+				}
+				namespaces[namespace] = struct{}{}
+				next := prefixEnd(appendString(nil, namespace))
+				if next == nil {
+					break
+				}
+				k, _ = c.Seek(next)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(namespaces))
+	for namespace := range namespaces {
+		res = append(res, namespace)
+	}
+	return res, nil
+}
+
+func (r *RelationRepository) DeleteAll(ctx context.Context) error {
+	return r.update(ctx, func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{relationsBucket, objectBucket, subjectBucket, expiryBucket} {
+			if err := tx.DeleteBucket(bucket); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *RelationRepository) Revision(ctx context.Context) (uint64, error) {
+	var revision uint64
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		if v := tx.Bucket(metaBucket).Get(revisionKey); v != nil {
+			revision = binary.BigEndian.Uint64(v)
+		}
+		return nil
+	})
+	return revision, err
+}
+
+// update runs fn in a write transaction and advances the revision, once per
+// transaction. bolt transactions can not be interrupted, so ctx is only
+// checked before fn.
+func (r *RelationRepository) update(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.tx != nil {
+		if err := fn(r.tx); err != nil {
+			return err
+		}
+		if *r.revised {
+			return nil
+		}
+		*r.revised = true
+		return advanceRevision(r.tx)
+	}
+	return r.DB.Update(func(tx *bolt.Tx) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return advanceRevision(tx)
+	})
+}
+
+func advanceRevision(tx *bolt.Tx) error {
+	meta := tx.Bucket(metaBucket)
+	var revision uint64
+	if v := meta.Get(revisionKey); v != nil {
+		revision = binary.BigEndian.Uint64(v)
+	}
+	return meta.Put(revisionKey, binary.BigEndian.AppendUint64(nil, revision+1))
+}
+
+func (r *RelationRepository) view(ctx context.Context, fn func(tx *bolt.Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+	return r.DB.View(fn)
+}
+
+// queryAll runs every query in one read transaction. A node with and
+// without relation may match the same relation twice, so results are
+// deduplicated.
+func (r *RelationRepository) queryAll(ctx context.Context, queries []domain.Relation) ([]domain.Relation, error) {
+	relations := []domain.Relation{}
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		found := map[domain.Relation]struct{}{}
+		for _, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			matched, err := queryTx(tx, query, nil)
+			if err != nil {
+				return err
+			}
+			for _, relation := range matched {
+				if _, ok := found[relation.Key()]; !ok {
+					found[relation.Key()] = struct{}{}
+					relations = append(relations, relation)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return relations, nil
+}
+
+// create replaces an expired relation.
+func create(tx *bolt.Tx, relation domain.Relation) error {
+	objects := tx.Bucket(objectBucket)
+	key := objectKey(relation)
+	if indexed := objects.Get(key); indexed != nil {
+		stored, err := decodeAttributes(relation, indexed[8:])
+		if err != nil {
+			return err
+		}
+		if !stored.Expired(time.Now()) {
+			return domain.AlreadyExistsError{}
+		}
+		if err := remove(tx, relation); err != nil {
+			return err
+		}
+	}
+	relations := tx.Bucket(relationsBucket)
+	seq, err := relations.NextSequence()
+	if err != nil {
+		return err
+	}
+	id := idKey(seq)
+	attributes := attributesValue(relation)
+	if err := relations.Put(id, append(key, attributes...)); err != nil {
+		return err
+	}
+	indexed := append(id, attributes...)
+	if err := objects.Put(key, indexed); err != nil {
+		return err
+	}
+	if err := tx.Bucket(subjectBucket).Put(subjectKey(relation), indexed); err != nil {
+		return err
+	}
+	if relation.ExpiresAt == nil {
+		return nil
+	}
+	return tx.Bucket(expiryBucket).Put(expiryKey(*relation.ExpiresAt, id), key)
+}
+
+func remove(tx *bolt.Tx, relation domain.Relation) error {
+	objects := tx.Bucket(objectBucket)
+	key := objectKey(relation)
+	indexed := objects.Get(key)
+	if indexed == nil {
+		return nil
+	}
+	id := indexed[:8]
+	stored, err := decodeAttributes(relation, indexed[8:])
+	if err != nil {
+		return err
+	}
+	if stored.ExpiresAt != nil {
+		if err := tx.Bucket(expiryBucket).Delete(expiryKey(*stored.ExpiresAt, id)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Bucket(relationsBucket).Delete(id); err != nil {
+		return err
+	}
+	if err := objects.Delete(key); err != nil {
+		return err
+	}
+	return tx.Bucket(subjectBucket).Delete(subjectKey(relation))
+}
+
+// QueryExpired scans the expiry bucket up to now.
+func (r *RelationRepository) QueryExpired(ctx context.Context, now time.Time, limit int) ([]domain.Relation, error) {
+	expired := []domain.Relation{}
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		objects := tx.Bucket(objectBucket)
+		c := tx.Bucket(expiryBucket).Cursor()
+		for k, v := c.First(); k != nil && len(expired) < limit; k, v = c.Next() {
+			if expiresAt := decodeTime(k); expiresAt.After(now) {
+				break
+			}
+			relation, err := decodeObjectKey(v)
+			if err != nil {
+				return err
+			}
+			indexed := objects.Get(v)
+			if indexed == nil {
+				return fmt.Errorf("expiry of a missing relation: %x", k)
+			}
+			if relation, err = decodeAttributes(relation, indexed[8:]); err != nil {
+				return err
+			}
+			expired = append(expired, relation)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// queryTx appends the relations matching query to relations. Like the gorm
+// queries of the sql repository, empty fields match anything, and expired
+// relations nothing. A query naming
+// the subject or object entity scans the range of that entity in its index,
+// anything else scans all relations.
+func queryTx(tx *bolt.Tx, query domain.Relation, relations []domain.Relation) ([]domain.Relation, error) {
+	var c *bolt.Cursor
+	var prefix []byte
+	decode := decodeObjectKey
+	now := time.Now()
+	switch {
+	case query.SubjectNamespace != "" && query.SubjectName != "":
+		c = tx.Bucket(subjectBucket).Cursor()
+		prefix = appendString(appendString(nil, query.SubjectNamespace), query.SubjectName)
+		if query.SubjectRelation != "" {
+			prefix = appendString(prefix, query.SubjectRelation)
+		}
+		decode = decodeSubjectKey
+	case query.ObjectNamespace != "" && query.ObjectName != "":
+		c = tx.Bucket(objectBucket).Cursor()
+		prefix = appendString(appendString(nil, query.ObjectNamespace), query.ObjectName)
+		if query.Relation != "" {
+			prefix = appendString(prefix, query.Relation)
+		}
+	default:
+		c = tx.Bucket(relationsBucket).Cursor()
+		for _, v := c.First(); v != nil; _, v = c.Next() {
+			relation, err := decodeRelation(v)
+			if err != nil {
+				return nil, err
+			}
+			if match(query, relation) && !relation.Expired(now) {
+				relations = append(relations, relation)
+			}
+		}
+		return relations, nil
+	}
+
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		relation, err := decode(k)
+		if err != nil {
+			return nil, err
+		}
+		if relation, err = decodeAttributes(relation, v[8:]); err != nil {
+			return nil, err
+		}
+		if match(query, relation) && !relation.Expired(now) {
+			relations = append(relations, relation)
+		}
+	}
+	return relations, nil
+}
+
+func match(query, relation domain.Relation) bool {
+	return (query.ObjectNamespace == "" || query.ObjectNamespace == relation.ObjectNamespace) &&
+		(query.ObjectName == "" || query.ObjectName == relation.ObjectName) &&
+		(query.Relation == "" || query.Relation == relation.Relation) &&
+		(query.SubjectNamespace == "" || query.SubjectNamespace == relation.SubjectNamespace) &&
+		(query.SubjectName == "" || query.SubjectName == relation.SubjectName) &&
+		(query.SubjectRelation == "" || query.SubjectRelation == relation.SubjectRelation)
+}
+
+func idKey(id uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, id)
+}
+
+func objectKey(relation domain.Relation) []byte {
+	return appendStrings(nil,
+		relation.ObjectNamespace, relation.ObjectName, relation.Relation,
+		relation.SubjectNamespace, relation.SubjectName, relation.SubjectRelation,
+	)
+}
+
+func subjectKey(relation domain.Relation) []byte {
+	return appendStrings(nil,
+		relation.SubjectNamespace, relation.SubjectName, relation.SubjectRelation,
+		relation.ObjectNamespace, relation.ObjectName, relation.Relation,
+	)
+}
+
+func decodeObjectKey(key []byte) (domain.Relation, error) {
+	fields, err := readStrings(key, 6)
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	return domain.Relation{
+		ObjectNamespace:  fields[0],
+		ObjectName:       fields[1],
+		Relation:         fields[2],
+		SubjectNamespace: fields[3],
+		SubjectName:      fields[4],
+		SubjectRelation:  fields[5],
+	}, nil
+}
+
+// decodeRelation decodes a value of the relations bucket.
+func decodeRelation(value []byte) (domain.Relation, error) {
+	fields, err := readStrings(value, 6)
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	relation := domain.Relation{
+		ObjectNamespace:  fields[0],
+		ObjectName:       fields[1],
+		Relation:         fields[2],
+		SubjectNamespace: fields[3],
+		SubjectName:      fields[4],
+		SubjectRelation:  fields[5],
+	}
+	return decodeAttributes(relation, value[len(objectKey(relation)):])
+}
+
+// attributesValue encodes the condition and expiry of relation, nothing if
+// it has neither.
+func attributesValue(relation domain.Relation) []byte {
+	if relation.Condition == "" && relation.ExpiresAt == nil {
+		return nil
+	}
+	value := appendStrings(nil, relation.Condition, string(relation.ConditionParams))
+	if relation.ExpiresAt == nil {
+		return value
+	}
+	return appendTime(value, *relation.ExpiresAt)
+}
+
+// decodeAttributes sets the condition and expiry of relation from value,
+// the rest of a value after the id or object key.
+func decodeAttributes(relation domain.Relation, value []byte) (domain.Relation, error) {
+	if len(value) == 0 {
+		return relation, nil
+	}
+	condition, value, err := readString(value)
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	params, value, err := readString(value)
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	relation.Condition, relation.ConditionParams = condition, domain.ConditionParams(params)
+	if len(value) >= timeLength {
+		expiresAt := decodeTime(value)
+		relation.ExpiresAt = &expiresAt
+	}
+	return relation, nil
+}
+
+func expiryKey(expiresAt time.Time, id []byte) []byte {
+	return append(appendTime(nil, expiresAt), id...)
+}
+
+const timeLength = 12
+
+func appendTime(value []byte, t time.Time) []byte {
+	value = binary.BigEndian.AppendUint64(value, uint64(t.Unix())^1<<63)
+	return binary.BigEndian.AppendUint32(value, uint32(t.Nanosecond()))
+}
+
+func decodeTime(value []byte) time.Time {
+	seconds := int64(binary.BigEndian.Uint64(value) ^ 1<<63)
+	return time.Unix(seconds, int64(binary.BigEndian.Uint32(value[8:]))).UTC()
+}
+
+func decodeSubjectKey(key []byte) (domain.Relation, error) {
+	fields, err := readStrings(key, 6)
+	if err != nil {
+		return domain.Relation{}, err
+	}
+	return domain.Relation{
+		SubjectNamespace: fields[0],
+		SubjectName:      fields[1],
+		SubjectRelation:  fields[2],
+		ObjectNamespace:  fields[3],
+		ObjectName:       fields[4],
+		Relation:         fields[5],
+	}, nil
+}
+
+func appendString(key []byte, s string) []byte {
+	key = binary.AppendUvarint(key, uint64(len(s)))
+	return append(key, s...)
+}
+
+func appendStrings(key []byte, strs ...string) []byte {
+	for _, s := range strs {
+		key = appendString(key, s)
+	}
+	return key
+}
+
+func readString(key []byte) (string, []byte, error) {
+	length, n := binary.Uvarint(key)
+	if n <= 0 || uint64(len(key)-n) < length {
+		return "", nil, fmt.Errorf("corrupted key: %x", key)
+	}
+	end := n + int(length)
+	return string(key[n:end]), key[end:], nil
+}
+
+func readStrings(key []byte, count int) ([]string, error) {
+	strs := make([]string, count)
+	for i := range strs {
+		var err error
+		if strs[i], key, err = readString(key); err != nil {
+			return nil, err
+		}
+	}
+	return strs, nil
+}
+
+// prefixEnd returns the first key after every key starting with prefix, or
+// nil if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
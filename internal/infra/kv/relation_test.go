@@ -0,0 +1,44 @@
+package kv_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/kv"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/repotest"
+)
+
+func newRepo(t *testing.T, path string) *kv.RelationRepository {
+	repo, err := kv.NewRelationRepository(path)
+	if err != nil {
+		t.Fatalf("NewRelationRepository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func TestRelationRepository(t *testing.T) {
+	repotest.TestRelationRepository(t, func(t *testing.T) sqldom.RelationRepository {
+		return newRepo(t, filepath.Join(t.TempDir(), "kv.db"))
+	})
+}
+
+func TestReopen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "kv.db")
+	repo := newRepo(t, path)
+	rel := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"}
+	if err := repo.Create(ctx, rel); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	repo.Close()
+
+	repo = newRepo(t, path)
+	relations, _ := repo.Query(ctx, rel)
+	if len(relations) != 1 || relations[0] != rel {
+		t.Errorf("Query after reopen = %v, want [%v]", relations, rel)
+	}
+}
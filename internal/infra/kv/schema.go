@@ -0,0 +1,57 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	bolt "go.etcd.io/bbolt"
+)
+
+// schemasBucket maps each version of the schema to its namespaces as JSON.
+var schemasBucket = []byte("schemas")
+
+func (r *RelationRepository) ReadSchema(ctx context.Context) (domain.Schema, error) {
+	schema := domain.Schema{Namespaces: []domain.NamespaceDefinition{}}
+	err := r.view(ctx, func(tx *bolt.Tx) error {
+		k, v := tx.Bucket(schemasBucket).Cursor().Last()
+		if k == nil {
+			return nil
+		}
+		schema.Version = binary.BigEndian.Uint64(k)
+		return json.Unmarshal(v, &schema.Namespaces)
+	})
+	return schema, err
+}
+
+// WriteSchema does not advance the revision, the schema is versioned on its
+// own.
+func (r *RelationRepository) WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Schema{}, err
+	}
+	schema := domain.Schema{Namespaces: namespaces}
+	write := func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(schemasBucket)
+		if k, _ := bucket.Cursor().Last(); k != nil {
+			schema.Version = binary.BigEndian.Uint64(k)
+		}
+		schema.Version++
+		v, err := json.Marshal(namespaces)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(binary.BigEndian.AppendUint64(nil, schema.Version), v)
+	}
+	var err error
+	if r.tx != nil {
+		err = write(r.tx)
+	} else {
+		err = r.DB.Update(write)
+	}
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	return schema, nil
+}
@@ -0,0 +1,478 @@
+// Package repotest holds the behavior every RelationRepository backend has to
+// share, so each backend runs the same tests against its own store.
+package repotest
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+// TestRelationRepository runs the shared behavior tests. newRepo must return
+// an empty repository.
+func TestRelationRepository(t *testing.T, newRepo func(t *testing.T) sqldom.RelationRepository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo sqldom.RelationRepository)
+	}{
+		{"CreateAndQuery", testCreateAndQuery},
+		{"UniqueRelations", testUniqueRelations},
+		{"QueryByNodes", testQueryByNodes},
+		{"GetAllPages", testGetAllPages},
+		{"GetAllNamespaces", testGetAllNamespaces},
+		{"DeleteByQueries", testDeleteByQueries},
+		{"DeleteAll", testDeleteAll},
+		{"BatchOperationIsAtomic", testBatchOperationIsAtomic},
+		{"WithTransactionRollsBack", testWithTransactionRollsBack},
+		{"RecreateDeleted", testRecreateDeleted},
+		{"Revisions", testRevisions},
+		{"SchemaVersions", testSchemaVersions},
+		{"Conditions", testConditions},
+		{"Expiry", testExpiry},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+var (
+	viewer = domain.Relation{
+		ObjectNamespace:  "doc",
+		ObjectName:       "1",
+		Relation:         "viewer",
+		SubjectNamespace: "group",
+		SubjectName:      "eng",
+		SubjectRelation:  "member",
+	}
+	member = domain.Relation{
+		ObjectNamespace:  "group",
+		ObjectName:       "eng",
+		Relation:         "member",
+		SubjectNamespace: "user",
+		SubjectName:      "alice",
+	}
+	owner = domain.Relation{
+		ObjectNamespace:  "doc",
+		ObjectName:       "1",
+		Relation:         "owner",
+		SubjectNamespace: "user",
+		SubjectName:      "alice",
+	}
+)
+
+func create(t *testing.T, repo sqldom.RelationRepository, relations ...domain.Relation) {
+	t.Helper()
+	ctx := context.Background()
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create(%v): %v", relation, err)
+		}
+	}
+}
+
+func expect(t *testing.T, name string, got []domain.Relation, want ...domain.Relation) {
+	t.Helper()
+	sortRelations(got)
+	sortRelations(want)
+	if len(got) != len(want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+		return
+	}
+	for i := range got {
+		// the expiries are pointers, equal ones may differ in address
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+			return
+		}
+	}
+}
+
+func sortRelations(relations []domain.Relation) {
+	sort.Slice(relations, func(i, j int) bool {
+		return key(relations[i]) < key(relations[j])
+	})
+}
+
+func key(r domain.Relation) string {
+	return r.ObjectNamespace + "%" + r.ObjectName + "%" + r.Relation + "%" +
+		r.SubjectNamespace + "%" + r.SubjectName + "%" + r.SubjectRelation
+}
+
+func testCreateAndQuery(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member, owner)
+
+	got, err := repo.Query(ctx, domain.Relation{SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	expect(t, "query by subject", got, viewer)
+
+	got, _ = repo.Query(ctx, domain.Relation{SubjectNamespace: "user", SubjectName: "alice"})
+	expect(t, "query by subject entity", got, member, owner)
+
+	got, _ = repo.Query(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "1"})
+	expect(t, "query by object entity", got, viewer, owner)
+
+	got, _ = repo.Query(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "owner"})
+	expect(t, "query by object", got, owner)
+
+	got, _ = repo.Query(ctx, domain.Relation{Relation: "viewer"})
+	expect(t, "query by relation", got, viewer)
+
+	got, _ = repo.Query(ctx, domain.Relation{SubjectNamespace: "user", SubjectName: "bob"})
+	expect(t, "query without match", got)
+}
+
+func testUniqueRelations(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer)
+
+	if err := repo.Create(ctx, viewer); err != (domain.AlreadyExistsError{}) {
+		t.Errorf("Create duplicate = %v, want AlreadyExistsError", err)
+	}
+	if err := repo.CreateIfNotExists(ctx, viewer); err != nil {
+		t.Errorf("CreateIfNotExists duplicate: %v", err)
+	}
+	if err := repo.CreateIfNotExists(ctx, member); err != nil {
+		t.Errorf("CreateIfNotExists: %v", err)
+	}
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll", got, viewer, member)
+}
+
+func testQueryByNodes(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member, owner)
+
+	got, err := repo.QueryBySubjects(ctx, []domain.Node{
+		{Namespace: "group", Name: "eng", Relation: "member"},
+		// an empty relation matches every relation of the entity, and
+		// overlapping nodes must not return a relation twice
+		{Namespace: "user", Name: "alice"},
+		{Namespace: "user", Name: "alice", Relation: ""},
+	})
+	if err != nil {
+		t.Fatalf("QueryBySubjects: %v", err)
+	}
+	expect(t, "QueryBySubjects", got, viewer, member, owner)
+
+	got, err = repo.QueryByObjects(ctx, []domain.Node{
+		{Namespace: "doc", Name: "1", Relation: "owner"},
+		{Namespace: "group", Name: "eng", Relation: "member"},
+	})
+	if err != nil {
+		t.Fatalf("QueryByObjects: %v", err)
+	}
+	expect(t, "QueryByObjects", got, member, owner)
+
+	got, _ = repo.QueryBySubjects(ctx, nil)
+	expect(t, "QueryBySubjects without nodes", got)
+}
+
+func testGetAllPages(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member, owner)
+
+	first, lastID, err := repo.GetAll(ctx, sqldom.PageOptions{PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	expect(t, "first page", first, viewer, member)
+
+	second, lastID, err := repo.GetAll(ctx, sqldom.PageOptions{LastID: lastID, PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	expect(t, "second page", second, owner)
+
+	third, _, _ := repo.GetAll(ctx, sqldom.PageOptions{LastID: lastID, PageSize: 2})
+	expect(t, "third page", third)
+}
+
+func testGetAllNamespaces(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member, owner)
+
+	namespaces, err := repo.GetAllNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNamespaces: %v", err)
+	}
+	sort.Strings(namespaces)
+	want := []string{"doc", "group", "user"}
+	if len(namespaces) != len(want) {
+		t.Fatalf("GetAllNamespaces = %v, want %v", namespaces, want)
+	}
+	for i := range want {
+		if namespaces[i] != want[i] {
+			t.Fatalf("GetAllNamespaces = %v, want %v", namespaces, want)
+		}
+	}
+}
+
+func testDeleteByQueries(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member, owner)
+
+	if err := repo.Delete(ctx, member); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.Delete(ctx, member); err != nil {
+		t.Errorf("Delete missing relation: %v", err)
+	}
+	err := repo.DeleteByQueries(ctx, []domain.Relation{
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "owner"},
+	})
+	if err != nil {
+		t.Fatalf("DeleteByQueries: %v", err)
+	}
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll", got, viewer)
+
+	got, _ = repo.Query(ctx, domain.Relation{SubjectNamespace: "user", SubjectName: "alice"})
+	expect(t, "query by deleted subject", got)
+}
+
+func testDeleteAll(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer, member)
+
+	if err := repo.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll: %v", err)
+	}
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll", got)
+	namespaces, _ := repo.GetAllNamespaces(ctx)
+	if len(namespaces) != 0 {
+		t.Errorf("GetAllNamespaces = %v, want none", namespaces)
+	}
+
+	// the store stays usable
+	create(t, repo, viewer)
+	got, _ = repo.Query(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "1"})
+	expect(t, "query after DeleteAll", got, viewer)
+}
+
+func testBatchOperationIsAtomic(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer)
+
+	err := repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateOperation, Relation: member},
+		{Type: domain.DeleteOperation, Relation: viewer},
+		// fails on the duplicate and must roll back both operations above
+		{Type: domain.CreateOperation, Relation: member},
+	})
+	if err == nil {
+		t.Fatal("BatchOperation: expected duplicate error")
+	}
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll after failed batch", got, viewer)
+
+	err = repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateOperation, Relation: member},
+		{Type: domain.CreateIfNotExistOperation, Relation: member},
+		{Type: domain.DeleteOperation, Relation: viewer},
+	})
+	if err != nil {
+		t.Fatalf("BatchOperation: %v", err)
+	}
+	got, _, _ = repo.GetAll(ctx)
+	expect(t, "GetAll after batch", got, member)
+}
+
+func testWithTransactionRollsBack(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	err := repo.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		create(t, tx, viewer)
+		// reads inside the transaction see its own writes
+		got, err := tx.Query(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "1"})
+		if err != nil {
+			return err
+		}
+		expect(t, "query inside transaction", got, viewer)
+		return tx.Create(ctx, viewer)
+	})
+	if err != (domain.AlreadyExistsError{}) {
+		t.Fatalf("WithTransaction = %v, want AlreadyExistsError", err)
+	}
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll after rollback", got)
+}
+
+func testRecreateDeleted(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	create(t, repo, viewer)
+	if err := repo.Delete(ctx, viewer); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	create(t, repo, viewer)
+	got, _, _ := repo.GetAll(ctx)
+	expect(t, "GetAll after recreate", got, viewer)
+}
+
+func testRevisions(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	revisionRepo, ok := repo.(sqldom.RevisionRepository)
+	if !ok {
+		t.Skip("repository does not number its writes")
+	}
+	revision := func() uint64 {
+		t.Helper()
+		revision, err := revisionRepo.Revision(ctx)
+		if err != nil {
+			t.Fatalf("Revision: %v", err)
+		}
+		return revision
+	}
+
+	start := revision()
+	create(t, repo, viewer)
+	if got := revision(); got != start+1 {
+		t.Fatalf("Revision after Create = %d, want %d", got, start+1)
+	}
+	// a transaction commits as one revision however many writes it makes
+	err := repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateOperation, Relation: member},
+		{Type: domain.CreateOperation, Relation: owner},
+	})
+	if err != nil {
+		t.Fatalf("BatchOperation: %v", err)
+	}
+	if got := revision(); got != start+2 {
+		t.Fatalf("Revision after BatchOperation = %d, want %d", got, start+2)
+	}
+	// a rolled back transaction does not take a revision
+	repo.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		if err := tx.Delete(ctx, member); err != nil {
+			return err
+		}
+		return domain.RequestBodyError{}
+	})
+	if got := revision(); got != start+2 {
+		t.Fatalf("Revision after rollback = %d, want %d", got, start+2)
+	}
+}
+
+func testSchemaVersions(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	schemaRepo, ok := repo.(sqldom.SchemaRepository)
+	if !ok {
+		t.Skip("repository does not store schemas")
+	}
+	read := func() domain.Schema {
+		t.Helper()
+		schema, err := schemaRepo.ReadSchema(ctx)
+		if err != nil {
+			t.Fatalf("ReadSchema: %v", err)
+		}
+		return schema
+	}
+
+	if schema := read(); schema.Version != 0 || len(schema.Namespaces) != 0 {
+		t.Fatalf("ReadSchema of an empty repository = %+v, want version 0", schema)
+	}
+	doc := domain.NamespaceDefinition{Name: "doc", Relations: []domain.RelationDefinition{
+		{Name: "viewer", Rewrite: "direct + editor"},
+	}}
+	for version := uint64(1); version <= 2; version++ {
+		written, err := schemaRepo.WriteSchema(ctx, []domain.NamespaceDefinition{doc})
+		if err != nil {
+			t.Fatalf("WriteSchema: %v", err)
+		}
+		if written.Version != version {
+			t.Errorf("WriteSchema = version %d, want %d", written.Version, version)
+		}
+		doc.Relations = append(doc.Relations, domain.RelationDefinition{Name: "editor", Rewrite: "direct"})
+	}
+	schema := read()
+	if schema.Version != 2 || len(schema.Namespaces) != 1 || len(schema.Namespaces[0].Relations) != 2 {
+		t.Errorf("ReadSchema = %+v, want version 2 with 2 relations", schema)
+	}
+}
+
+func testConditions(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	conditional := member
+	conditional.Condition = "now < expires"
+	conditional.ConditionParams = `{"expires":"2030-01-01T00:00:00Z"}`
+	create(t, repo, conditional, owner)
+
+	got, _ := repo.Query(ctx, domain.Relation{ObjectNamespace: "group", ObjectName: "eng"})
+	expect(t, "query", got, conditional)
+	got, _ = repo.QueryBySubjects(ctx, []domain.Node{{Namespace: "user", Name: "alice"}})
+	expect(t, "QueryBySubjects", got, conditional, owner)
+	got, _ = repo.QueryByObjects(ctx, []domain.Node{{Namespace: "group", Name: "eng", Relation: "member"}})
+	expect(t, "QueryByObjects", got, conditional)
+	got, _, _ = repo.GetAll(ctx)
+	expect(t, "GetAll", got, conditional, owner)
+
+	// the condition is not part of the identity of the relation
+	if err := repo.Create(ctx, member); err != (domain.AlreadyExistsError{}) {
+		t.Errorf("Create without condition = %v, want AlreadyExistsError", err)
+	}
+	if err := repo.CreateIfNotExists(ctx, member); err != nil {
+		t.Errorf("CreateIfNotExists without condition: %v", err)
+	}
+	got, _ = repo.Query(ctx, member)
+	expect(t, "query after CreateIfNotExists", got, conditional)
+	if err := repo.Delete(ctx, member); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	got, _, _ = repo.GetAll(ctx)
+	expect(t, "GetAll after Delete", got, owner)
+}
+
+func testExpiry(t *testing.T, repo sqldom.RelationRepository) {
+	ctx := context.Background()
+	expiring := member
+	later := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiring.ExpiresAt = &later
+	expired := viewer
+	earlier := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	expired.ExpiresAt = &earlier
+	create(t, repo, expiring, expired, owner)
+
+	got, _ := repo.Query(ctx, domain.Relation{ObjectNamespace: "group", ObjectName: "eng"})
+	expect(t, "query", got, expiring)
+	got, _ = repo.Query(ctx, expired)
+	expect(t, "query of expired", got)
+	got, _ = repo.QueryBySubjects(ctx, []domain.Node{{Namespace: "group", Name: "eng", Relation: "member"}})
+	expect(t, "QueryBySubjects", got)
+	got, _ = repo.QueryByObjects(ctx, []domain.Node{{Namespace: "doc", Name: "1"}})
+	expect(t, "QueryByObjects", got, owner)
+	got, _, _ = repo.GetAll(ctx)
+	expect(t, "GetAll", got, expiring, owner)
+
+	expiryRepo, ok := repo.(sqldom.ExpiryRepository)
+	if !ok {
+		t.Skip("repository does not find expired relations")
+	}
+	got, err := expiryRepo.QueryExpired(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("QueryExpired: %v", err)
+	}
+	expect(t, "QueryExpired", got, expired)
+	got, _ = expiryRepo.QueryExpired(ctx, later, 10)
+	if len(got) != 2 || !reflect.DeepEqual(got, []domain.Relation{expired, expiring}) {
+		t.Errorf("QueryExpired(later) = %v, want %v and %v in order", got, expired, expiring)
+	}
+	got, _ = expiryRepo.QueryExpired(ctx, later, 1)
+	expect(t, "QueryExpired(limit 1)", got, expired)
+
+	// writes take an expired relation for deleted
+	if err := repo.Create(ctx, viewer); err != nil {
+		t.Fatalf("Create over expired: %v", err)
+	}
+	got, _ = repo.Query(ctx, viewer)
+	expect(t, "query after Create", got, viewer)
+	got, _ = expiryRepo.QueryExpired(ctx, time.Now(), 10)
+	expect(t, "QueryExpired after Create", got)
+}
@@ -3,9 +3,11 @@ package sql
 import (
 	"fmt"
 	"os"
+	"time"
 
-	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/spf13/viper"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -13,6 +15,20 @@ import (
 var Db *gorm.DB
 
 func InitDb() (*gorm.DB, error) {
+	switch dbType := viper.GetString("db.type"); dbType {
+	case "", "postgres":
+		return initPostgres()
+	case "sqlite":
+		return initSqlite()
+	default:
+		return nil, fmt.Errorf("unsupported db type: %s", dbType)
+	}
+}
+
+func initPostgres() (*gorm.DB, error) {
+	if dsn := viper.GetString("db.postgres.dsn"); dsn != "" {
+		return openPostgres(dsn)
+	}
 	connStr := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=%s",
 		getEnv("POSTGRES_HOST", "localhost"),
@@ -22,21 +38,100 @@ func InitDb() (*gorm.DB, error) {
 		getEnv("POSTGRES_DB", "zanzibar-dag"),
 		getEnv("POSTGRES_TIMEZONE", "Asia/Taipei"),
 	)
+	return openPostgres(connStr)
+}
+
+func openPostgres(dsn string) (*gorm.DB, error) {
+	return gorm.Open(
+		postgres.Open(dsn), &gorm.Config{
+			Logger:         nil,
+			TranslateError: true,
+		},
+	)
+}
+
+func initSqlite() (*gorm.DB, error) {
+	path := viper.GetString("db.sqlite.path")
+	if path == "" {
+		path = "gorm.db"
+	}
+	// WAL lets readers run alongside the single writer, and immediate
+	// transactions take the write lock up front instead of failing with
+	// SQLITE_BUSY when a read transaction tries to upgrade.
+	dsn := path + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
 	return gorm.Open(
-		postgres.Open(connStr), &gorm.Config{
-			Logger: nil,
+		sqlite.Open(dsn), &gorm.Config{
+			Logger:         nil,
+			TranslateError: true,
 		},
 	)
 }
 
+// InitReplicas opens the read replicas listed in db.postgres.replicas and
+// starts their health checks. It returns nil if none are configured.
+func InitReplicas(primary *gorm.DB) (*ReplicaPool, error) {
+	dsns := viper.GetStringSlice("db.postgres.replicas")
+	if len(dsns) == 0 || primary.Dialector.Name() != "postgres" {
+		return nil, nil
+	}
+	replicas := []*gorm.DB{}
+	for _, dsn := range dsns {
+		db, err := openPostgres(dsn)
+		if err != nil {
+			return nil, err
+		}
+		replicas = append(replicas, db)
+	}
+	interval := viper.GetDuration("db.postgres.replica-health-check-interval")
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	pool := NewReplicaPool(primary, replicas)
+	pool.Start(interval)
+	return pool, nil
+}
+
+// StartGarbageCollector collects the history older than db.snapshot-retention
+// every db.snapshot-gc-interval.
+func StartGarbageCollector(repo *RelationRepository) {
+	retention := viper.GetDuration("db.snapshot-retention")
+	if retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	interval := viper.GetDuration("db.snapshot-gc-interval")
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	repo.StartGarbageCollector(retention, interval)
+}
+
 type OrmRepository struct {
 	RelationshipRepo RelationRepository
 }
 
+// NewOrmRepository refuses a schema newer than the binary and brings an older
+// one up to date, unless db.auto-migrate is false and it has to be migrated
+// by hand with the migrate command.
 func NewOrmRepository(db *gorm.DB) (*OrmRepository, error) {
-	if err := db.AutoMigrate(&sqldomain.Relation{}); err != nil {
+	migrator, err := NewMigrator(db)
+	if err != nil {
 		return nil, err
 	}
+	version, err := migrator.CheckVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version < migrator.Latest() {
+		if viper.IsSet("db.auto-migrate") && !viper.GetBool("db.auto-migrate") {
+			return nil, fmt.Errorf(
+				"database schema version %d is older than %d, run the migrate command",
+				version, migrator.Latest(),
+			)
+		}
+		if err := migrator.Up(); err != nil {
+			return nil, err
+		}
+	}
 
 	return &OrmRepository{
 		RelationshipRepo: *NewRelationRepository(db),
@@ -0,0 +1,196 @@
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+
+	"gorm.io/gorm"
+)
+
+// importBatchSize is the number of rows per INSERT when staging without
+// COPY, at twelve parameters per row it stays below the SQLite limit.
+const importBatchSize = 1000
+
+var importColumns = []string{
+	"import_id", "line", "all_columns",
+	"object_namespace", "object_name", "relation",
+	"subject_namespace", "subject_name", "subject_relation",
+	"condition", "condition_params", "expires_at",
+}
+
+type relationImport struct {
+	ImportID         string
+	Line             int
+	AllColumns       string
+	ObjectNamespace  string
+	ObjectName       string
+	Relation         string
+	SubjectNamespace string
+	SubjectName      string
+	SubjectRelation  string
+	Condition        string
+	ConditionParams  string
+	ExpiresAt        *time.Time
+}
+
+func (relationImport) TableName() string {
+	return "relation_imports"
+}
+
+// StageImport copies relations into relation_imports, with COPY on Postgres
+// and batched inserts otherwise. Staging always goes to the primary.
+func (r *RelationRepository) StageImport(ctx context.Context, importID string, line int, relations []domain.Relation) error {
+	if r.DB.Dialector.Name() == "postgres" {
+		if sqlDB, err := r.DB.DB(); err == nil {
+			return copyImport(ctx, sqlDB, importID, line, relations)
+		}
+	}
+	rows := make([]relationImport, len(relations))
+	for i, relation := range relations {
+		rows[i] = relationImport{
+			ImportID:         importID,
+			Line:             line + i,
+			AllColumns:       concatAttr(relation),
+			ObjectNamespace:  relation.ObjectNamespace,
+			ObjectName:       relation.ObjectName,
+			Relation:         relation.Relation,
+			SubjectNamespace: relation.SubjectNamespace,
+			SubjectName:      relation.SubjectName,
+			SubjectRelation:  relation.SubjectRelation,
+			Condition:        relation.Condition,
+			ConditionParams:  string(relation.ConditionParams),
+			ExpiresAt:        expiresAt(relation),
+		}
+	}
+	return r.DB.WithContext(ctx).CreateInBatches(rows, importBatchSize).Error
+}
+
+func (r *RelationRepository) CommitImport(ctx context.Context, importID string) (int, error) {
+	created := 0
+	err := r.write(ctx, func(db *gorm.DB, revision uint64) error {
+		// expired relations make way for the staged ones
+		err := db.Model(&sqldom.Relation{}).
+			Where("deleted_rev IS NULL AND expires_at <= ?", time.Now().UTC()).
+			Where("all_columns IN (?)", db.Model(&relationImport{}).Select("all_columns").Where("import_id = ?", importID)).
+			Updates(deletedAt(revision)).Error
+		if err != nil {
+			return err
+		}
+		// of repeated relations the first line is imported
+		result := db.Exec(`
+			INSERT INTO relations (all_columns, object_namespace, object_name, relation, subject_namespace, subject_name, subject_relation, condition, condition_params, expires_at, created_rev)
+			SELECT all_columns, object_namespace, object_name, relation, subject_namespace, subject_name, subject_relation, condition, condition_params, expires_at, CAST(? AS BIGINT)
+			FROM relation_imports i
+			WHERE import_id = ? AND NOT EXISTS (
+				SELECT 1 FROM relation_imports earlier
+				WHERE earlier.import_id = i.import_id AND earlier.all_columns = i.all_columns AND earlier.line < i.line
+			)
+			ON CONFLICT (all_columns) WHERE deleted_rev IS NULL DO NOTHING
+		`, revision, importID)
+		if result.Error != nil {
+			return result.Error
+		}
+		created = int(result.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// ReadImport reads from the primary, which the relations were staged to.
+func (r *RelationRepository) ReadImport(ctx context.Context, importID string, line int, limit int) ([]domain.Relation, error) {
+	var rows []relationImport
+	err := r.DB.WithContext(ctx).Where("import_id = ? AND line >= ?", importID, line).
+		Order("line").Limit(limit).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return convertImports(rows), nil
+}
+
+// ImportGraph reads from the primary too. The staged relations that expired
+// are left out like the stored ones.
+func (r *RelationRepository) ImportGraph(importID string) sqldom.RelationRepository {
+	return &RelationRepository{DB: r.DB, importID: importID}
+}
+
+// queryImport returns the relations staged under the import of the
+// repository that match nodes.
+func (r *RelationRepository) queryImport(ctx context.Context, nodes *gorm.DB) ([]domain.Relation, error) {
+	var rows []relationImport
+	err := r.DB.WithContext(ctx).
+		Where("import_id = ? AND (expires_at IS NULL OR expires_at > ?)", r.importID, time.Now().UTC()).
+		Where(nodes).Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return convertImports(rows), nil
+}
+
+func (r *RelationRepository) CreatedSince(ctx context.Context, revision uint64) ([]domain.Relation, error) {
+	var relations []sqldom.Relation
+	err := r.DB.WithContext(ctx).Where("created_rev > ? AND deleted_rev IS NULL", revision).Find(&relations).Error
+	if err != nil {
+		return nil, err
+	}
+	newRelations := make([]domain.Relation, len(relations))
+	for i, relation := range relations {
+		newRelations[i] = convertToRelation(relation)
+	}
+	return newRelations, nil
+}
+
+func convertImports(rows []relationImport) []domain.Relation {
+	relations := make([]domain.Relation, len(rows))
+	for i, row := range rows {
+		relations[i] = convertToRelation(sqldom.Relation{
+			ObjectNamespace:  row.ObjectNamespace,
+			ObjectName:       row.ObjectName,
+			Relation:         row.Relation,
+			SubjectNamespace: row.SubjectNamespace,
+			SubjectName:      row.SubjectName,
+			SubjectRelation:  row.SubjectRelation,
+			Condition:        row.Condition,
+			ConditionParams:  row.ConditionParams,
+			ExpiresAt:        row.ExpiresAt,
+		})
+	}
+	return relations
+}
+
+func (r *RelationRepository) DropImport(ctx context.Context, importID string) error {
+	return r.DB.WithContext(ctx).Where("import_id = ?", importID).Delete(&relationImport{}).Error
+}
+
+// copyImport runs COPY on a pgx connection taken from the pool of db.
+func copyImport(ctx context.Context, db *gosql.DB, importID string, line int, relations []domain.Relation) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	rows := make([][]interface{}, len(relations))
+	for i, relation := range relations {
+		rows[i] = []interface{}{
+			importID, line + i, concatAttr(relation),
+			relation.ObjectNamespace, relation.ObjectName, relation.Relation,
+			relation.SubjectNamespace, relation.SubjectName, relation.SubjectRelation,
+			relation.Condition, string(relation.ConditionParams), expiresAt(relation),
+		}
+	}
+	return conn.Raw(func(driverConn interface{}) error {
+		_, err := driverConn.(*stdlib.Conn).Conn().CopyFrom(
+			ctx, pgx.Identifier{"relation_imports"}, importColumns, pgx.CopyFromRows(rows),
+		)
+		return err
+	})
+}
@@ -0,0 +1,237 @@
+package sql
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+const migrationLockKey int64 = 0x67726170687801
+
+// Migration is one versioned schema step, read from
+// migrations/<dialect>/<version>_<name>.up.sql and its .down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies the migration set of the dialect of DB and records the
+// applied versions in schema_migrations.
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for db dialect %s", dialect)
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", fileName)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", fileName)
+		}
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, fileName))
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d of %s needs both up and down", migration.Version, dialect)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migrations of %s are not numbered 1..n, found %d at %d", dialect, migration.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Latest is the schema version this binary was built for.
+func (m *Migrator) Latest() int {
+	return len(m.Migrations)
+}
+
+// Version is the schema version of the database, 0 for an empty one.
+func (m *Migrator) Version() (int, error) {
+	if err := ensureTable(m.DB); err != nil {
+		return 0, err
+	}
+	return currentVersion(m.DB)
+}
+
+// Up applies every pending migration, each in its own transaction.
+func (m *Migrator) Up() error {
+	for {
+		applied, err := m.step(func(version int) (*Migration, bool) {
+			if version >= m.Latest() {
+				return nil, false
+			}
+			return &m.Migrations[version], true
+		})
+		if err != nil || !applied {
+			return err
+		}
+	}
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(steps int) error {
+	for i := 0; i < steps; i++ {
+		applied, err := m.step(func(version int) (*Migration, bool) {
+			if version == 0 || version > m.Latest() {
+				return nil, false
+			}
+			return &m.Migrations[version-1], true
+		})
+		if err != nil || !applied {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckVersion fails if the database was migrated by a newer binary, whose
+// schema this one may not understand.
+func (m *Migrator) CheckVersion() (int, error) {
+	version, err := m.Version()
+	if err != nil {
+		return 0, err
+	}
+	if version > m.Latest() {
+		return version, fmt.Errorf(
+			"database schema version %d is newer than the %d this binary supports",
+			version, m.Latest(),
+		)
+	}
+	return version, nil
+}
+
+// step runs the migration next picks for the current version. The version is
+// read inside the transaction, under an advisory lock on postgres, so
+// replicas starting together do not apply the same migration twice.
+func (m *Migrator) step(next func(version int) (*Migration, bool)) (bool, error) {
+	if _, err := m.CheckVersion(); err != nil {
+		return false, err
+	}
+	applied := false
+	err := m.DB.Transaction(func(tx *gorm.DB) error {
+		if tx.Dialector.Name() == "postgres" {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+		}
+		version, err := currentVersion(tx)
+		if err != nil {
+			return err
+		}
+		migration, ok := next(version)
+		if !ok {
+			return nil
+		}
+		if migration.Version == version+1 {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return fmt.Errorf("migration %d up: %w", migration.Version, err)
+			}
+			err = tx.Create(&schemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		} else {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return fmt.Errorf("migration %d down: %w", migration.Version, err)
+			}
+			err = tx.Delete(&schemaMigration{Version: migration.Version}).Error
+		}
+		applied = err == nil
+		return err
+	})
+	return applied, err
+}
+
+func ensureTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+}
+
+func currentVersion(db *gorm.DB) (int, error) {
+	var versions []int
+	if err := db.Model(&schemaMigration{}).Order("version DESC").Limit(1).
+		Pluck("version", &versions).Error; err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	return versions[0], nil
+}
@@ -0,0 +1,111 @@
+package sql_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+func newSqliteDb(t *testing.T) *gorm.DB {
+	viper.Set("db.type", "sqlite")
+	viper.Set("db.sqlite.path", filepath.Join(t.TempDir(), "test.db"))
+	db, err := sql.InitDb()
+	if err != nil {
+		t.Fatalf("InitDb: %v", err)
+	}
+	return db
+}
+
+func TestMigrateLegacySchema(t *testing.T) {
+	ctx := context.Background()
+	db := newSqliteDb(t)
+
+	// a database created by AutoMigrate: no schema_migrations, duplicates and
+	// rows written before all_columns existed
+	legacy := []string{
+		`CREATE TABLE relations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			all_columns TEXT,
+			object_namespace TEXT, object_name TEXT, relation TEXT,
+			subject_namespace TEXT, subject_name TEXT, subject_relation TEXT
+		)`,
+		`CREATE INDEX idx_relations_all_columns ON relations (all_columns)`,
+		`INSERT INTO relations (all_columns, object_namespace, object_name, relation, subject_namespace, subject_name, subject_relation)
+		VALUES ('doc%1%viewer%user%alice%', 'doc', '1', 'viewer', 'user', 'alice', ''),
+			('doc%1%viewer%user%alice%', 'doc', '1', 'viewer', 'user', 'alice', ''),
+			('', 'doc', '1', 'viewer', 'user', 'bob', '')`,
+	}
+	for _, stmt := range legacy {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("legacy schema: %v", err)
+		}
+	}
+
+	ormRepo, err := sql.NewOrmRepository(db)
+	if err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	repo := &ormRepo.RelationshipRepo
+	relations, _, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(relations) != 2 {
+		t.Errorf("GetAll = %v, want the duplicate removed", relations)
+	}
+	bob := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "bob"}
+	if err := repo.Create(ctx, bob); err != (domain.AlreadyExistsError{}) {
+		t.Errorf("Create backfilled relation = %v, want AlreadyExistsError", err)
+	}
+}
+
+func TestMigrateUpDown(t *testing.T) {
+	db := newSqliteDb(t)
+	migrator, err := sql.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if version, _ := migrator.Version(); version != migrator.Latest() {
+		t.Errorf("Version after Up = %d, want %d", version, migrator.Latest())
+	}
+
+	if err := migrator.Down(migrator.Latest()); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if version, _ := migrator.Version(); version != 0 {
+		t.Errorf("Version after Down = %d, want 0", version)
+	}
+	if db.Migrator().HasTable("relations") {
+		t.Error("relations table still exists after migrating down")
+	}
+
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up again: %v", err)
+	}
+	if err := migrator.Up(); err != nil {
+		t.Fatalf("Up when up to date: %v", err)
+	}
+}
+
+func TestRefuseNewerSchema(t *testing.T) {
+	db := newSqliteDb(t)
+	if _, err := sql.NewOrmRepository(db); err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (999, 'future', CURRENT_TIMESTAMP)").Error
+	if err != nil {
+		t.Fatalf("insert version: %v", err)
+	}
+	if _, err := sql.NewOrmRepository(db); err == nil {
+		t.Error("NewOrmRepository accepted a schema newer than the binary")
+	}
+}
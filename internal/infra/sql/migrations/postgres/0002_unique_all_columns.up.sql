@@ -0,0 +1,8 @@
+UPDATE relations
+SET all_columns = object_namespace || '%' || object_name || '%' || relation || '%' ||
+    subject_namespace || '%' || subject_name || '%' || subject_relation
+WHERE all_columns IS NULL OR all_columns = '';
+DELETE FROM relations a USING relations b
+WHERE a.all_columns = b.all_columns AND a.id > b.id;
+DROP INDEX IF EXISTS idx_relations_all_columns;
+CREATE UNIQUE INDEX IF NOT EXISTS uidx_relations_all_columns ON relations (all_columns);
@@ -0,0 +1,16 @@
+-- relations are deleted by setting deleted_rev, so reads can see the graph
+-- as of a past revision until the deleted rows are collected
+ALTER TABLE relations ADD COLUMN created_rev BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE relations ADD COLUMN deleted_rev BIGINT;
+ALTER TABLE relations ADD COLUMN deleted_at TIMESTAMPTZ;
+DROP INDEX IF EXISTS uidx_relations_all_columns;
+CREATE UNIQUE INDEX uidx_relations_all_columns ON relations (all_columns) WHERE deleted_rev IS NULL;
+CREATE INDEX idx_relations_deleted_rev ON relations (deleted_rev) WHERE deleted_rev IS NOT NULL;
+-- revision is the last committed revision, snapshots older than
+-- min_revision were collected
+CREATE TABLE graph_revision (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    revision BIGINT NOT NULL,
+    min_revision BIGINT NOT NULL
+);
+INSERT INTO graph_revision (id, revision, min_revision) VALUES (1, 0, 0);
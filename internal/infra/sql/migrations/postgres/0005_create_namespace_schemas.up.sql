@@ -0,0 +1,6 @@
+-- versions of the namespace schema, the latest is in effect
+CREATE TABLE IF NOT EXISTS namespace_schemas (
+    version BIGINT PRIMARY KEY,
+    namespaces TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
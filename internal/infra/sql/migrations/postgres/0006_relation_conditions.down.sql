@@ -0,0 +1,5 @@
+ALTER TABLE relation_imports DROP COLUMN condition_params;
+ALTER TABLE relation_imports DROP COLUMN condition;
+DROP INDEX IF EXISTS idx_relations_conditional;
+ALTER TABLE relations DROP COLUMN condition_params;
+ALTER TABLE relations DROP COLUMN condition;
@@ -0,0 +1,7 @@
+-- a relation with a condition only holds while its expression evaluates
+-- true, the partial index finds whether any relation has one
+ALTER TABLE relations ADD COLUMN condition TEXT NOT NULL DEFAULT '';
+ALTER TABLE relations ADD COLUMN condition_params TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_relations_conditional ON relations (id) WHERE condition <> '';
+ALTER TABLE relation_imports ADD COLUMN condition TEXT NOT NULL DEFAULT '';
+ALTER TABLE relation_imports ADD COLUMN condition_params TEXT NOT NULL DEFAULT '';
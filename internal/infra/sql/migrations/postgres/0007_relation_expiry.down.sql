@@ -0,0 +1,3 @@
+ALTER TABLE relation_imports DROP COLUMN expires_at;
+DROP INDEX IF EXISTS idx_relations_expires_at;
+ALTER TABLE relations DROP COLUMN expires_at;
@@ -0,0 +1,5 @@
+-- a relation with expires_at is not read from then on, and deleted by the
+-- sweeper, which finds the expired ones through the partial index
+ALTER TABLE relations ADD COLUMN expires_at TIMESTAMPTZ;
+CREATE INDEX idx_relations_expires_at ON relations (expires_at) WHERE expires_at IS NOT NULL;
+ALTER TABLE relation_imports ADD COLUMN expires_at TIMESTAMPTZ;
@@ -0,0 +1,6 @@
+-- an import is checked for cycles before its commit, looking its staged
+-- relations up by node, and rechecked at the commit against the relations
+-- created since
+CREATE INDEX idx_relation_imports_object ON relation_imports (import_id, object_namespace, object_name, relation);
+CREATE INDEX idx_relation_imports_subject ON relation_imports (import_id, subject_namespace, subject_name, subject_relation);
+CREATE INDEX idx_relations_created_rev ON relations (created_rev);
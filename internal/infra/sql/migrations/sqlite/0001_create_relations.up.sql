@@ -0,0 +1,14 @@
+-- the schema AutoMigrate used to create, existing databases adopt it as is
+CREATE TABLE IF NOT EXISTS relations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    all_columns TEXT,
+    object_namespace TEXT,
+    object_name TEXT,
+    relation TEXT,
+    subject_namespace TEXT,
+    subject_name TEXT,
+    subject_relation TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_relations_all_columns ON relations (all_columns);
+CREATE INDEX IF NOT EXISTS idx_object ON relations (object_namespace, object_name, relation);
+CREATE INDEX IF NOT EXISTS idx_subject ON relations (subject_namespace, subject_name, subject_relation);
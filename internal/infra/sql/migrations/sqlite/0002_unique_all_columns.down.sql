@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uidx_relations_all_columns;
+CREATE INDEX IF NOT EXISTS idx_relations_all_columns ON relations (all_columns);
@@ -0,0 +1,8 @@
+UPDATE relations
+SET all_columns = object_namespace || '%' || object_name || '%' || relation || '%' ||
+    subject_namespace || '%' || subject_name || '%' || subject_relation
+WHERE all_columns IS NULL OR all_columns = '';
+DELETE FROM relations
+WHERE id NOT IN (SELECT MIN(id) FROM relations GROUP BY all_columns);
+DROP INDEX IF EXISTS idx_relations_all_columns;
+CREATE UNIQUE INDEX IF NOT EXISTS uidx_relations_all_columns ON relations (all_columns);
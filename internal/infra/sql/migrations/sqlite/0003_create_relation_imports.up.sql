@@ -0,0 +1,12 @@
+-- staging of bulk imports, rows only live until the import commits
+CREATE TABLE IF NOT EXISTS relation_imports (
+    import_id TEXT NOT NULL,
+    all_columns TEXT NOT NULL,
+    object_namespace TEXT NOT NULL,
+    object_name TEXT NOT NULL,
+    relation TEXT NOT NULL,
+    subject_namespace TEXT NOT NULL,
+    subject_name TEXT NOT NULL,
+    subject_relation TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_relation_imports_import_id ON relation_imports (import_id);
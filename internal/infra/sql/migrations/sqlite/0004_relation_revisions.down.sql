@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS graph_revision;
+DELETE FROM relations WHERE deleted_rev IS NOT NULL;
+DROP INDEX IF EXISTS idx_relations_deleted_rev;
+DROP INDEX IF EXISTS uidx_relations_all_columns;
+CREATE UNIQUE INDEX uidx_relations_all_columns ON relations (all_columns);
+ALTER TABLE relations DROP COLUMN deleted_at;
+ALTER TABLE relations DROP COLUMN deleted_rev;
+ALTER TABLE relations DROP COLUMN created_rev;
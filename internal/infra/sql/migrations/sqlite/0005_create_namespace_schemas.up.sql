@@ -0,0 +1,6 @@
+-- versions of the namespace schema, the latest is in effect
+CREATE TABLE IF NOT EXISTS namespace_schemas (
+    version INTEGER PRIMARY KEY,
+    namespaces TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_relation_imports_all_columns;
+DROP INDEX IF EXISTS idx_relation_imports_line;
+ALTER TABLE relation_imports DROP COLUMN line;
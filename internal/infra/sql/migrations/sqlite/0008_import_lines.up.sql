@@ -0,0 +1,5 @@
+-- line orders the staged relations of an import, the first of repeated
+-- ones is imported and the cycle check reads them back in pages
+ALTER TABLE relation_imports ADD COLUMN line BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX idx_relation_imports_line ON relation_imports (import_id, line);
+CREATE INDEX idx_relation_imports_all_columns ON relation_imports (import_id, all_columns, line);
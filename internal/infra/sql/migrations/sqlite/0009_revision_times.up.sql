@@ -0,0 +1,6 @@
+-- committed_at is the time a revision was written at, a snapshot takes the
+-- expiry of relations at the time of its revision
+CREATE TABLE graph_revision_times (
+    revision BIGINT PRIMARY KEY,
+    committed_at TIMESTAMP NOT NULL
+);
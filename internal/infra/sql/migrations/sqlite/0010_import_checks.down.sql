@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_relations_created_rev;
+DROP INDEX IF EXISTS idx_relation_imports_subject;
+DROP INDEX IF EXISTS idx_relation_imports_object;
@@ -0,0 +1,256 @@
+package sql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+
+	"gorm.io/gorm"
+)
+
+// Reachable walks the graph from subject with a recursive CTE and reports
+// whether object can be reached, so the whole search costs one round trip.
+func (r *RelationRepository) Reachable(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (bool, error) {
+	var found bool
+	err := r.read(ctx, func(db *gorm.DB) error {
+		var err error
+		_, found, err = r.reachDepth(db, subject, object, searchCondition, maxDepth)
+		return err
+	})
+	return found, err
+}
+
+// Search first walks the graph with the deduplicated walk of Reachable, and
+// reports in the same query what relations the walk met. The shortest path
+// is only searched for if the walk found object past no condition.
+func (r *RelationRepository) Search(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int, path bool) (sqldom.SearchResult, error) {
+	var result sqldom.SearchResult
+	// all queries run on the same database, a second replica may lag
+	// behind the nodes found on the first
+	err := r.read(ctx, func(db *gorm.DB) error {
+		var err error
+		result, err = r.search(db, subject, object, searchCondition, maxDepth)
+		if err != nil || !path || !result.Found || result.Conditional {
+			return err
+		}
+		result.Path, err = r.shortestPath(db, subject, object, searchCondition, maxDepth)
+		return err
+	})
+	return result, err
+}
+
+// search reports whether object is reached and whether a relation with a
+// condition or an expiry leaves subject or a node the walk expanded.
+func (r *RelationRepository) search(db *gorm.DB, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (sqldom.SearchResult, error) {
+	reach, args := r.reachCTE(subject, object, searchCondition, maxDepth)
+	startClause, startArgs := startCondition(subject)
+	expandClause, expandArgs := expandCondition("reach", object, searchCondition)
+	visible, visibleArgs := r.visible("r")
+	met := func(kind string) (string, []interface{}) {
+		clause := `EXISTS (
+				SELECT 1 FROM relations r
+				WHERE ` + kind + ` AND ` + visible + ` AND (` + startClause + ` OR EXISTS (
+					SELECT 1 FROM reach
+					WHERE r.subject_namespace = reach.namespace
+						AND r.subject_name = reach.name
+						AND r.subject_relation = reach.relation
+						AND ` + expandClause + `
+				))
+			)`
+		metArgs := append([]interface{}{}, visibleArgs...)
+		metArgs = append(metArgs, startArgs...)
+		return clause, append(metArgs, expandArgs...)
+	}
+	conditional, conditionalArgs := met("r.condition <> ''")
+	expiring, expiringArgs := met("r.expires_at IS NOT NULL")
+	sqlQuery := reach + `
+		SELECT EXISTS (
+				SELECT 1 FROM reach
+				WHERE namespace = ? AND name = ? AND relation = ?
+			) AS found,
+			` + conditional + ` AS conditional,
+			` + expiring + ` AS expiring
+	`
+	args = append(args, object.Namespace, object.Name, object.Relation)
+	args = append(args, conditionalArgs...)
+	args = append(args, expiringArgs...)
+	var result sqldom.SearchResult
+	err := db.Raw(sqlQuery, args...).Row().Scan(&result.Found, &result.Conditional, &result.Expiring)
+	return result, err
+}
+
+func (r *RelationRepository) shortestPath(db *gorm.DB, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) ([]domain.Relation, error) {
+	if maxDepth <= 0 {
+		// no shortest path is longer than the number of nodes reached, the
+		// bound only matters if the graph has a cycle
+		reach, reachArgs := r.reachCTE(subject, object, searchCondition, 0)
+		var count int64
+		if err := db.Raw(reach+` SELECT COUNT(*) FROM reach`, reachArgs...).Scan(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, nil
+		}
+		maxDepth = int(count)
+	}
+
+	reach, args := r.reachCTE(subject, object, searchCondition, maxDepth)
+	startClause, startArgs := startCondition(subject)
+	expandClause, expandArgs := expandCondition("previous", object, searchCondition)
+	visible, visibleArgs := r.visible("r")
+	// a node first reached at some depth has a relation from a node first
+	// reached a level before, or from subject at the first level, of which
+	// the one with the lowest id is its predecessor
+	sqlQuery := reach + `, levels(namespace, name, relation, depth) AS (
+			SELECT namespace, name, relation, MIN(depth) FROM reach
+			GROUP BY namespace, name, relation
+		), predecessors(namespace, name, relation, depth, id) AS (
+			SELECT levels.namespace, levels.name, levels.relation, levels.depth, MIN(r.id)
+			FROM levels
+			JOIN relations r ON r.object_namespace = levels.namespace
+				AND r.object_name = levels.name
+				AND r.relation = levels.relation
+			LEFT JOIN levels previous ON r.subject_namespace = previous.namespace
+				AND r.subject_name = previous.name
+				AND r.subject_relation = previous.relation
+				AND previous.depth = levels.depth - 1
+			WHERE ` + visible + ` AND (
+				(levels.depth = 1 AND ` + startClause + `)
+				OR (previous.depth IS NOT NULL AND ` + expandClause + `))
+			GROUP BY levels.namespace, levels.name, levels.relation, levels.depth
+		), back(depth, id) AS (
+			SELECT depth, id FROM predecessors
+			WHERE namespace = ? AND name = ? AND relation = ?
+			UNION ALL
+			SELECT p.depth, p.id
+			FROM back
+			JOIN relations r ON r.id = back.id
+			JOIN predecessors p ON p.namespace = r.subject_namespace
+				AND p.name = r.subject_name
+				AND p.relation = r.subject_relation
+				AND p.depth = back.depth - 1
+		)
+		SELECT id FROM back ORDER BY depth
+	`
+	args = append(args, visibleArgs...)
+	args = append(args, startArgs...)
+	args = append(args, expandArgs...)
+	args = append(args, object.Namespace, object.Name, object.Relation)
+	var ids []uint
+	if err := db.Raw(sqlQuery, args...).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var relations []sqldom.Relation
+	if err := db.Where("id IN ?", ids).Find(&relations).Error; err != nil {
+		return nil, err
+	}
+	byID := map[uint]sqldom.Relation{}
+	for _, relation := range relations {
+		byID[relation.ID] = relation
+	}
+	path := make([]domain.Relation, len(ids))
+	for i, id := range ids {
+		path[i] = convertToRelation(byID[id])
+	}
+	return path, nil
+}
+
+// reachDepth returns the length of the shortest path from subject to object
+// of at most maxDepth relations. With a maxDepth of 0 or less the returned
+// depth is meaningless, see reachCTE.
+func (r *RelationRepository) reachDepth(db *gorm.DB, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (int, bool, error) {
+	reach, args := r.reachCTE(subject, object, searchCondition, maxDepth)
+	sqlQuery := reach + `
+		SELECT depth FROM reach
+		WHERE namespace = ? AND name = ? AND relation = ?
+		ORDER BY depth
+		LIMIT 1
+	`
+	args = append(args, object.Namespace, object.Name, object.Relation)
+	var depths []int
+	if err := db.Raw(sqlQuery, args...).Scan(&depths).Error; err != nil {
+		return 0, false, err
+	}
+	if len(depths) == 0 {
+		return 0, false, nil
+	}
+	return depths[0], true, nil
+}
+
+// reachCTE returns the recursive CTE named reach of the nodes walked from
+// subject within maxDepth relations. The walk keeps one row per (node, depth)
+// and UNION drops duplicates, so a node is expanded at most once per level.
+// With a maxDepth of 0 or less every row gets depth 1 instead: a node is
+// expanded only once in total, but the depth is meaningless.
+func (r *RelationRepository) reachCTE(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, maxDepth int) (string, []interface{}) {
+	startClause, startArgs := startCondition(subject)
+	expandClause, expandArgs := expandCondition("reach", object, searchCondition)
+	startVisible, startVisibleArgs := r.visible("relations")
+	expandVisible, expandVisibleArgs := r.visible("r")
+	step, depthClause := 0, "1 = 1"
+	if maxDepth > 0 {
+		step, depthClause = 1, "reach.depth < "+strconv.Itoa(maxDepth)
+	}
+	sqlQuery := `
+		WITH RECURSIVE reach(namespace, name, relation, depth) AS (
+			SELECT object_namespace, object_name, relation, 1
+			FROM relations
+			WHERE ` + startClause + ` AND ` + startVisible + `
+			UNION
+			SELECT r.object_namespace, r.object_name, r.relation, reach.depth + ` + strconv.Itoa(step) + `
+			FROM relations r
+			JOIN reach ON r.subject_namespace = reach.namespace
+				AND r.subject_name = reach.name
+				AND r.subject_relation = reach.relation
+			WHERE ` + depthClause + ` AND ` + expandClause + ` AND ` + expandVisible + `
+		)`
+	args := append(startArgs, startVisibleArgs...)
+	args = append(args, expandArgs...)
+	args = append(args, expandVisibleArgs...)
+	return sqlQuery, args
+}
+
+// startCondition matches the relations leaving subject. An empty subject
+// relation matches any, like the gorm struct queries of Query, and the
+// relations of the wildcard of the namespace, which has no relation.
+func startCondition(subject domain.Node) (string, []interface{}) {
+	if subject.Relation != "" {
+		return "subject_namespace = ? AND subject_name = ? AND subject_relation = ?",
+			[]interface{}{subject.Namespace, subject.Name, subject.Relation}
+	}
+	return "subject_namespace = ? AND subject_name IN ?",
+		[]interface{}{subject.Namespace, []string{subject.Name, domain.Wildcard}}
+}
+
+// expandCondition decides which reached nodes of the CTE named table are
+// walked further: not the object itself, and only the nodes searchCondition
+// does not stop at.
+func expandCondition(table string, object domain.Node, searchCondition domain.SearchCondition) (string, []interface{}) {
+	clause := "NOT (" + table + ".namespace = ? AND " + table + ".name = ? AND " + table + ".relation = ?)"
+	args := []interface{}{object.Namespace, object.Name, object.Relation}
+
+	in := []string{}
+	if len(searchCondition.In.Namespaces) > 0 {
+		in = append(in, table+".namespace IN ?")
+		args = append(args, searchCondition.In.Namespaces)
+	}
+	if len(searchCondition.In.Names) > 0 {
+		in = append(in, table+".name IN ?")
+		args = append(args, searchCondition.In.Names)
+	}
+	if len(searchCondition.In.Relations) > 0 {
+		in = append(in, table+".relation IN ?")
+		args = append(args, searchCondition.In.Relations)
+	}
+	if len(in) > 0 {
+		clause += " AND (" + strings.Join(in, " OR ") + ")"
+	}
+	return clause, args
+}
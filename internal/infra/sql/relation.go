@@ -1,101 +1,193 @@
 package sql
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/skyrocketOoO/go-utility/set"
 	"github.com/skyrocketOoO/zanazibar-dag/domain"
 	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// queryChunkSize is the number of nodes per query of queryByNodes, at three
+// parameters per node it stays below the limits of both Postgres and SQLite.
+const queryChunkSize = 5000
+
+// graphLockKey is the Postgres advisory lock key of write transactions that
+// may create relations.
+const graphLockKey int64 = 0x67726170687800 // "graphx"
+
 type RelationRepository struct {
+	// DB is the primary, which takes every write.
 	DB *gorm.DB
+	// Replicas serve the reads if set. Transactions and the repository
+	// returned by Primary read from DB.
+	Replicas *ReplicaPool
+	// snapshot pins the reads to the graph as of a revision, nil reads the
+	// live relations. snapshotAt is the time the revision was written at,
+	// zero for the revisions written before the times were recorded.
+	snapshot   *uint64
+	snapshotAt time.Time
+	// tx is set on the repositories WithTransaction binds to a transaction.
+	tx *transaction
+	// importID is set on the repositories ImportGraph returns, whose
+	// QueryBySubjects and QueryByObjects add the relations staged under it.
+	importID string
 }
 
-func NewRelationRepository(db *gorm.DB) *RelationRepository {
-	return &RelationRepository{DB: db}
+// transaction holds the revision a write transaction commits as, which its
+// first write allocates.
+type transaction struct {
+	revision uint64
 }
 
-func (r *RelationRepository) Create(relation domain.Relation) error {
-	sqlRelation := convertToSqlModel(relation)
-	return r.DB.Create(&sqlRelation).Error
-}
-
-func (r *RelationRepository) Delete(relation domain.Relation) error {
-	return r.DB.Where("all_columns = ?", concatAttr(relation)).Delete(&sqldom.Relation{}).Error
+func NewRelationRepository(db *gorm.DB) *RelationRepository {
+	return &RelationRepository{DB: db}
 }
 
-func (r *RelationRepository) DeleteByQueries(queries []domain.Relation) error {
-	operations := set.NewSet[domain.Operation]()
-	for _, query := range queries {
-		relations, err := r.Query(query)
-		if err != nil {
+func (r *RelationRepository) Create(ctx context.Context, relation domain.Relation) error {
+	return r.write(ctx, func(db *gorm.DB, revision uint64) error {
+		if err := deleteExpired(db, relation, revision); err != nil {
 			return err
 		}
-		for _, relation := range relations {
-			operations.Add(domain.Operation{
-				Type:     domain.DeleteOperation,
-				Relation: relation,
-			})
+		sqlRelation := convertToSqlModel(relation)
+		sqlRelation.CreatedRev = revision
+		if err := db.Create(&sqlRelation).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.AlreadyExistsError{}
+			}
+			return err
 		}
-	}
+		return nil
+	})
+}
 
-	return r.BatchOperation(operations.ToSlice())
+func (r *RelationRepository) CreateIfNotExists(ctx context.Context, relation domain.Relation) error {
+	return r.write(ctx, func(db *gorm.DB, revision uint64) error {
+		if err := deleteExpired(db, relation, revision); err != nil {
+			return err
+		}
+		sqlRelation := convertToSqlModel(relation)
+		sqlRelation.CreatedRev = revision
+		return db.Clauses(liveConflict).Create(&sqlRelation).Error
+	})
 }
 
-func (r *RelationRepository) BatchOperation(operations []domain.Operation) error {
-	tx := r.DB.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
+// Delete only marks the relation deleted, snapshot reads of earlier
+// revisions still see it.
+func (r *RelationRepository) Delete(ctx context.Context, relation domain.Relation) error {
+	return r.write(ctx, func(db *gorm.DB, revision uint64) error {
+		return db.Model(&sqldom.Relation{}).
+			Where("all_columns = ? AND deleted_rev IS NULL", concatAttr(relation)).
+			Updates(deletedAt(revision)).Error
+	})
+}
 
-	for _, operation := range operations {
-		switch operation.Type {
-		case domain.CreateOperation:
-			if err := r.Create(operation.Relation); err != nil {
-				tx.Rollback()
+// DeleteByQueries queries inside the deleting transaction, so a lagging
+// replica can not hide a relation from it.
+func (r *RelationRepository) DeleteByQueries(ctx context.Context, queries []domain.Relation) error {
+	return r.WithDeleteTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		operations := set.NewSet[domain.Operation]()
+		for _, query := range queries {
+			relations, err := tx.Query(ctx, query)
+			if err != nil {
 				return err
 			}
-		case domain.DeleteOperation:
-			if err := r.Delete(operation.Relation); err != nil {
-				tx.Rollback()
+			for _, relation := range relations {
+				operations.Add(domain.Operation{
+					Type:     domain.DeleteOperation,
+					Relation: relation,
+				})
+			}
+		}
+		return tx.BatchOperation(ctx, operations.ToSlice())
+	})
+}
+
+func (r *RelationRepository) BatchOperation(ctx context.Context, operations []domain.Operation) error {
+	return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		for _, operation := range operations {
+			var err error
+			switch operation.Type {
+			case domain.CreateOperation:
+				err = tx.Create(ctx, operation.Relation)
+			case domain.DeleteOperation:
+				err = tx.Delete(ctx, operation.Relation)
+			case domain.CreateIfNotExistOperation:
+				err = tx.CreateIfNotExists(ctx, operation.Relation)
+			default:
+				err = errors.New("invalid operation type")
+			}
+			if err != nil {
 				return err
 			}
-		case domain.CreateIfNotExistOperation:
-			if err := r.Create(operation.Relation); err != nil {
-				if err != gorm.ErrDuplicatedKey {
-					tx.Rollback()
+		}
+		return nil
+	})
+}
+
+// WithTransaction uses a savepoint when the repository is already bound to
+// a transaction. On Postgres every transaction first takes the same
+// transaction level advisory lock, which serializes writers of all server
+// replicas sharing the database. SQLite begins transactions with BEGIN
+// IMMEDIATE, which takes the database write lock up front and serializes
+// them already.
+func (r *RelationRepository) WithTransaction(ctx context.Context, fn func(tx sqldom.RelationRepository) error) error {
+	return r.transaction(ctx, true, fn)
+}
+
+// WithDeleteTransaction is WithTransaction without the advisory lock, so on
+// Postgres deletes only wait for each other and the other writers at the
+// allocation of their revision.
+func (r *RelationRepository) WithDeleteTransaction(ctx context.Context, fn func(tx sqldom.RelationRepository) error) error {
+	return r.transaction(ctx, false, fn)
+}
+
+func (r *RelationRepository) transaction(ctx context.Context, lock bool, fn func(tx sqldom.RelationRepository) error) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state := r.tx
+		if state == nil {
+			if lock && tx.Dialector.Name() == "postgres" {
+				if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", graphLockKey).Error; err != nil {
 					return err
 				}
 			}
-		default:
-			tx.Rollback()
-			return errors.New("invalid operation type")
+			state = &transaction{}
+		}
+		// rolling back a savepoint also rolls back the revision allocated
+		// after it
+		revision := state.revision
+		err := fn(&RelationRepository{DB: tx, tx: state})
+		if err != nil {
+			state.revision = revision
 		}
-	}
-
-	if err := tx.Commit().Error; err != nil {
 		return err
-	}
-
-	return nil
+	})
 }
 
-func (r *RelationRepository) GetAll(options ...sqldom.PageOptions) ([]domain.Relation, uint, error) {
+func (r *RelationRepository) GetAll(ctx context.Context, options ...sqldom.PageOptions) ([]domain.Relation, uint, error) {
 	var relations []sqldom.Relation
-	var err error
-	if len(options) > 0 {
-		options := options[0]
-		err = r.DB.Where("id > ?", options.LastID).Order("id").Limit(options.PageSize).Find(&relations).Error
-	} else {
-		err = r.DB.Find(&relations).Error
-	}
+	err := r.read(ctx, func(db *gorm.DB) error {
+		visible, visibleArgs := r.visible("relations")
+		query := db.Where(visible, visibleArgs...)
+		if len(options) > 0 {
+			options := options[0]
+			return query.Where("id > ?", options.LastID).Order("id").Limit(options.PageSize).Find(&relations).Error
+		}
+		return query.Find(&relations).Error
+	})
 	if err != nil {
 		return nil, 0, err
 	}
+	if len(relations) == 0 {
+		return []domain.Relation{}, 0, nil
+	}
 
 	newRelations := make([]domain.Relation, len(relations))
 	for i, relation := range relations {
@@ -104,9 +196,14 @@ func (r *RelationRepository) GetAll(options ...sqldom.PageOptions) ([]domain.Rel
 	return newRelations, relations[len(relations)-1].ID, nil
 }
 
-func (r *RelationRepository) Query(query domain.Relation) ([]domain.Relation, error) {
+func (r *RelationRepository) Query(ctx context.Context, query domain.Relation) ([]domain.Relation, error) {
 	var relations []sqldom.Relation
-	if err := r.DB.Where(&query).Find(&relations).Error; err != nil {
+	err := r.read(ctx, func(db *gorm.DB) error {
+		visible, visibleArgs := r.visible("relations")
+		query := query.Key()
+		return db.Where(&query).Where(visible, visibleArgs...).Find(&relations).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	newRelations := make([]domain.Relation, len(relations))
@@ -116,29 +213,196 @@ func (r *RelationRepository) Query(query domain.Relation) ([]domain.Relation, er
 	return newRelations, nil
 }
 
-func (r *RelationRepository) GetAllNamespaces() ([]string, error) {
+func (r *RelationRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	return r.queryByNodes(ctx, "subject_namespace", "subject_name", "subject_relation", subjects)
+}
+
+func (r *RelationRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	return r.queryByNodes(ctx, "object_namespace", "object_name", "relation", objects)
+}
+
+// queryByNodes matches the given columns against all nodes with row value
+// IN lists, in chunks that stay below the bind parameter limits.
+func (r *RelationRepository) queryByNodes(ctx context.Context, namespaceColumn, nameColumn, relationColumn string, nodes []domain.Node) ([]domain.Relation, error) {
+	newRelations := []domain.Relation{}
+	for start := 0; start < len(nodes); start += queryChunkSize {
+		end := start + queryChunkSize
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		withRelation := [][]interface{}{}
+		withoutRelation := [][]interface{}{}
+		for _, node := range nodes[start:end] {
+			if node.Relation == "" {
+				withoutRelation = append(withoutRelation, []interface{}{node.Namespace, node.Name})
+			} else {
+				withRelation = append(withRelation, []interface{}{node.Namespace, node.Name, node.Relation})
+			}
+		}
+
+		nodeClause := func(db *gorm.DB) *gorm.DB {
+			query := db.Where("1 = 0")
+			if len(withRelation) > 0 {
+				query = query.Or(
+					fmt.Sprintf("(%s, %s, %s) IN ?", namespaceColumn, nameColumn, relationColumn),
+					withRelation,
+				)
+			}
+			if len(withoutRelation) > 0 {
+				query = query.Or(fmt.Sprintf("(%s, %s) IN ?", namespaceColumn, nameColumn), withoutRelation)
+			}
+			return query
+		}
+
+		var relations []sqldom.Relation
+		err := r.read(ctx, func(db *gorm.DB) error {
+			visible, visibleArgs := r.visible("relations")
+			return db.Where(visible, visibleArgs...).Where(nodeClause(db)).Find(&relations).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			newRelations = append(newRelations, convertToRelation(relation))
+		}
+		if r.importID != "" {
+			staged, err := r.queryImport(ctx, nodeClause(r.DB))
+			if err != nil {
+				return nil, err
+			}
+			newRelations = append(newRelations, staged...)
+		}
+	}
+	return newRelations, nil
+}
+
+func (r *RelationRepository) GetAllNamespaces(ctx context.Context) ([]string, error) {
+	visible, visibleArgs := r.visible("relations")
 	sqlQuery := `
 		SELECT DISTINCT namespace
 		FROM (
-			SELECT object_namespace AS namespace FROM relations
+			SELECT object_namespace AS namespace FROM relations WHERE ` + visible + `
 			UNION
-			SELECT subject_namespace AS namespace FROM relations
+			SELECT subject_namespace AS namespace FROM relations WHERE ` + visible + `
 		) AS namespaces
 	`
 	var namespaces []string
-	if err := r.DB.Raw(sqlQuery).Scan(&namespaces).Error; err != nil {
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Raw(sqlQuery, append(visibleArgs, visibleArgs...)...).Scan(&namespaces).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return namespaces, nil
 }
 
-func (r *RelationRepository) DeleteAll() error {
-	query := "DELETE FROM relations"
-	if err := r.DB.Exec(query).Error; err != nil {
+func (r *RelationRepository) DeleteAll(ctx context.Context) error {
+	return r.write(ctx, func(db *gorm.DB, revision uint64) error {
+		return db.Model(&sqldom.Relation{}).Where("deleted_rev IS NULL").Updates(deletedAt(revision)).Error
+	})
+}
+
+// Primary returns the repository reading from the primary, for reads that
+// must see the writes made before them.
+func (r *RelationRepository) Primary() sqldom.RelationRepository {
+	return NewRelationRepository(r.DB)
+}
+
+// write runs fn inside a transaction with the revision the transaction
+// commits as. The first write of a transaction allocates it, the row of
+// graph_revision stays locked until the commit, so revisions become visible
+// in order.
+func (r *RelationRepository) write(ctx context.Context, fn func(db *gorm.DB, revision uint64) error) error {
+	if r.tx == nil {
+		return r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+			return tx.(*RelationRepository).write(ctx, fn)
+		})
+	}
+	db := r.DB.WithContext(ctx)
+	if r.tx.revision == 0 {
+		var revision uint64
+		err := db.Raw("UPDATE graph_revision SET revision = revision + 1 WHERE id = 1 RETURNING revision").
+			Scan(&revision).Error
+		if err != nil {
+			return err
+		}
+		if err := db.Create(&graphRevisionTime{Revision: revision, CommittedAt: time.Now().UTC()}).Error; err != nil {
+			return err
+		}
+		r.tx.revision = revision
+	}
+	return fn(db, r.tx.revision)
+}
+
+// visible returns the condition on the relations of table the repository
+// reads: the live ones, or those of its snapshot, that have not expired yet.
+// A snapshot takes expiry at the time of its revision, or of the read if the
+// revision has no time recorded.
+func (r *RelationRepository) visible(table string) (string, []interface{}) {
+	unexpired := "(" + table + ".expires_at IS NULL OR " + table + ".expires_at > ?)"
+	now := time.Now().UTC()
+	if !r.snapshotAt.IsZero() {
+		now = r.snapshotAt
+	}
+	if r.snapshot == nil {
+		return table + ".deleted_rev IS NULL AND " + unexpired, []interface{}{now}
+	}
+	return table + ".created_rev <= ? AND (" + table + ".deleted_rev IS NULL OR " + table + ".deleted_rev > ?) AND " + unexpired,
+		[]interface{}{*r.snapshot, *r.snapshot, now}
+}
+
+// deleteExpired deletes relation if it expired, so it can be created again.
+func deleteExpired(db *gorm.DB, relation domain.Relation, revision uint64) error {
+	return db.Model(&sqldom.Relation{}).
+		Where("all_columns = ? AND deleted_rev IS NULL AND expires_at <= ?", concatAttr(relation), time.Now().UTC()).
+		Updates(deletedAt(revision)).Error
+}
+
+// QueryExpired reads the partial index of the relations with an expiry.
+func (r *RelationRepository) QueryExpired(ctx context.Context, now time.Time, limit int) ([]domain.Relation, error) {
+	var relations []sqldom.Relation
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Where("deleted_rev IS NULL AND expires_at <= ?", now.UTC()).
+			Order("expires_at").Limit(limit).Find(&relations).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	expired := make([]domain.Relation, len(relations))
+	for i, relation := range relations {
+		expired[i] = convertToRelation(relation)
+	}
+	return expired, nil
+}
+
+// liveConflict skips the insert of a relation that exists and is not
+// deleted, the target of the partial unique index on all_columns.
+var liveConflict = clause.OnConflict{
+	Columns:     []clause.Column{{Name: "all_columns"}},
+	TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_rev IS NULL"}}},
+	DoNothing:   true,
+}
+
+func deletedAt(revision uint64) map[string]interface{} {
+	return map[string]interface{}{"deleted_rev": revision, "deleted_at": time.Now()}
+}
+
+// read runs fn on a replica, or on the primary if there are no replicas. If
+// fn fails on a replica, the replica is checked and fn retried on the
+// primary, so a replica going down costs the reads in flight nothing. A read
+// failing because ctx is done is not retried.
+func (r *RelationRepository) read(ctx context.Context, fn func(db *gorm.DB) error) error {
+	if r.Replicas == nil {
+		return fn(r.DB.WithContext(ctx))
+	}
+	db := r.Replicas.Reader()
+	err := fn(db.WithContext(ctx))
+	if err == nil || db == r.DB || ctx.Err() != nil {
 		return err
 	}
-	return nil
+	r.Replicas.MarkFailed(db)
+	return fn(r.DB.WithContext(ctx))
 }
 
 func convertToSqlModel(relation domain.Relation) sqldom.Relation {
@@ -149,10 +413,21 @@ func convertToSqlModel(relation domain.Relation) sqldom.Relation {
 		SubjectNamespace: relation.SubjectNamespace,
 		SubjectName:      relation.SubjectName,
 		SubjectRelation:  relation.SubjectRelation,
+		Condition:        relation.Condition,
+		ConditionParams:  string(relation.ConditionParams),
+		ExpiresAt:        expiresAt(relation),
 		AllColumns:       concatAttr(relation),
 	}
 }
 
+func expiresAt(relation domain.Relation) *time.Time {
+	if relation.ExpiresAt == nil {
+		return nil
+	}
+	expiresAt := relation.ExpiresAt.UTC()
+	return &expiresAt
+}
+
 func concatAttr(relation domain.Relation) string {
 	return strings.Join(
 		[]string{
@@ -168,12 +443,19 @@ func concatAttr(relation domain.Relation) string {
 }
 
 func convertToRelation(relation sqldom.Relation) domain.Relation {
-	return domain.Relation{
+	newRelation := domain.Relation{
 		ObjectNamespace:  relation.ObjectNamespace,
 		ObjectName:       relation.ObjectName,
 		Relation:         relation.Relation,
 		SubjectNamespace: relation.SubjectNamespace,
 		SubjectName:      relation.SubjectName,
 		SubjectRelation:  relation.SubjectRelation,
+		Condition:        relation.Condition,
+		ConditionParams:  domain.ConditionParams(relation.ConditionParams),
+	}
+	if relation.ExpiresAt != nil {
+		expiresAt := relation.ExpiresAt.UTC()
+		newRelation.ExpiresAt = &expiresAt
 	}
+	return newRelation
 }
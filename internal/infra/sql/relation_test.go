@@ -0,0 +1,381 @@
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/repotest"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"github.com/spf13/viper"
+)
+
+func newSqliteRepo(t *testing.T) *sql.RelationRepository {
+	ormRepo, err := sql.NewOrmRepository(newSqliteDb(t))
+	if err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	return &ormRepo.RelationshipRepo
+}
+
+func TestSqliteRelationRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+
+	rel := domain.Relation{
+		ObjectNamespace:  "doc",
+		ObjectName:       "1",
+		Relation:         "viewer",
+		SubjectNamespace: "user",
+		SubjectName:      "alice",
+	}
+	if err := repo.Create(ctx, rel); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	relations, err := repo.Query(ctx, domain.Relation{SubjectNamespace: "user", SubjectName: "alice"})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(relations) != 1 || relations[0] != rel {
+		t.Errorf("Query = %v, want [%v]", relations, rel)
+	}
+
+	namespaces, err := repo.GetAllNamespaces(ctx)
+	if err != nil {
+		t.Fatalf("GetAllNamespaces: %v", err)
+	}
+	if len(namespaces) != 2 {
+		t.Errorf("GetAllNamespaces = %v, want doc and user", namespaces)
+	}
+
+	bob := rel
+	bob.SubjectName = "bob"
+	err = repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateOperation, Relation: bob},
+		{Type: domain.DeleteOperation, Relation: rel},
+	})
+	if err != nil {
+		t.Fatalf("BatchOperation: %v", err)
+	}
+	relations, _, err = repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(relations) != 1 || relations[0] != bob {
+		t.Errorf("GetAll = %v, want [%v]", relations, bob)
+	}
+
+	if err := repo.DeleteAll(ctx); err != nil {
+		t.Fatalf("DeleteAll: %v", err)
+	}
+	relations, _, err = repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(relations) != 0 {
+		t.Errorf("GetAll after DeleteAll = %v, want none", relations)
+	}
+}
+
+func TestSqliteReachability(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	// user:alice -> group:eng#member -> group:all#member -> doc:1#viewer
+	//            -> folder:a#viewer --------------------> doc:1#viewer
+	relations := []domain.Relation{
+		{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "group", ObjectName: "all", Relation: "member", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "all", SubjectRelation: "member"},
+		{ObjectNamespace: "folder", ObjectName: "a", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "folder", SubjectName: "a", SubjectRelation: "viewer"},
+	}
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+
+	tests := []struct {
+		name      string
+		condition domain.SearchCondition
+		maxDepth  int
+		want      []domain.Relation
+	}{
+		{name: "unlimited", want: []domain.Relation{relations[3], relations[4]}},
+		{name: "depth limit", maxDepth: 1},
+		{
+			name:      "search condition",
+			condition: domain.SearchCondition{In: domain.Compare{Namespaces: []string{"group"}}},
+			maxDepth:  3,
+			want:      []domain.Relation{relations[0], relations[1], relations[2]},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := repo.Reachable(ctx, alice, doc, tt.condition, tt.maxDepth)
+			if err != nil {
+				t.Fatalf("Reachable: %v", err)
+			}
+			if ok != (tt.want != nil) {
+				t.Errorf("Reachable = %v, want %v", ok, tt.want != nil)
+			}
+			result, err := repo.Search(ctx, alice, doc, tt.condition, tt.maxDepth, true)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			path := result.Path
+			if len(path) != len(tt.want) {
+				t.Fatalf("Search path = %v, want %v", path, tt.want)
+			}
+			for i := range path {
+				if path[i] != tt.want[i] {
+					t.Errorf("Search path = %v, want %v", path, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSqliteSearchReportsConditions(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	// user:alice -> group:eng#member -> doc:1#viewer
+	// user:bob -[condition]-> group:ops#member -> doc:1#viewer
+	// user:carol -[expiry]-> doc:1#viewer
+	later := time.Now().Add(time.Hour).UTC()
+	relations := []domain.Relation{
+		{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+		{ObjectNamespace: "group", ObjectName: "ops", Relation: "member", SubjectNamespace: "user", SubjectName: "bob", Condition: "on_call"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "ops", SubjectRelation: "member"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "carol", ExpiresAt: &later},
+	}
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+
+	for _, tt := range []struct {
+		subject string
+		want    sqldom.SearchResult
+	}{
+		{subject: "alice", want: sqldom.SearchResult{Found: true}},
+		{subject: "bob", want: sqldom.SearchResult{Found: true, Conditional: true}},
+		{subject: "carol", want: sqldom.SearchResult{Found: true, Expiring: true}},
+	} {
+		result, err := repo.Search(ctx, domain.Node{Namespace: "user", Name: tt.subject}, doc, domain.SearchCondition{}, 0, false)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if result.Found != tt.want.Found || result.Conditional != tt.want.Conditional || result.Expiring != tt.want.Expiring {
+			t.Errorf("Search(%s) = %+v, want %+v", tt.subject, result, tt.want)
+		}
+	}
+}
+
+func TestSqliteShortestPathOnLattice(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	repo := newSqliteRepo(t)
+	// user:alice reaches every node of the first layer, and every node of a
+	// layer reaches every node of the next, so there are 8^12 paths to the
+	// doc of the last layer
+	const layers, width = 12, 8
+	node := func(layer, i int) (string, string) {
+		return "group", fmt.Sprint(layer, "-", i)
+	}
+	for i := 0; i < width; i++ {
+		namespace, name := node(0, i)
+		relation := domain.Relation{ObjectNamespace: namespace, ObjectName: name, Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	for layer := 1; layer < layers; layer++ {
+		for i := 0; i < width; i++ {
+			for j := 0; j < width; j++ {
+				subjectNamespace, subjectName := node(layer-1, i)
+				namespace, name := node(layer, j)
+				relation := domain.Relation{
+					ObjectNamespace: namespace, ObjectName: name, Relation: "member",
+					SubjectNamespace: subjectNamespace, SubjectName: subjectName, SubjectRelation: "member",
+				}
+				if err := repo.Create(ctx, relation); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+		}
+	}
+	for i := 0; i < width; i++ {
+		namespace, name := node(layers-1, i)
+		relation := domain.Relation{
+			ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer",
+			SubjectNamespace: namespace, SubjectName: name, SubjectRelation: "member",
+		}
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	for _, maxDepth := range []int{0, layers + 1} {
+		result, err := repo.Search(ctx, alice, doc, domain.SearchCondition{}, maxDepth, true)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		path := result.Path
+		if len(path) != layers+1 {
+			t.Fatalf("Search path = %v, want %d relations", path, layers+1)
+		}
+		for i := 1; i < len(path); i++ {
+			if path[i].SubjectName != path[i-1].ObjectName {
+				t.Errorf("Search path = %v, want a path", path)
+			}
+		}
+	}
+}
+
+func TestSqliteQueryByNodes(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	relations := []domain.Relation{
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+		{ObjectNamespace: "doc", ObjectName: "3", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "admin"},
+	}
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	found, err := repo.QueryBySubjects(ctx, []domain.Node{
+		{Namespace: "user", Name: "alice"},
+		{Namespace: "group", Name: "eng", Relation: "member"},
+	})
+	if err != nil {
+		t.Fatalf("QueryBySubjects: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("QueryBySubjects = %v, want the first two relations", found)
+	}
+
+	found, err = repo.QueryByObjects(ctx, []domain.Node{
+		{Namespace: "doc", Name: "2", Relation: "viewer"},
+		{Namespace: "doc", Name: "3"},
+	})
+	if err != nil {
+		t.Fatalf("QueryByObjects: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("QueryByObjects = %v, want the last two relations", found)
+	}
+}
+
+func TestSqliteUniqueRelations(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	rel := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"}
+	if err := repo.Create(ctx, rel); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(ctx, rel); err != (domain.AlreadyExistsError{}) {
+		t.Errorf("Create duplicate = %v, want AlreadyExistsError", err)
+	}
+	if err := repo.CreateIfNotExists(ctx, rel); err != nil {
+		t.Errorf("CreateIfNotExists: %v", err)
+	}
+	err := repo.BatchOperation(ctx, []domain.Operation{
+		{Type: domain.CreateIfNotExistOperation, Relation: rel},
+	})
+	if err != nil {
+		t.Errorf("BatchOperation: %v", err)
+	}
+
+	relations, _, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(relations) != 1 {
+		t.Errorf("GetAll = %v, want a single relation", relations)
+	}
+}
+
+func TestRelationRepository(t *testing.T) {
+	dbTypes := []string{"sqlite"}
+	if os.Getenv("TEST_POSTGRES") != "" {
+		dbTypes = append(dbTypes, "postgres")
+	}
+	for _, dbType := range dbTypes {
+		t.Run(dbType, func(t *testing.T) {
+			repotest.TestRelationRepository(t, func(t *testing.T) sqldom.RelationRepository {
+				if dbType == "sqlite" {
+					return newSqliteRepo(t)
+				}
+				return newPostgresRepo(t)
+			})
+		})
+	}
+}
+
+// newPostgresRepo returns an emptied repository on the database configured
+// for postgres.
+func newPostgresRepo(t *testing.T) *sql.RelationRepository {
+	viper.Set("db.type", "postgres")
+	db, err := sql.InitDb()
+	if err != nil {
+		t.Fatalf("InitDb: %v", err)
+	}
+	ormRepo, err := sql.NewOrmRepository(db)
+	if err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	if err := ormRepo.RelationshipRepo.DeleteAll(context.Background()); err != nil {
+		t.Fatalf("DeleteAll: %v", err)
+	}
+	return &ormRepo.RelationshipRepo
+}
+
+func TestPostgresDeletesDoNotWaitForWriters(t *testing.T) {
+	if os.Getenv("TEST_POSTGRES") == "" {
+		t.Skip("TEST_POSTGRES is not set")
+	}
+	ctx := context.Background()
+	repo := newPostgresRepo(t)
+	relation := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"}
+	if err := repo.Create(ctx, relation); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	locked, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- repo.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+	deadline, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err := repo.WithDeleteTransaction(deadline, func(tx sqldom.RelationRepository) error {
+		return tx.Delete(deadline, relation)
+	})
+	close(release)
+	if err != nil {
+		t.Errorf("WithDeleteTransaction while a writer holds the lock: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("WithTransaction: %v", err)
+	}
+}
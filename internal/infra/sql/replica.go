@@ -0,0 +1,122 @@
+package sql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pingTimeout bounds a single replica health check.
+const pingTimeout = 2 * time.Second
+
+// ReplicaPool hands out the read replicas of a primary round robin. Replicas
+// failing their health check are skipped until they pass again, and reads
+// fall back to the primary while none is healthy.
+type ReplicaPool struct {
+	Primary  *gorm.DB
+	replicas []*replica
+	next     atomic.Uint64
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type replica struct {
+	db      *gorm.DB
+	healthy atomic.Bool
+}
+
+// NewReplicaPool checks every replica once, so an unreachable one is not
+// handed out before the first periodic check.
+func NewReplicaPool(primary *gorm.DB, replicas []*gorm.DB) *ReplicaPool {
+	p := &ReplicaPool{Primary: primary, stop: make(chan struct{})}
+	for _, db := range replicas {
+		p.replicas = append(p.replicas, &replica{db: db})
+	}
+	p.CheckHealth()
+	return p
+}
+
+// Reader returns the next healthy replica, or the primary if there is none.
+func (p *ReplicaPool) Reader() *gorm.DB {
+	n := len(p.replicas)
+	start := p.next.Add(1)
+	for i := 0; i < n; i++ {
+		replica := p.replicas[(start+uint64(i))%uint64(n)]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+	return p.Primary
+}
+
+// Readers returns the healthy replicas, in the order Reader would hand them
+// out.
+func (p *ReplicaPool) Readers() []*gorm.DB {
+	n := len(p.replicas)
+	start := p.next.Add(1)
+	readers := []*gorm.DB{}
+	for i := 0; i < n; i++ {
+		replica := p.replicas[(start+uint64(i))%uint64(n)]
+		if replica.healthy.Load() {
+			readers = append(readers, replica.db)
+		}
+	}
+	return readers
+}
+
+// CheckHealth pings every replica and marks it healthy if it answers.
+func (p *ReplicaPool) CheckHealth() {
+	var wg sync.WaitGroup
+	for _, r := range p.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			r.healthy.Store(ping(r.db))
+		}(r)
+	}
+	wg.Wait()
+}
+
+// MarkFailed takes db out of rotation if it no longer answers, instead of
+// waiting for the next periodic check. It is called after a read on db
+// failed, which may as well have been the fault of the query.
+func (p *ReplicaPool) MarkFailed(db *gorm.DB) {
+	for _, replica := range p.replicas {
+		if replica.db == db {
+			replica.healthy.Store(ping(db))
+		}
+	}
+}
+
+// Start checks the health of the replicas every interval until Stop.
+func (p *ReplicaPool) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.CheckHealth()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (p *ReplicaPool) Stop() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func ping(db *gorm.DB) bool {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return sqlDB.PingContext(ctx) == nil
+}
@@ -0,0 +1,52 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"gorm.io/gorm"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	ctx := context.Background()
+	primary := newSqliteDb(t)
+	ormRepo, err := sql.NewOrmRepository(primary)
+	if err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	// the replica is a separate, empty database, so reads show where they went
+	replica := newSqliteDb(t)
+	if _, err := sql.NewOrmRepository(replica); err != nil {
+		t.Fatalf("NewOrmRepository: %v", err)
+	}
+	pool := sql.NewReplicaPool(primary, []*gorm.DB{replica})
+	defer pool.Stop()
+	repo := &ormRepo.RelationshipRepo
+	repo.Replicas = pool
+
+	rel := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice"}
+	if err := repo.Create(ctx, rel); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	query := domain.Relation{ObjectNamespace: "doc", ObjectName: "1"}
+
+	if relations, _ := repo.Query(ctx, query); len(relations) != 0 {
+		t.Errorf("Query = %v, want the read served by the empty replica", relations)
+	}
+	if relations, _ := repo.Primary().Query(ctx, query); len(relations) != 1 {
+		t.Errorf("Primary().Query = %v, want the relation written to the primary", relations)
+	}
+
+	// a read failing on a dead replica is retried on the primary, which
+	// serves the reads from then on
+	sqlDB, _ := replica.DB()
+	sqlDB.Close()
+	if relations, err := repo.Query(ctx, query); err != nil || len(relations) != 1 {
+		t.Errorf("Query after replica failure = %v, %v, want the primary to answer", relations, err)
+	}
+	if pool.Reader() != primary {
+		t.Error("Reader still returns the failed replica")
+	}
+}
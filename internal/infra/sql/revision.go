@@ -0,0 +1,133 @@
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+
+	"gorm.io/gorm"
+)
+
+type graphRevision struct {
+	ID          uint
+	Revision    uint64
+	MinRevision uint64
+}
+
+func (graphRevision) TableName() string {
+	return "graph_revision"
+}
+
+// graphRevisionTime is the time a revision was written at.
+type graphRevisionTime struct {
+	Revision    uint64 `gorm:"primaryKey;autoIncrement:false"`
+	CommittedAt time.Time
+}
+
+func (graphRevisionTime) TableName() string {
+	return "graph_revision_times"
+}
+
+func (r *RelationRepository) Revision(ctx context.Context) (uint64, error) {
+	if r.tx != nil && r.tx.revision != 0 {
+		return r.tx.revision, nil
+	}
+	if r.snapshot != nil {
+		return *r.snapshot, nil
+	}
+	state, err := readRevision(r.DB.WithContext(ctx))
+	return state.Revision, err
+}
+
+// AtLeast prefers a replica that replayed revision already, and falls back to
+// the primary, which has every committed revision.
+func (r *RelationRepository) AtLeast(ctx context.Context, revision uint64) (sqldom.RelationRepository, error) {
+	if r.Replicas == nil {
+		return r, nil
+	}
+	for _, db := range r.Replicas.Readers() {
+		state, err := readRevision(db.WithContext(ctx))
+		if err != nil && ctx.Err() != nil {
+			return nil, err
+		}
+		if err != nil {
+			r.Replicas.MarkFailed(db)
+			continue
+		}
+		if state.Revision >= revision {
+			return NewRelationRepository(db), nil
+		}
+	}
+	return NewRelationRepository(r.DB), nil
+}
+
+// AtRevision reads from a replica that replayed revision if there is one, the
+// rows of a snapshot are the same on every database that has it.
+func (r *RelationRepository) AtRevision(ctx context.Context, revision uint64) (sqldom.RelationRepository, error) {
+	primary, err := readRevision(r.DB.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if revision > primary.Revision {
+		return nil, domain.InvalidTokenError{}
+	}
+	if revision < primary.MinRevision {
+		return nil, domain.SnapshotUnavailableError{}
+	}
+	var times []graphRevisionTime
+	if err := r.DB.WithContext(ctx).Where("revision = ?", revision).Limit(1).Find(&times).Error; err != nil {
+		return nil, err
+	}
+	repo, err := r.AtLeast(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &RelationRepository{DB: repo.(*RelationRepository).DB, snapshot: &revision}
+	if len(times) > 0 {
+		snapshot.snapshotAt = times[0].CommittedAt.UTC()
+	}
+	return snapshot, nil
+}
+
+// CollectGarbage removes the relations deleted longer than retention ago,
+// and with them the snapshots that still saw them. The live graph does not
+// change, so it runs without the lock of WithTransaction.
+func (r *RelationRepository) CollectGarbage(ctx context.Context, retention time.Duration) error {
+	return r.WithDeleteTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		db := tx.(*RelationRepository).DB
+		var horizon *uint64
+		err := db.Model(&sqldom.Relation{}).
+			Where("deleted_rev IS NOT NULL AND deleted_at < ?", time.Now().Add(-retention)).
+			Select("MAX(deleted_rev)").Scan(&horizon).Error
+		if err != nil || horizon == nil {
+			return err
+		}
+		if err := db.Where("deleted_rev <= ?", *horizon).Delete(&sqldom.Relation{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("revision < ?", *horizon).Delete(&graphRevisionTime{}).Error; err != nil {
+			return err
+		}
+		return db.Model(&graphRevision{}).Where("id = 1 AND min_revision < ?", *horizon).
+			Update("min_revision", *horizon).Error
+	})
+}
+
+// StartGarbageCollector runs CollectGarbage every interval.
+func (r *RelationRepository) StartGarbageCollector(retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			r.CollectGarbage(context.Background(), retention)
+		}
+	}()
+}
+
+func readRevision(db *gorm.DB) (graphRevision, error) {
+	var state graphRevision
+	err := db.Where("id = 1").Take(&state).Error
+	return state, err
+}
@@ -0,0 +1,121 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+func TestSnapshots(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	user := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	member := domain.Relation{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+	viewer := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"}
+
+	if err := repo.Create(ctx, member); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.Create(ctx, viewer); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	granted, _ := repo.Revision(ctx)
+	if err := repo.Delete(ctx, member); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	revoked, _ := repo.Revision(ctx)
+
+	if ok, _ := repo.Reachable(ctx, user, doc, domain.SearchCondition{}, 0); ok {
+		t.Error("Reachable after delete = true")
+	}
+	snapshot, err := repo.AtRevision(ctx, granted)
+	if err != nil {
+		t.Fatalf("AtRevision(%d): %v", granted, err)
+	}
+	if ok, err := snapshot.(sqldom.ReachabilityRepository).Reachable(ctx, user, doc, domain.SearchCondition{}, 0); !ok || err != nil {
+		t.Errorf("Reachable at revision %d = %v, %v, want true", granted, ok, err)
+	}
+	if relations, _ := snapshot.Query(ctx, member); len(relations) != 1 {
+		t.Errorf("Query at revision %d = %v, want the deleted relation", granted, relations)
+	}
+	if relations, _, _ := repo.GetAll(ctx); len(relations) != 1 {
+		t.Errorf("GetAll = %v, want only the live relation", relations)
+	}
+
+	if _, err := repo.AtRevision(ctx, revoked+1); err != (domain.InvalidTokenError{}) {
+		t.Errorf("AtRevision of a future revision = %v, want InvalidTokenError", err)
+	}
+
+	// collecting with no retention drops the history of the delete
+	if err := repo.CollectGarbage(ctx, 0); err != nil {
+		t.Fatalf("CollectGarbage: %v", err)
+	}
+	if _, err := repo.AtRevision(ctx, granted); err != (domain.SnapshotUnavailableError{}) {
+		t.Errorf("AtRevision after collection = %v, want SnapshotUnavailableError", err)
+	}
+	if _, err := repo.AtRevision(ctx, revoked); err != nil {
+		t.Errorf("AtRevision(%d) after collection: %v", revoked, err)
+	}
+	if err := repo.CollectGarbage(ctx, time.Hour); err != nil {
+		t.Fatalf("CollectGarbage: %v", err)
+	}
+}
+
+func TestSnapshotsTakeExpiryAtTheirRevision(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	expiresAt := time.Now().Add(200 * time.Millisecond)
+	expiring := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice",
+		ExpiresAt: &expiresAt}
+	if err := repo.Create(ctx, expiring); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	granted, _ := repo.Revision(ctx)
+	time.Sleep(time.Until(expiresAt))
+
+	if relations, _ := repo.Query(ctx, expiring); len(relations) != 0 {
+		t.Errorf("Query = %v, want the relation expired", relations)
+	}
+	snapshot, err := repo.AtRevision(ctx, granted)
+	if err != nil {
+		t.Fatalf("AtRevision(%d): %v", granted, err)
+	}
+	if relations, _ := snapshot.Query(ctx, expiring); len(relations) != 1 {
+		t.Errorf("Query at revision %d = %v, want the relation not expired yet", granted, relations)
+	}
+	user := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	if ok, err := snapshot.(sqldom.ReachabilityRepository).Reachable(ctx, user, doc, domain.SearchCondition{}, 0); !ok || err != nil {
+		t.Errorf("Reachable at revision %d = %v, %v, want true", granted, ok, err)
+	}
+}
+
+func TestSweptRelationsAreCollected(t *testing.T) {
+	ctx := context.Background()
+	repo := newSqliteRepo(t)
+	earlier := time.Now().Add(-time.Minute)
+	expired := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice",
+		ExpiresAt: &earlier}
+	if err := repo.Create(ctx, expired); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// the sweeper deletes what QueryExpired finds like any delete
+	swept, err := repo.QueryExpired(ctx, time.Now(), 10)
+	if err != nil || len(swept) != 1 {
+		t.Fatalf("QueryExpired = %v, %v, want the expired relation", swept, err)
+	}
+	if err := repo.Delete(ctx, swept[0]); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := repo.CollectGarbage(ctx, 0); err != nil {
+		t.Fatalf("CollectGarbage: %v", err)
+	}
+	var rows int64
+	if err := repo.DB.Model(&sqldom.Relation{}).Count(&rows).Error; err != nil || rows != 0 {
+		t.Errorf("rows after collection = %d, %v, want 0", rows, err)
+	}
+}
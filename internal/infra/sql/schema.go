@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+
+	"gorm.io/gorm"
+)
+
+type namespaceSchema struct {
+	Version uint64 `gorm:"primaryKey;autoIncrement:false"`
+	// Namespaces are the namespace definitions as JSON.
+	Namespaces string
+	CreatedAt  time.Time
+}
+
+func (namespaceSchema) TableName() string {
+	return "namespace_schemas"
+}
+
+// ReadSchema reads from the primary, so a schema is in effect as soon as it
+// is written.
+func (r *RelationRepository) ReadSchema(ctx context.Context) (domain.Schema, error) {
+	schema := domain.Schema{Namespaces: []domain.NamespaceDefinition{}}
+	row := namespaceSchema{}
+	err := r.DB.WithContext(ctx).Order("version DESC").Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return schema, nil
+	}
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	schema.Version = row.Version
+	if err := json.Unmarshal([]byte(row.Namespaces), &schema.Namespaces); err != nil {
+		return domain.Schema{}, err
+	}
+	return schema, nil
+}
+
+// WriteSchema does not advance the revision, the schema is versioned on its
+// own. Transactions are serialized, so versions are taken in order.
+func (r *RelationRepository) WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error) {
+	definition, err := json.Marshal(namespaces)
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	schema := domain.Schema{Namespaces: namespaces}
+	err = r.WithTransaction(ctx, func(tx sqldom.RelationRepository) error {
+		db := tx.(*RelationRepository).DB.WithContext(ctx)
+		var latest uint64
+		if err := db.Model(&namespaceSchema{}).Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		schema.Version = latest + 1
+		return db.Create(&namespaceSchema{
+			Version:    schema.Version,
+			Namespaces: string(definition),
+			CreatedAt:  time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	return schema, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+	"github.com/spf13/viper"
+)
+
+// budget returns the budget of a traversal, the server defaults overridden by
+// the options of the request.
+func budget(options []usecasedom.ReadOptions) domain.Budget {
+	b := domain.Budget{
+		MaxDepth: viper.GetInt("main.max-search-depth"),
+		MaxNodes: viper.GetInt("main.max-visited-nodes"),
+		MaxPaths: viper.GetInt("main.max-paths"),
+		Timeout:  viper.GetDuration("main.max-search-time"),
+	}
+	if len(options) > 0 {
+		b = b.Override(options[0].Budget)
+	}
+	return b
+}
+
+// withTimeout bounds ctx by the time budget of b.
+func withTimeout(ctx context.Context, b domain.Budget) (context.Context, context.CancelFunc) {
+	if b.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.Timeout)
+}
+
+// outOfTime reports whether a traversal running under budgeted, derived from
+// ctx by withTimeout, was stopped by its time budget rather than by the end
+// of its request.
+func outOfTime(ctx, budgeted context.Context) bool {
+	return budgeted.Err() == context.DeadlineExceeded && ctx.Err() == nil
+}
+
+// budgetError returns domain.BudgetExceededError in place of err if the
+// traversal failed because it ran out of time.
+func budgetError(ctx, budgeted context.Context, err error) error {
+	if err != nil && outOfTime(ctx, budgeted) {
+		return domain.BudgetExceededError{Limit: domain.TimeBudget}
+	}
+	return err
+}
+
+// visits counts the nodes a traversal visits against its node budget.
+type visits struct {
+	max   int
+	count int
+}
+
+// add counts n more visited nodes and fails once they exceed the budget.
+func (v *visits) add(n int) error {
+	v.count += n
+	if v.max > 0 && v.count > v.max {
+		return domain.BudgetExceededError{Limit: domain.NodeBudget}
+	}
+	return nil
+}
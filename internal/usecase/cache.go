@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+	"github.com/spf13/viper"
+)
+
+// Cache holds the results of Check and the adjacency lookups of the
+// traversals, for reads of the latest graph. Writes through the usecase
+// invalidate the entries they affect, writes of other server replicas are
+// only seen once the entries expire.
+type Cache struct {
+	lock sync.Mutex
+	// generation advances with every invalidation. A result computed across
+	// one may be stale already, so it is not put.
+	generation uint64
+	checks     *lru[checkKey, bool]
+	// checksBySubject indexes the cached checks by the entity of their
+	// subject, a node without relation.
+	checksBySubject map[domain.Node]map[checkKey]struct{}
+	lookups         *lru[lookupKey, []domain.Relation]
+}
+
+type checkKey struct {
+	subject         domain.Node
+	object          domain.Node
+	searchCondition string
+	// maxDepth is part of the key, a check may only fail for lack of depth
+	maxDepth int
+	// schema is the version of the schema the check was computed under
+	schema uint64
+}
+
+// lookupKey is a node and the direction of its relations: the ones leaving
+// it, or with reverse the ones pointing to it.
+type lookupKey struct {
+	node    domain.Node
+	reverse bool
+}
+
+// NewCache returns a cache of up to size checks and size lookups, each kept
+// for at most ttl.
+func NewCache(size int, ttl time.Duration) *Cache {
+	c := &Cache{checksBySubject: map[domain.Node]map[checkKey]struct{}{}}
+	c.checks = newLru[checkKey, bool](size, ttl, func(key checkKey) {
+		entity := entityOf(key.subject)
+		delete(c.checksBySubject[entity], key)
+		if len(c.checksBySubject[entity]) == 0 {
+			delete(c.checksBySubject, entity)
+		}
+	})
+	c.lookups = newLru[lookupKey, []domain.Relation](size, ttl, nil)
+	return c
+}
+
+// newCacheFromConfig returns the cache configured by main.cache, or nil if
+// it is disabled.
+func newCacheFromConfig() *Cache {
+	size := viper.GetInt("main.cache.size")
+	if size <= 0 {
+		return nil
+	}
+	ttl := viper.GetDuration("main.cache.ttl")
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	return NewCache(size, ttl)
+}
+
+func (c *Cache) Stats() domain.CacheStats {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return domain.CacheStats{
+		Checks:  c.checks.stats(),
+		Lookups: c.lookups.stats(),
+	}
+}
+
+func newCheckKey(subject, object domain.Node, searchCondition domain.SearchCondition, maxDepth int, rules *rewriteRules) checkKey {
+	key := checkKey{subject: subject, object: object, searchCondition: fmt.Sprintf("%v", searchCondition), maxDepth: maxDepth}
+	if rules != nil {
+		key.schema = rules.version
+	}
+	return key
+}
+
+func (c *Cache) getCheck(key checkKey) (ok bool, found bool, generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	ok, found = c.checks.get(key)
+	return ok, found, c.generation
+}
+
+func (c *Cache) putCheck(generation uint64, key checkKey, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.checks.put(key, ok)
+	entity := entityOf(key.subject)
+	if c.checksBySubject[entity] == nil {
+		c.checksBySubject[entity] = map[checkKey]struct{}{}
+	}
+	c.checksBySubject[entity][key] = struct{}{}
+}
+
+// invalidate removes the entries the written relations may have changed.
+// The relations of a node are changed by the relations leaving or entering
+// it. A check is changed only if its subject reaches the subject of a
+// written relation, those subjects are found with one query per level of
+// the graph above the written relations. The relations computed by the
+// schema stay within an entity or follow a stored relation, so the stored
+// relations reach the same entities. The search runs under the node and time
+// budget of a traversal, past it every check is dropped instead.
+func (c *Cache) invalidate(ctx context.Context, repo sqldomain.RelationRepository, relations []domain.Relation, b domain.Budget) {
+	c.lock.Lock()
+	c.generation++
+	for _, relation := range relations {
+		subject, object := subjectOf(relation), objectOf(relation)
+		for _, node := range []domain.Node{subject, entityOf(subject)} {
+			c.lookups.invalidate(lookupKey{node: node})
+		}
+		for _, node := range []domain.Node{object, entityOf(object)} {
+			c.lookups.invalidate(lookupKey{node: node, reverse: true})
+		}
+	}
+	noChecks := c.checks.order.Len() == 0
+	c.lock.Unlock()
+	if noChecks || len(relations) == 0 {
+		return
+	}
+
+	frontier := make([]domain.Node, len(relations))
+	for i, relation := range relations {
+		frontier[i] = subjectOf(relation)
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	ancestors, err := ancestorEntities(budgeted, repo, frontier, visits{max: b.MaxNodes})
+	cancel()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	if err != nil {
+		c.checks.invalidateAll()
+		return
+	}
+	for entity := range ancestors {
+		for key := range c.checksBySubject[entity] {
+			c.checks.invalidate(key)
+		}
+		// the wildcard of a namespace is reached from each of its subjects
+		if entity.IsWildcard() {
+			for subject, keys := range c.checksBySubject {
+				if subject.Namespace != entity.Namespace {
+					continue
+				}
+				for key := range keys {
+					c.checks.invalidate(key)
+				}
+			}
+		}
+	}
+}
+
+func (c *Cache) invalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+	c.checks.invalidateAll()
+	c.lookups.invalidateAll()
+}
+
+// ancestorEntities returns the entities of the frontier and of every node
+// reaching it, or fails once it visited more nodes than visited allows.
+func ancestorEntities(ctx context.Context, repo sqldomain.RelationRepository, frontier []domain.Node, visited visits) (map[domain.Node]struct{}, error) {
+	entities := map[domain.Node]struct{}{}
+	seen := map[domain.Node]struct{}{}
+	next := []domain.Node{}
+	for _, node := range frontier {
+		if _, ok := seen[node]; !ok {
+			seen[node] = struct{}{}
+			next = append(next, node)
+		}
+	}
+	for len(next) > 0 {
+		if err := visited.add(len(next)); err != nil {
+			return nil, err
+		}
+		for _, node := range next {
+			entities[entityOf(node)] = struct{}{}
+		}
+		tuples, err := repo.QueryByObjects(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		next = []domain.Node{}
+		for _, tuple := range tuples {
+			parent := subjectOf(tuple)
+			if _, ok := seen[parent]; !ok {
+				seen[parent] = struct{}{}
+				next = append(next, parent)
+			}
+		}
+	}
+	return entities, nil
+}
+
+// cachedRepository serves QueryBySubjects and QueryByObjects of the latest
+// graph from the cache. It hides the optional interfaces of the repository
+// it wraps.
+type cachedRepository struct {
+	sqldomain.RelationRepository
+	cache *Cache
+}
+
+func (r *cachedRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	return r.lookup(ctx, subjects, false)
+}
+
+func (r *cachedRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	return r.lookup(ctx, objects, true)
+}
+
+// lookup queries the nodes missing from the cache at once and caches the
+// relations of each of them, also when there are none.
+func (r *cachedRepository) lookup(ctx context.Context, nodes []domain.Node, reverse bool) ([]domain.Relation, error) {
+	found := map[domain.Relation]struct{}{}
+	relations := []domain.Relation{}
+	collect := func(tuples []domain.Relation) {
+		for _, tuple := range tuples {
+			if _, ok := found[tuple.Key()]; !ok {
+				found[tuple.Key()] = struct{}{}
+				relations = append(relations, tuple)
+			}
+		}
+	}
+
+	missing := []domain.Node{}
+	r.cache.lock.Lock()
+	generation := r.cache.generation
+	for _, node := range nodes {
+		if tuples, ok := r.cache.lookups.get(lookupKey{node: node, reverse: reverse}); ok {
+			collect(tuples)
+		} else {
+			missing = append(missing, node)
+		}
+	}
+	r.cache.lock.Unlock()
+	if len(missing) == 0 {
+		return relations, nil
+	}
+
+	groups, missing := newNodeGroups(missing)
+	var tuples []domain.Relation
+	var err error
+	if reverse {
+		tuples, err = r.RelationRepository.QueryByObjects(ctx, missing)
+	} else {
+		tuples, err = r.RelationRepository.QueryBySubjects(ctx, missing)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, tuple := range tuples {
+		if reverse {
+			groups.add(objectOf(tuple), tuple)
+		} else {
+			groups.add(subjectOf(tuple), tuple)
+		}
+	}
+	collect(tuples)
+
+	r.cache.lock.Lock()
+	defer r.cache.lock.Unlock()
+	if generation == r.cache.generation {
+		for node, tuples := range groups {
+			r.cache.lookups.put(lookupKey{node: node, reverse: reverse}, tuples)
+		}
+	}
+	return relations, nil
+}
+
+// fresh reports whether a read asks for the latest graph, which is the one
+// cached.
+func fresh(options []usecasedom.ReadOptions) bool {
+	return len(options) == 0 || options[0].Consistency == (usecasedom.Consistency{})
+}
+
+// lookups returns repo with the adjacency cache if it reads the latest
+// graph. The cache hides the searches the repository runs itself, so it is
+// applied once those are ruled out.
+func (u *RelationUsecase) lookups(repo sqldomain.RelationRepository, options []usecasedom.ReadOptions) sqldomain.RelationRepository {
+	if u.Cache == nil || !fresh(options) {
+		return repo
+	}
+	return &cachedRepository{RelationRepository: repo, cache: u.Cache}
+}
+
+// invalidate drops the cache entries affected by the written relations. The
+// write committed already, so a request canceled since does not cut it short.
+func (u *RelationUsecase) invalidate(ctx context.Context, relations ...domain.Relation) {
+	if u.Cache != nil {
+		u.Cache.invalidate(context.WithoutCancel(ctx), u.RelationRepo, relations, budget(nil))
+	}
+}
+
+func (u *RelationUsecase) CacheStats() domain.CacheStats {
+	if u.Cache == nil {
+		return domain.CacheStats{}
+	}
+	return u.Cache.Stats()
+}
+
+func entityOf(node domain.Node) domain.Node {
+	return domain.Node{Namespace: node.Namespace, Name: node.Name}
+}
+
+func subjectOf(relation domain.Relation) domain.Node {
+	return domain.Node{Namespace: relation.SubjectNamespace, Name: relation.SubjectName, Relation: relation.SubjectRelation}
+}
+
+func objectOf(relation domain.Relation) domain.Node {
+	return domain.Node{Namespace: relation.ObjectNamespace, Name: relation.ObjectName, Relation: relation.Relation}
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+)
+
+// conditions evaluates the conditions and expiries of the relations one read
+// meets, in the context of the read and at the time it started.
+type conditions struct {
+	context domain.ConditionContext
+	now     time.Time
+	// compiled holds the conditions compiled so far, by a relation with
+	// only its condition set.
+	compiled map[domain.Relation]*domain.Condition
+	// met is set once a relation with a condition or an expiry was met, the
+	// result of the read then depends on its context and time.
+	met bool
+	// snapshot is set for exact snapshot reads, whose repository leaves out
+	// the relations expired at the time of the snapshot instead.
+	snapshot bool
+}
+
+func newConditions(options []usecasedom.ReadOptions) *conditions {
+	c := &conditions{now: time.Now(), compiled: map[domain.Relation]*domain.Condition{}}
+	if len(options) > 0 {
+		c.context = options[0].Context
+		c.snapshot = options[0].Consistency.ExactSnapshot != ""
+	}
+	return c
+}
+
+// holds reports whether relation has not expired and its condition holds. A
+// condition needing a context variable the read does not have holds if
+// assumed, which is where not crossing its relation could grant access.
+func (c *conditions) holds(relation domain.Relation, assumed bool) (bool, error) {
+	if relation.ExpiresAt != nil {
+		c.met = true
+		// the adjacency cache may hold relations that expired since
+		if !c.snapshot && relation.Expired(c.now) {
+			return false, nil
+		}
+	}
+	if relation.Condition == "" {
+		return true, nil
+	}
+	c.met = true
+	key := domain.Relation{Condition: relation.Condition, ConditionParams: relation.ConditionParams}
+	condition, ok := c.compiled[key]
+	if !ok {
+		var err error
+		if condition, err = relation.Compile(); err != nil {
+			return false, err
+		}
+		c.compiled[key] = condition
+	}
+	ok, err := condition.Holds(c.context, c.now)
+	if errors.As(err, &domain.MissingContextError{}) {
+		return assumed, nil
+	}
+	return ok, err
+}
+
+// filter returns repo without the relations that expired or whose condition
+// does not hold, see holds for assumed.
+func (c *conditions) filter(repo sqldomain.RelationRepository, assumed bool) sqldomain.RelationRepository {
+	return &conditionRepository{RelationRepository: repo, conditions: c, assumed: assumed}
+}
+
+// conditionRepository serves QueryBySubjects and QueryByObjects of the
+// relations that hold. It hides the optional interfaces of the repository
+// it wraps, whose own searches cross every relation.
+type conditionRepository struct {
+	sqldomain.RelationRepository
+	conditions *conditions
+	assumed    bool
+}
+
+func (r *conditionRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	relations, err := r.RelationRepository.QueryBySubjects(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	return r.holding(relations)
+}
+
+func (r *conditionRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	relations, err := r.RelationRepository.QueryByObjects(ctx, objects)
+	if err != nil {
+		return nil, err
+	}
+	return r.holding(relations)
+}
+
+func (r *conditionRepository) holding(relations []domain.Relation) ([]domain.Relation, error) {
+	held := make([]domain.Relation, 0, len(relations))
+	for _, relation := range relations {
+		ok, err := r.conditions.holds(relation, r.assumed)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			held = append(held, relation)
+		}
+	}
+	return held, nil
+}
+
+// graph returns repo as the traversals of a read see it: through the
+// adjacency cache, without the relations that expired or whose condition
+// does not hold, and with the relations the rules compute.
+func (u *RelationUsecase) graph(repo sqldomain.RelationRepository, options []usecasedom.ReadOptions, rules *rewriteRules) sqldomain.RelationRepository {
+	return rewrite(newConditions(options).filter(u.lookups(repo, options), false), rules)
+}
+
+// searchable returns the search of repo if it answers for the graph of the
+// rules: repo must search itself and the rules must not compute relations.
+// A search still does not answer if it met a condition, see
+// sqldomain.SearchResult.
+func searchable(repo sqldomain.RelationRepository, rules *rewriteRules) (sqldomain.ReachabilityRepository, bool) {
+	reachability, ok := repo.(sqldomain.ReachabilityRepository)
+	return reachability, ok && !rules.computes()
+}
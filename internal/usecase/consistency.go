@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+)
+
+// write runs fn in one transaction and returns the consistency token of the
+// revision it committed as. Tokens are the revisions in decimal, clients
+// should treat them as opaque.
+func (u *RelationUsecase) write(ctx context.Context, fn func(tx sqldomain.RelationRepository) error) (string, error) {
+	return commit(ctx, u.RelationRepo.WithTransaction, fn)
+}
+
+// writeDeletes is write for fn that only deletes, which does not wait for the
+// other writers if the repository can avoid it.
+func (u *RelationUsecase) writeDeletes(ctx context.Context, fn func(tx sqldomain.RelationRepository) error) (string, error) {
+	if repo, ok := u.RelationRepo.(sqldomain.DeleteTransactionRepository); ok {
+		return commit(ctx, repo.WithDeleteTransaction, fn)
+	}
+	return u.write(ctx, fn)
+}
+
+func commit(ctx context.Context, transaction func(ctx context.Context, fn func(tx sqldomain.RelationRepository) error) error, fn func(tx sqldomain.RelationRepository) error) (string, error) {
+	token := ""
+	err := transaction(ctx, func(tx sqldomain.RelationRepository) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		var err error
+		token, err = revisionToken(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func revisionToken(ctx context.Context, repo sqldomain.RelationRepository) (string, error) {
+	revisionRepo, ok := repo.(sqldomain.RevisionRepository)
+	if !ok {
+		return "", nil
+	}
+	revision, err := revisionRepo.Revision(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(revision, 10), nil
+}
+
+func parseToken(token string) (uint64, error) {
+	revision, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return 0, domain.InvalidTokenError{}
+	}
+	return revision, nil
+}
+
+// reader returns the repository the reads of a request go to. Backends
+// without history serve at_least_as_fresh from the repository itself, which
+// has every committed write, and can not serve exact_snapshot.
+func (u *RelationUsecase) reader(ctx context.Context, options []usecasedom.ReadOptions) (sqldomain.RelationRepository, error) {
+	if len(options) == 0 {
+		return u.RelationRepo, nil
+	}
+	c := options[0].Consistency
+	if c.AtLeastAsFresh != "" && c.ExactSnapshot != "" {
+		return nil, domain.RequestBodyError{}
+	}
+	snapshotRepo, snapshots := u.RelationRepo.(sqldomain.SnapshotRepository)
+	switch {
+	case c.AtLeastAsFresh != "":
+		revision, err := parseToken(c.AtLeastAsFresh)
+		if err != nil {
+			return nil, err
+		}
+		if !snapshots {
+			return u.RelationRepo, nil
+		}
+		return snapshotRepo.AtLeast(ctx, revision)
+	case c.ExactSnapshot != "":
+		revision, err := parseToken(c.ExactSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		if !snapshots {
+			return nil, domain.SnapshotUnavailableError{}
+		}
+		return snapshotRepo.AtRevision(ctx, revision)
+	}
+	return u.RelationRepo, nil
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/spf13/viper"
+)
+
+// sweepBatchSize is the number of expired relations deleted per transaction.
+const sweepBatchSize = 1000
+
+// sweepInterval is main.expiry-sweep-interval, a minute if unset.
+func sweepInterval() time.Duration {
+	if interval := viper.GetDuration("main.expiry-sweep-interval"); interval > 0 {
+		return interval
+	}
+	return time.Minute
+}
+
+// SweepExpired deletes the relations that expired, in transactions of up to
+// sweepBatchSize, each committing as a revision like any delete. It returns
+// how many it deleted, none if the repository can not find them.
+func (u *RelationUsecase) SweepExpired(ctx context.Context) (int, error) {
+	if _, ok := u.RelationRepo.(sqldomain.ExpiryRepository); !ok {
+		return 0, nil
+	}
+	swept := 0
+	for {
+		var expired []domain.Relation
+		_, err := u.writeDeletes(ctx, func(tx sqldomain.RelationRepository) error {
+			var err error
+			expired, err = tx.(sqldomain.ExpiryRepository).QueryExpired(ctx, time.Now(), sweepBatchSize)
+			if err != nil {
+				return err
+			}
+			for _, relation := range expired {
+				if err := tx.Delete(ctx, relation); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return swept, err
+		}
+		u.invalidate(ctx, expired...)
+		swept += len(expired)
+		if len(expired) < sweepBatchSize {
+			return swept, nil
+		}
+	}
+}
+
+// StartSweeper runs SweepExpired every interval until Stop.
+func (u *RelationUsecase) StartSweeper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := u.SweepExpired(context.Background()); err != nil {
+					log.Printf("sweep of expired relations: %v", err)
+				}
+			case <-u.stop:
+				return
+			}
+		}
+	}()
+}
+
+func (u *RelationUsecase) Stop() {
+	u.stopOnce.Do(func() { close(u.stop) })
+}
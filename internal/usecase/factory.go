@@ -1,16 +1,26 @@
 package usecase
 
 import (
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
 	ucdomain "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
-	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
 )
 
 type UsecaseRepository struct {
 	RelationUsecase ucdomain.RelationUsecase
+	relationUsecase *RelationUsecase
 }
 
-func NewUsecaseRepository(sqlRepo *sql.OrmRepository) *UsecaseRepository {
+// NewUsecaseRepository starts the sweeper of expired relations, every
+// main.expiry-sweep-interval until Stop.
+func NewUsecaseRepository(relationRepo sqldomain.RelationRepository) *UsecaseRepository {
+	relationUsecase := NewRelationUsecase(relationRepo)
+	relationUsecase.StartSweeper(sweepInterval())
 	return &UsecaseRepository{
-		RelationUsecase: NewRelationUsecase(&sqlRepo.RelationshipRepo),
+		RelationUsecase: relationUsecase,
+		relationUsecase: relationUsecase,
 	}
 }
+
+func (r *UsecaseRepository) Stop() {
+	r.relationUsecase.Stop()
+}
@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+	"github.com/skyrocketOoO/zanazibar-dag/utils"
+)
+
+const (
+	// importChunkSize is the number of relations staged at once.
+	importChunkSize = 10000
+	// maxImportLineErrors bounds the line errors kept in an ImportResult.
+	maxImportLineErrors = 1000
+)
+
+// BulkImport stages the relations in chunks while it reads them if the
+// repository supports staging, and keeps them in memory otherwise. Staged
+// relations are read back a chunk at a time and checked for cycles before
+// the serialized transaction that commits them, which checks again only the
+// relations created since, unless the schema changed. Relations held in
+// memory are checked inside that transaction, like Create.
+func (u *RelationUsecase) BulkImport(ctx context.Context, reader usecasedom.RelationReader, progress func(domain.ImportProgress)) (domain.ImportResult, error) {
+	if progress == nil {
+		progress = func(domain.ImportProgress) {}
+	}
+	result := domain.ImportResult{Errors: []domain.ImportLineError{}}
+
+	bulkRepo, bulk := u.RelationRepo.(sqldomain.BulkImportRepository)
+	importID, err := utils.GenerateRandomToken()
+	if err != nil {
+		return result, err
+	}
+	if bulk {
+		// the staged rows are dropped also if the import was canceled
+		defer bulkRepo.DropImport(context.WithoutCancel(ctx), importID)
+	}
+
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return result, err
+	}
+	staged := 0
+	// the relations of an import without staging, in the order read
+	imported := []domain.Relation{}
+	chunk := []domain.Relation{}
+	stage := func() error {
+		if bulk && len(chunk) > 0 {
+			if err := bulkRepo.StageImport(ctx, importID, staged-len(chunk), chunk); err != nil {
+				return err
+			}
+		} else {
+			imported = append(imported, chunk...)
+		}
+		chunk = chunk[:0]
+		progress(domain.ImportProgress{Phase: domain.ImportStaging, Lines: result.Lines, Staged: staged})
+		return nil
+	}
+	for {
+		relation, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		result.Lines++
+		if err == nil {
+			err = utils.ValidateRelation(relation)
+		}
+		if err == nil {
+			err = rules.allows(relation)
+		}
+		if err != nil {
+			if !errors.Is(err, domain.RequestBodyError{}) && !errors.As(err, &domain.SchemaViolationError{}) &&
+				!errors.As(err, &domain.InvalidConditionError{}) {
+				return result, err
+			}
+			result.ErrorCount++
+			if len(result.Errors) < maxImportLineErrors {
+				result.Errors = append(result.Errors, domain.ImportLineError{Line: result.Lines, Err: err.Error()})
+			}
+			continue
+		}
+		staged++
+		chunk = append(chunk, relation)
+		if len(chunk) >= importChunkSize {
+			if err := stage(); err != nil {
+				return result, err
+			}
+		}
+	}
+	if err := stage(); err != nil {
+		return result, err
+	}
+
+	// staged relations are checked against the graph before the serialized
+	// transaction, which then only checks the relations created since
+	var since uint64
+	if bulk {
+		if revisions, ok := u.RelationRepo.(sqldomain.RevisionRepository); ok {
+			if since, err = revisions.Revision(ctx); err != nil {
+				return result, err
+			}
+		}
+		progress(domain.ImportProgress{Phase: domain.ImportValidating, Lines: result.Lines, Staged: staged})
+		// a cycle runs through a relation of some chunk, whose check sees
+		// the whole graph of the import
+		graph := bulkRepo.ImportGraph(importID)
+		for line := 0; line < staged; line += importChunkSize {
+			relations, err := bulkRepo.ReadImport(ctx, importID, line, importChunkSize)
+			if err != nil {
+				return result, err
+			}
+			if err := checkAcyclic(ctx, graph, rules, relations); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	err = u.RelationRepo.WithTransaction(ctx, func(tx sqldomain.RelationRepository) error {
+		result.Imported = 0
+		// the relations are rolled back with the transaction if they close a
+		// cycle
+		if txBulk, ok := tx.(sqldomain.BulkImportRepository); ok && bulk {
+			progress(domain.ImportProgress{Phase: domain.ImportCommitting, Lines: result.Lines, Staged: staged})
+			changed, err := txBulk.CreatedSince(ctx, since)
+			if err != nil {
+				return err
+			}
+			latest, err := latestRules(ctx, tx)
+			if err != nil {
+				return err
+			}
+			created, err := txBulk.CommitImport(ctx, importID)
+			if err != nil {
+				return err
+			}
+			result.Imported = created
+			if latest.schemaVersion() != rules.schemaVersion() {
+				// a schema written since may close a cycle anywhere through
+				// the import
+				for line := 0; line < staged; line += importChunkSize {
+					relations, err := txBulk.ReadImport(ctx, importID, line, importChunkSize)
+					if err != nil {
+						return err
+					}
+					if err := checkAcyclic(ctx, tx, latest, relations); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			return checkAcyclic(ctx, tx, rules, changed)
+		}
+
+		// of repeated relations the first line is created, after the check
+		// of all of them in the graph as it will be
+		progress(domain.ImportProgress{Phase: domain.ImportValidating, Lines: result.Lines, Staged: staged})
+		if err := checkAcyclic(ctx, withImported(tx, imported), rules, imported); err != nil {
+			return err
+		}
+		progress(domain.ImportProgress{Phase: domain.ImportCommitting, Lines: result.Lines, Staged: staged})
+		for _, relation := range imported {
+			if err := tx.Create(ctx, relation); err != nil {
+				if errors.Is(err, domain.AlreadyExistsError{}) {
+					continue
+				}
+				return err
+			}
+			result.Imported++
+		}
+		return nil
+	})
+	if err == nil {
+		result.Token, err = revisionToken(ctx, u.RelationRepo)
+	}
+	// an import may touch any part of the graph
+	if u.Cache != nil && result.Imported > 0 {
+		u.Cache.invalidateAll()
+	}
+	if err != nil {
+		result.Imported = 0
+		return result, err
+	}
+	return result, nil
+}
+
+// importedRepository is a repository with the relations of an import held
+// in memory added to its QueryBySubjects and QueryByObjects, the graph an
+// import is checked in before it is written. Expired relations are left out
+// like the stored ones.
+type importedRepository struct {
+	sqldomain.RelationRepository
+	// bySubject and byObject index the relations by their node, and by its
+	// entity if it has a relation, as nodes without relation match those
+	bySubject map[domain.Node][]domain.Relation
+	byObject  map[domain.Node][]domain.Relation
+}
+
+func withImported(repo sqldomain.RelationRepository, relations []domain.Relation) *importedRepository {
+	r := &importedRepository{
+		RelationRepository: repo,
+		bySubject:          map[domain.Node][]domain.Relation{},
+		byObject:           map[domain.Node][]domain.Relation{},
+	}
+	now := time.Now()
+	for _, relation := range relations {
+		if relation.Expired(now) {
+			continue
+		}
+		indexImported(r.bySubject, subjectOf(relation), relation)
+		indexImported(r.byObject, objectOf(relation), relation)
+	}
+	return r
+}
+
+func indexImported(index map[domain.Node][]domain.Relation, node domain.Node, relation domain.Relation) {
+	index[node] = append(index[node], relation)
+	if node.Relation != "" {
+		index[entityOf(node)] = append(index[entityOf(node)], relation)
+	}
+}
+
+func (r *importedRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	relations, err := r.RelationRepository.QueryBySubjects(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	return appendImported(relations, r.bySubject, subjects), nil
+}
+
+func (r *importedRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	relations, err := r.RelationRepository.QueryByObjects(ctx, objects)
+	if err != nil {
+		return nil, err
+	}
+	return appendImported(relations, r.byObject, objects), nil
+}
+
+// appendImported appends the relations index holds for nodes to relations,
+// each once.
+func appendImported(relations []domain.Relation, index map[domain.Node][]domain.Relation, nodes []domain.Node) []domain.Relation {
+	found := map[domain.Relation]struct{}{}
+	for _, relation := range relations {
+		found[relation.Key()] = struct{}{}
+	}
+	for _, node := range nodes {
+		for _, relation := range index[node] {
+			if _, ok := found[relation.Key()]; !ok {
+				found[relation.Key()] = struct{}{}
+				relations = append(relations, relation)
+			}
+		}
+	}
+	return relations
+}
+
+// checkAcyclic checks in one pass that no cycle runs through the relations
+// that storing added adds to the graph the rules define, which repo has
+// already. The graph was acyclic before, so a cycle lies in the part of it
+// reachable from the objects of those relations. That part is collected
+// level by level and sorted topologically, a node left over is on or behind
+// a cycle.
+func checkAcyclic(ctx context.Context, repo sqldomain.RelationRepository, rules *rewriteRules, added []domain.Relation) error {
+	graph := rewrite(repo, rules)
+	frontier := []domain.Node{}
+	visited := map[domain.Node]struct{}{}
+	for _, relation := range added {
+		for _, relation := range rules.adds(relation) {
+			object := objectOf(relation)
+			if _, ok := visited[object]; !ok {
+				visited[object] = struct{}{}
+				frontier = append(frontier, object)
+			}
+		}
+	}
+
+	edges := map[domain.Node][]domain.Node{}
+	inDegree := map[domain.Node]int{}
+	for len(frontier) > 0 {
+		children, err := children(ctx, graph, frontier)
+		if err != nil {
+			return err
+		}
+		next := []domain.Node{}
+		for _, node := range frontier {
+			targets := []domain.Node{}
+			for _, tuple := range children[node] {
+				targets = append(targets, objectOf(tuple))
+			}
+			edges[node] = targets
+			for _, target := range targets {
+				inDegree[target]++
+				if _, ok := visited[target]; !ok {
+					visited[target] = struct{}{}
+					next = append(next, target)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	sorted := []domain.Node{}
+	for node := range visited {
+		if inDegree[node] == 0 {
+			sorted = append(sorted, node)
+		}
+	}
+	for i := 0; i < len(sorted); i++ {
+		for _, target := range edges[sorted[i]] {
+			inDegree[target]--
+			if inDegree[target] == 0 {
+				sorted = append(sorted, target)
+			}
+		}
+	}
+	if len(sorted) < len(visited) {
+		return domain.CauseCycleError{}
+	}
+	return nil
+}
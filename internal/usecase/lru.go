@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"container/list"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+)
+
+// lru is a size bounded cache whose entries also expire ttl after they were
+// put. It is not safe for concurrent use.
+type lru[K comparable, V any] struct {
+	size  int
+	ttl   time.Duration
+	items map[K]*list.Element
+	// order holds the entries, the most recently used first
+	order *list.List
+	// onRemove is called with the key of every entry leaving the cache
+	onRemove func(key K)
+	counters domain.CacheCounters
+}
+
+type lruEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+func newLru[K comparable, V any](size int, ttl time.Duration, onRemove func(key K)) *lru[K, V] {
+	return &lru[K, V]{
+		size:     size,
+		ttl:      ttl,
+		items:    map[K]*list.Element{},
+		order:    list.New(),
+		onRemove: onRemove,
+	}
+}
+
+func (c *lru[K, V]) get(key K) (V, bool) {
+	element, ok := c.items[key]
+	if ok && time.Now().After(element.Value.(*lruEntry[K, V]).expires) {
+		c.removeElement(element)
+		c.counters.Evictions++
+		ok = false
+	}
+	if !ok {
+		c.counters.Misses++
+		var zero V
+		return zero, false
+	}
+	c.counters.Hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+func (c *lru[K, V]) put(key K, value V) {
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expires: time.Now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+		c.counters.Evictions++
+	}
+}
+
+// invalidate removes key because a write changed its value.
+func (c *lru[K, V]) invalidate(key K) {
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+		c.counters.Invalidations++
+	}
+}
+
+func (c *lru[K, V]) invalidateAll() {
+	for c.order.Len() > 0 {
+		c.removeElement(c.order.Back())
+		c.counters.Invalidations++
+	}
+}
+
+func (c *lru[K, V]) stats() domain.CacheCounters {
+	counters := c.counters
+	counters.Entries = c.order.Len()
+	return counters
+}
+
+func (c *lru[K, V]) removeElement(element *list.Element) {
+	entry := c.order.Remove(element).(*lruEntry[K, V])
+	delete(c.items, entry.key)
+	if c.onRemove != nil {
+		c.onRemove(entry.key)
+	}
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+	"github.com/skyrocketOoO/zanazibar-dag/utils"
+)
+
+// permissionEngine decides whether subject holds relations by evaluating
+// their rewrites top down, one object node at a time, which the graph
+// traversals can not do for intersection and exclusion. The stored relations
+// whose condition holds are read from repo, which should not compute
+// relations itself, and from negatedRepo under an exclusion, which crosses
+// the relations whose condition lacks context rather than grant access.
+type permissionEngine struct {
+	repo            sqldomain.RelationRepository
+	negatedRepo     sqldomain.RelationRepository
+	rules           *rewriteRules
+	subject         domain.Node
+	searchCondition domain.SearchCondition
+	maxDepth        int
+	visited         visits
+	// done holds the final explanations, inProgress the evaluations the
+	// current one is part of.
+	done       map[evaluation]domain.Explanation
+	inProgress map[domain.Node]struct{}
+}
+
+// evaluation is a node evaluated with or without the search condition,
+// which is not applied under an exclusion.
+type evaluation struct {
+	node    domain.Node
+	negated bool
+}
+
+func newPermissionEngine(repo sqldomain.RelationRepository, conditions *conditions, rules *rewriteRules, subject domain.Node, searchCondition domain.SearchCondition, budget domain.Budget) *permissionEngine {
+	return &permissionEngine{
+		repo:            conditions.filter(repo, false),
+		negatedRepo:     conditions.filter(repo, true),
+		rules:           rules,
+		subject:         subject,
+		searchCondition: searchCondition,
+		maxDepth:        budget.MaxDepth,
+		visited:         visits{max: budget.MaxNodes},
+		done:            map[evaluation]domain.Explanation{},
+		inProgress:      map[domain.Node]struct{}{},
+	}
+}
+
+// permitted evaluates the relation of node for the subject at depth, the
+// number of relations from the object of the check. Under an exclusion,
+// negated is set and the evaluation must not deny for lack of depth. cut
+// reports a denial for lack of depth or by a cycle, which holds for this
+// evaluation only.
+func (e *permissionEngine) permitted(ctx context.Context, node domain.Node, depth int, negated bool) (explanation domain.Explanation, cut bool, err error) {
+	if node == e.subject {
+		return domain.Explanation{Object: node, Allowed: true, Children: []domain.Explanation{}}, false, nil
+	}
+	if explanation, ok := e.done[evaluation{node: node, negated: negated}]; ok {
+		return explanation, false, nil
+	}
+	rewrite := e.rules.rewriteOf(node.Namespace, node.Relation)
+	denied := domain.Explanation{Object: node, Rewrite: rewrite.String(), Children: []domain.Explanation{}}
+	if _, ok := e.inProgress[node]; ok {
+		return denied, true, nil
+	}
+	if e.maxDepth > 0 && depth >= e.maxDepth {
+		if negated {
+			return domain.Explanation{}, false, domain.BudgetExceededError{Limit: domain.DepthBudget}
+		}
+		return denied, true, nil
+	}
+	if err := e.visited.add(1); err != nil {
+		return domain.Explanation{}, false, err
+	}
+	e.inProgress[node] = struct{}{}
+	explanation, cut, err = e.evaluate(ctx, rewrite, node, depth, negated)
+	delete(e.inProgress, node)
+	if err != nil {
+		return domain.Explanation{}, false, err
+	}
+	if !cut {
+		e.done[evaluation{node: node, negated: negated}] = explanation
+	}
+	return explanation, cut, nil
+}
+
+// evaluate evaluates rewrite on node.
+func (e *permissionEngine) evaluate(ctx context.Context, rewrite domain.Rewrite, node domain.Node, depth int, negated bool) (domain.Explanation, bool, error) {
+	explanation := domain.Explanation{Object: node, Rewrite: rewrite.String(), Children: []domain.Explanation{}}
+	cut := false
+	switch rewrite.Operator {
+	case "":
+		return e.term(ctx, rewrite.Term, node, depth, negated)
+	case domain.UnionOperator:
+		for _, operand := range rewrite.Operands {
+			child, childCut, err := e.evaluate(ctx, operand, node, depth, negated)
+			if err != nil {
+				return domain.Explanation{}, false, err
+			}
+			cut = cut || childCut
+			if child.Allowed {
+				explanation.Allowed = true
+				explanation.Children = []domain.Explanation{child}
+				return explanation, cut, nil
+			}
+			explanation.Children = append(explanation.Children, child)
+		}
+	case domain.IntersectionOperator:
+		for _, operand := range rewrite.Operands {
+			child, childCut, err := e.evaluate(ctx, operand, node, depth, negated)
+			if err != nil {
+				return domain.Explanation{}, false, err
+			}
+			cut = cut || childCut
+			if !child.Allowed {
+				explanation.Children = []domain.Explanation{child}
+				return explanation, cut, nil
+			}
+			explanation.Children = append(explanation.Children, child)
+		}
+		explanation.Allowed = true
+	case domain.ExclusionOperator:
+		base, baseCut, err := e.evaluate(ctx, rewrite.Operands[0], node, depth, negated)
+		if err != nil {
+			return domain.Explanation{}, false, err
+		}
+		if !base.Allowed {
+			explanation.Children = []domain.Explanation{base}
+			return explanation, baseCut, nil
+		}
+		excluded, excludedCut, err := e.evaluate(ctx, rewrite.Operands[1], node, depth, !negated)
+		if err != nil {
+			return domain.Explanation{}, false, err
+		}
+		cut = baseCut || excludedCut
+		if excluded.Allowed {
+			explanation.Children = []domain.Explanation{excluded}
+			return explanation, cut, nil
+		}
+		explanation.Allowed = true
+		explanation.Children = []domain.Explanation{base, excluded}
+	}
+	return explanation, cut, nil
+}
+
+// term evaluates a term of the rewrite of node. A direct term follows the
+// stored relations of node to subject sets, the others continue at the
+// nodes they compute from, and each step counts as a relation of depth.
+func (e *permissionEngine) term(ctx context.Context, term domain.RewriteTerm, node domain.Node, depth int, negated bool) (domain.Explanation, bool, error) {
+	explanation := domain.Explanation{Object: node, Rewrite: term.String(), Children: []domain.Explanation{}}
+	if term.Tupleset == "" && !term.Direct() {
+		child, cut, err := e.permitted(ctx, domain.Node{Namespace: node.Namespace, Name: node.Name, Relation: term.Relation}, depth+1, negated)
+		if err != nil {
+			return domain.Explanation{}, false, err
+		}
+		explanation.Allowed = child.Allowed
+		explanation.Children = []domain.Explanation{child}
+		return explanation, cut, nil
+	}
+
+	from := node
+	if !term.Direct() {
+		from.Relation = term.Tupleset
+	}
+	repo := e.repo
+	if negated {
+		repo = e.negatedRepo
+	}
+	parents, err := parents(ctx, repo, []domain.Node{from})
+	if err != nil {
+		return domain.Explanation{}, false, err
+	}
+	cut := false
+	for _, tuple := range parents[from] {
+		tuple := tuple
+		next := subjectOf(tuple)
+		if term.Direct() {
+			if next.Matches(e.subject) {
+				explanation.Allowed = true
+				explanation.Children = []domain.Explanation{{Object: next, Allowed: true, Through: &tuple, Children: []domain.Explanation{}}}
+				return explanation, cut, nil
+			}
+			// a subject without relation holds nothing further
+			if next.Relation == "" {
+				continue
+			}
+		} else {
+			next.Relation = term.Relation
+		}
+		// the search condition does not narrow what an exclusion removes
+		if !negated && e.searchCondition.ShouldStop(next) {
+			continue
+		}
+		child, childCut, err := e.permitted(ctx, next, depth+1, negated)
+		if err != nil {
+			return domain.Explanation{}, false, err
+		}
+		cut = cut || childCut
+		child.Through = &tuple
+		if child.Allowed {
+			explanation.Allowed = true
+			explanation.Children = []domain.Explanation{child}
+			return explanation, cut, nil
+		}
+		explanation.Children = append(explanation.Children, child)
+	}
+	return explanation, cut, nil
+}
+
+// Explain evaluates whether subject holds the relation of object under the
+// schema, and returns the branches the decision rests on. Check comes to
+// the same decision, by the permission engine too once the schema has an
+// intersection or exclusion.
+func (u *RelationUsecase) Explain(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...usecasedom.ReadOptions) (*domain.Explanation, error) {
+	if err := utils.ValidateNode(object, false); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateNode(subject, true); err != nil {
+		return nil, err
+	}
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	explanation, err := explain(budgeted, u.lookups(repo, options), newConditions(options), rules, subject, object, searchCondition, b)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	return &explanation, nil
+}
+
+func explain(ctx context.Context, repo sqldomain.RelationRepository, conditions *conditions, rules *rewriteRules, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, budget domain.Budget) (domain.Explanation, error) {
+	engine := newPermissionEngine(repo, conditions, rules, subject, searchCondition, budget)
+	explanation, _, err := engine.permitted(ctx, object, 0, false)
+	return explanation, err
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+// rankedPath is a path with its cost under the preference of the search.
+type rankedPath struct {
+	relations []domain.Relation
+	cost      int
+}
+
+// before reports whether p ranks before o: it is shorter, or as long and
+// cheaper.
+func (p rankedPath) before(o rankedPath) bool {
+	if len(p.relations) != len(o.relations) {
+		return len(p.relations) < len(o.relations)
+	}
+	return p.cost < o.cost
+}
+
+// rankedStep is the best relation found leading to a node, and the cost of
+// the path up to the node through it.
+type rankedStep struct {
+	searchStep
+	cost int
+}
+
+// kShortestPaths returns up to k paths from subject to object, best ranked
+// first, by Yen's algorithm: every path after the first is the best one
+// leaving a path already found at one of its nodes by a relation no found
+// path with the same beginning took. The graph is acyclic, so a deviation
+// never returns to the beginning it left, and no nodes are excluded.
+func kShortestPaths(ctx context.Context, repo sqldomain.RelationRepository, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, preference domain.PathPreference, k int, budget domain.Budget) ([][]domain.Relation, error) {
+	visited := &visits{max: budget.MaxNodes}
+	first, err := bestPath(ctx, repo, subject, object, searchCondition, preference, nil, budget.MaxDepth, visited)
+	if err != nil {
+		return nil, err
+	}
+	paths := [][]domain.Relation{}
+	if first == nil {
+		return paths, nil
+	}
+	paths = append(paths, first.relations)
+	seen := map[string]struct{}{pathKey(first.relations): {}}
+	candidates := []rankedPath{}
+	for len(paths) < k {
+		last := paths[len(paths)-1]
+		for i := range last {
+			spur := subject
+			if i > 0 {
+				spur = objectOf(last[i-1])
+			}
+			root := last[:i]
+			excluded := map[domain.Relation]struct{}{}
+			for _, path := range paths {
+				if len(path) > i && pathKey(path[:i]) == pathKey(root) {
+					excluded[path[i].Key()] = struct{}{}
+				}
+			}
+			maxDepth := budget.MaxDepth
+			if maxDepth > 0 {
+				// at least 1, no path found is longer than the budget
+				maxDepth -= i
+			}
+			tail, err := bestPath(ctx, repo, spur, object, searchCondition, preference, excluded, maxDepth, visited)
+			if err != nil {
+				return nil, err
+			}
+			if tail == nil {
+				continue
+			}
+			relations := append(append([]domain.Relation{}, root...), tail.relations...)
+			key := pathKey(relations)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			candidates = append(candidates, rankedPath{relations: relations, cost: pathCost(preference, relations)})
+		}
+		if len(candidates) == 0 {
+			break
+		}
+		best := 0
+		for i, candidate := range candidates {
+			if candidate.before(candidates[best]) {
+				best = i
+			}
+		}
+		paths = append(paths, candidates[best].relations)
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+	return paths, nil
+}
+
+// bestPath searches from the node from along the relations, one level at a
+// time, for the best ranked path to object of at most maxDepth relations, a
+// maxDepth of 0 or less means no depth limit. The relations whose Key is in
+// excluded are not followed, nor are the nodes the search condition stops at passed
+// through. It returns nil if there is no such path.
+func bestPath(ctx context.Context, repo sqldomain.RelationRepository, from domain.Node, object domain.Node, searchCondition domain.SearchCondition, preference domain.PathPreference, excluded map[domain.Relation]struct{}, maxDepth int, visited *visits) (*rankedPath, error) {
+	steps := map[domain.Node]rankedStep{from: {searchStep: searchStep{root: true}}}
+	frontier := []domain.Node{from}
+	for depth := 0; len(frontier) > 0 && (maxDepth <= 0 || depth < maxDepth); depth++ {
+		children, err := children(ctx, repo, frontier)
+		if err != nil {
+			return nil, err
+		}
+		// a node is first reached at its shortest distance, the cheapest of
+		// the relations reaching it in that level is its step
+		level := map[domain.Node]rankedStep{}
+		next := []domain.Node{}
+		for _, node := range frontier {
+			for _, tuple := range children[node] {
+				if _, ok := excluded[tuple.Key()]; ok {
+					continue
+				}
+				child := objectOf(tuple)
+				if _, ok := steps[child]; ok {
+					continue
+				}
+				step := rankedStep{
+					searchStep: searchStep{next: node, relation: tuple},
+					cost:       steps[node].cost + preference.Cost(tuple),
+				}
+				if found, ok := level[child]; ok {
+					if step.cost < found.cost {
+						level[child] = step
+					}
+					continue
+				}
+				if child != object && searchCondition.ShouldStop(child) {
+					continue
+				}
+				if err := visited.add(1); err != nil {
+					return nil, err
+				}
+				level[child] = step
+				next = append(next, child)
+			}
+		}
+		for node, step := range level {
+			steps[node] = step
+		}
+		if step, ok := steps[object]; ok {
+			relations := []domain.Relation{}
+			for s := step; !s.root; s = steps[s.next] {
+				relations = append(relations, s.relation)
+			}
+			for i, j := 0, len(relations)-1; i < j; i, j = i+1, j-1 {
+				relations[i], relations[j] = relations[j], relations[i]
+			}
+			return &rankedPath{relations: relations, cost: step.cost}, nil
+		}
+		frontier = next
+	}
+	return nil, nil
+}
+
+func pathCost(preference domain.PathPreference, path []domain.Relation) int {
+	cost := 0
+	for _, relation := range path {
+		cost += preference.Cost(relation)
+	}
+	return cost
+}
+
+func pathKey(path []domain.Relation) string {
+	keys := make([]domain.Relation, len(path))
+	for i, relation := range path {
+		keys[i] = relation.Key()
+	}
+	return fmt.Sprint(keys)
+}
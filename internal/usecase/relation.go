@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,7 +13,6 @@ import (
 	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
 	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
 	"github.com/skyrocketOoO/zanazibar-dag/utils"
-	"gorm.io/gorm"
 )
 
 type PageState struct {
@@ -24,11 +25,20 @@ type RelationUsecase struct {
 	RelationRepo   sqldomain.RelationRepository
 	PageStates     map[string]*PageState
 	PageStatesLock sync.RWMutex
+	// Cache is nil if caching is disabled.
+	Cache   *Cache
+	schemas *schemaCache
+	// stop ends the sweeper of StartSweeper
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 func NewRelationUsecase(relationRepo sqldomain.RelationRepository) *RelationUsecase {
 	relationUsecase := RelationUsecase{
 		RelationRepo: relationRepo,
+		Cache:        newCacheFromConfig(),
+		schemas:      &schemaCache{},
+		stop:         make(chan struct{}),
 	}
 
 	go func(u *RelationUsecase) {
@@ -54,7 +64,19 @@ func NewRelationUsecase(relationRepo sqldomain.RelationRepository) *RelationUsec
 	return &relationUsecase
 }
 
-func (u *RelationUsecase) Get(relation domain.Relation, options ...usecasedom.PageOptions) ([]domain.Relation, string, error) {
+// Primary returns a usecase reading from the primary, or u itself if the
+// repository does not read from replicas. It shares only the schema with u,
+// so page tokens of Get belong to the usecase that issued them, and its
+// reads bypass the cache.
+func (u *RelationUsecase) Primary() usecasedom.RelationUsecase {
+	repo, ok := u.RelationRepo.(sqldomain.PrimaryRepository)
+	if !ok {
+		return u
+	}
+	return &RelationUsecase{RelationRepo: repo.Primary(), schemas: u.schemas}
+}
+
+func (u *RelationUsecase) Get(ctx context.Context, relation domain.Relation, options ...usecasedom.PageOptions) ([]domain.Relation, string, error) {
 	if relation.ObjectNamespace == "" && relation.ObjectName == "" && relation.Relation == "" &&
 		relation.SubjectNamespace == "" && relation.SubjectName == "" && relation.SubjectRelation == "" {
 		var lastID uint
@@ -74,7 +96,7 @@ func (u *RelationUsecase) Get(relation domain.Relation, options ...usecasedom.Pa
 			delete(u.PageStates, options.PageToken)
 			u.PageStatesLock.Unlock()
 		}
-		relations, lastID, err := u.RelationRepo.GetAll(sqldomain.PageOptions{
+		relations, lastID, err := u.RelationRepo.GetAll(ctx, sqldomain.PageOptions{
 			LastID:   lastID,
 			PageSize: pageSize,
 		})
@@ -101,174 +123,275 @@ func (u *RelationUsecase) Get(relation domain.Relation, options ...usecasedom.Pa
 
 		return relations, token, nil
 	} else {
-		relations, err := u.RelationRepo.Query(relation)
+		relations, err := u.RelationRepo.Query(ctx, relation)
 		return relations, "", err
 	}
 }
 
-func (u *RelationUsecase) Create(relation domain.Relation, existOk bool) error {
+func (u *RelationUsecase) Create(ctx context.Context, relation domain.Relation, existOk bool) (string, error) {
 	if err := utils.ValidateRelation(relation); err != nil {
-		return err
+		return "", err
 	}
-	ok, err := u.Check(
-		domain.Node{
-			Namespace: relation.ObjectNamespace,
-			Name:      relation.ObjectName,
-			Relation:  relation.Relation,
-		},
-		domain.Node{
-			Namespace: relation.SubjectNamespace,
-			Name:      relation.SubjectName,
-			Relation:  relation.SubjectRelation,
-		},
-		domain.SearchCondition{},
-	)
+	rules, err := u.rules(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if ok {
-		return domain.CauseCycleError{}
+	if err := rules.allows(relation); err != nil {
+		return "", err
 	}
-
-	err = u.RelationRepo.Create(relation)
+	// the check and the insert share one serialized transaction, otherwise
+	// concurrent creates of A->B and B->A could both pass the check
+	token, err := u.write(ctx, func(tx sqldomain.RelationRepository) error {
+		return createAcyclic(ctx, tx, rules, relation, existOk)
+	})
 	if err != nil {
-		if err == gorm.ErrDuplicatedKey {
-			if existOk {
-				return nil
-			} else {
-				return err
-			}
-		}
-		return err
+		return "", err
 	}
-	return nil
+	u.invalidate(ctx, relation)
+	return token, nil
 }
 
-func (u *RelationUsecase) Delete(relation domain.Relation) error {
+func (u *RelationUsecase) Delete(ctx context.Context, relation domain.Relation) (string, error) {
 	if err := utils.ValidateRelation(relation); err != nil {
-		return err
+		return "", err
 	}
-	return u.RelationRepo.Delete(relation)
+	token, err := u.writeDeletes(ctx, func(tx sqldomain.RelationRepository) error {
+		return tx.Delete(ctx, relation)
+	})
+	if err != nil {
+		return "", err
+	}
+	u.invalidate(ctx, relation)
+	return token, nil
 }
 
-func (u *RelationUsecase) DeleteByQueries(queries []domain.Relation) error {
-	return u.RelationRepo.DeleteByQueries(queries)
+func (u *RelationUsecase) DeleteByQueries(ctx context.Context, queries []domain.Relation) (string, error) {
+	deleted := []domain.Relation{}
+	token, err := u.writeDeletes(ctx, func(tx sqldomain.RelationRepository) error {
+		// the cache is invalidated by relation, so the matches are needed
+		// only if there is one
+		if u.Cache != nil {
+			for _, query := range queries {
+				relations, err := tx.Query(ctx, query)
+				if err != nil {
+					return err
+				}
+				deleted = append(deleted, relations...)
+			}
+		}
+		return tx.DeleteByQueries(ctx, queries)
+	})
+	if err != nil {
+		return "", err
+	}
+	u.invalidate(ctx, deleted...)
+	return token, nil
 }
 
-func (u *RelationUsecase) BatchOperation(operations []domain.Operation) error {
-	for _, operation := range operations {
+// BatchOperation applies all operations in one transaction. Every create is
+// cycle checked against the graph including the earlier operations, and the
+// error of a failing operation is a domain.BatchOperationError with its
+// index.
+func (u *RelationUsecase) BatchOperation(ctx context.Context, operations []domain.Operation) (string, error) {
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return "", err
+	}
+	for i, operation := range operations {
 		if err := utils.ValidateRelation(operation.Relation); err != nil {
-			return err
+			return "", domain.BatchOperationError{Index: i, Err: err}
+		}
+		if operation.Type == domain.DeleteOperation {
+			continue
+		}
+		if err := rules.allows(operation.Relation); err != nil {
+			return "", domain.BatchOperationError{Index: i, Err: err}
+		}
+	}
+	write := u.writeDeletes
+	for _, operation := range operations {
+		if operation.Type != domain.DeleteOperation {
+			write = u.write
 		}
 	}
-	return u.RelationRepo.BatchOperation(operations)
+	token, err := write(ctx, func(tx sqldomain.RelationRepository) error {
+		for i, operation := range operations {
+			var err error
+			switch operation.Type {
+			case domain.CreateOperation:
+				err = createAcyclic(ctx, tx, rules, operation.Relation, false)
+			case domain.CreateIfNotExistOperation:
+				err = createAcyclic(ctx, tx, rules, operation.Relation, true)
+			case domain.DeleteOperation:
+				err = tx.Delete(ctx, operation.Relation)
+			default:
+				err = domain.RequestBodyError{}
+			}
+			if err != nil {
+				return domain.BatchOperationError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	relations := make([]domain.Relation, len(operations))
+	for i, operation := range operations {
+		relations[i] = operation.Relation
+	}
+	u.invalidate(ctx, relations...)
+	return token, nil
 }
 
-func (u *RelationUsecase) GetAllNamespaces() ([]string, error) {
-	return u.RelationRepo.GetAllNamespaces()
+func (u *RelationUsecase) GetAllNamespaces(ctx context.Context) ([]string, error) {
+	return u.RelationRepo.GetAllNamespaces(ctx)
 }
 
-func (u *RelationUsecase) Check(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) (bool, error) {
+func (u *RelationUsecase) Check(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...usecasedom.ReadOptions) (bool, error) {
 	if err := utils.ValidateNode(object, false); err != nil {
 		return false, err
 	}
 	if err := utils.ValidateNode(subject, true); err != nil {
 		return false, err
 	}
-	visited := set.NewSet[domain.Node]()
-	q := queue.NewQueue[domain.Node]()
-	visited.Add(subject)
-	q.Push(subject)
-
-	for !q.IsEmpty() {
-		qLen := q.Len()
-		for i := 0; i < qLen; i++ {
-			node, _ := q.Pop()
-			query := domain.Relation{
-				SubjectNamespace: node.Namespace,
-				SubjectName:      node.Name,
-				SubjectRelation:  node.Relation,
-			}
-			tuples, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return false, err
-			}
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return false, err
+	}
+	var key checkKey
+	var generation uint64
+	if u.Cache != nil && fresh(options) {
+		var ok, found bool
+		key = newCheckKey(subject, object, searchCondition, b.MaxDepth, rules)
+		if ok, found, generation = u.Cache.getCheck(key); found {
+			return ok, nil
+		}
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return false, budgetError(ctx, budgeted, err)
+	}
+	var ok bool
+	conditions := newConditions(options)
+	if rules != nil && rules.operators {
+		var explanation domain.Explanation
+		explanation, err = explain(budgeted, u.lookups(repo, options), conditions, rules, subject, object, searchCondition, b)
+		ok = explanation.Allowed
+	} else {
+		var result sqldomain.SearchResult
+		reachability, search := searchable(repo, rules)
+		if search {
+			result, err = reachability.Search(budgeted, subject, object, searchCondition, b.MaxDepth, false)
+			search = err == nil && !result.Conditional
+		}
+		switch {
+		case err != nil:
+		case search:
+			// the database leaves out expired relations, but the answer
+			// changes once those it met expire
+			ok, conditions.met = result.Found, result.Expiring
+		default:
+			repo = rewrite(conditions.filter(u.lookups(repo, options), false), rules)
+			ok, err = reachable(budgeted, repo, subject, object, searchCondition, b)
+		}
+	}
+	if err != nil {
+		return false, budgetError(ctx, budgeted, err)
+	}
+	// a result depending on conditions may change without a write
+	if u.Cache != nil && fresh(options) && !conditions.met {
+		u.Cache.putCheck(generation, key, ok)
+	}
+	return ok, nil
+}
 
-			for _, tuple := range tuples {
-				if tuple.ObjectNamespace == object.Namespace && tuple.ObjectName == object.Name && tuple.Relation == object.Relation {
-					return true, nil
-				}
-				child := domain.Node{
-					Namespace: tuple.ObjectNamespace,
-					Name:      tuple.ObjectName,
-					Relation:  tuple.Relation,
-				}
-				if !searchCondition.ShouldStop(child) && !visited.Exist(child) {
-					visited.Add(child)
-					q.Push(child)
-				}
-			}
+// createAcyclic creates relation unless it closes a cycle in the graph the
+// rules define, which is the case when the object of a relation it adds
+// reaches its subject. A wildcard subject is the object of no relation, and
+// its relations leave only the subject a traversal starts from, so it closes
+// none.
+func createAcyclic(ctx context.Context, repo sqldomain.RelationRepository, rules *rewriteRules, relation domain.Relation, existOk bool) error {
+	if err := create(ctx, repo, relation, existOk); err != nil {
+		return err
+	}
+	if relation.SubjectName == domain.Wildcard {
+		return nil
+	}
+	// the write is rolled back with the transaction if it closes a cycle
+	graph := rewrite(repo, rules)
+	for _, added := range rules.adds(relation) {
+		// a cycle check must see the whole graph, so it ignores the depth
+		// limit
+		ok, err := reachable(ctx, graph, objectOf(added), subjectOf(added), domain.SearchCondition{}, domain.Budget{})
+		if err != nil {
+			return err
+		}
+		if ok {
+			return domain.CauseCycleError{}
 		}
 	}
+	return nil
+}
 
-	return false, nil
+func create(ctx context.Context, repo sqldomain.RelationRepository, relation domain.Relation, existOk bool) error {
+	if existOk {
+		return repo.CreateIfNotExists(ctx, relation)
+	}
+	return repo.Create(ctx, relation)
 }
 
-func (u *RelationUsecase) GetShortestPath(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) ([]domain.Relation, error) {
+// reachable searches in the repository when it supports it and falls back
+// to a bidirectional BFS with one repository call per level otherwise. The
+// repository search is bounded by the depth of the budget only.
+func reachable(ctx context.Context, repo sqldomain.RelationRepository, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, budget domain.Budget) (bool, error) {
+	if repo, ok := repo.(sqldomain.ReachabilityRepository); ok {
+		return repo.Reachable(ctx, subject, object, searchCondition, budget.MaxDepth)
+	}
+
+	path, err := shortestPath(ctx, repo, subject, object, searchCondition, budget)
+	return path != nil, err
+}
+
+func (u *RelationUsecase) GetShortestPath(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...usecasedom.ReadOptions) ([]domain.Relation, error) {
 	if err := utils.ValidateNode(object, false); err != nil {
 		return nil, err
 	}
 	if err := utils.ValidateNode(subject, true); err != nil {
 		return nil, err
 	}
-	visited := set.NewSet[domain.Node]()
-	type NodeItem struct {
-		Cur  domain.Node
-		Path []domain.Relation
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return nil, err
 	}
-	firstNode := NodeItem{
-		Cur:  subject,
-		Path: []domain.Relation{},
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
 	}
-	q := queue.NewQueue[NodeItem]()
-	visited.Add(subject)
-	q.Push(firstNode)
-	for !q.IsEmpty() {
-		qLen := q.Len()
-		for i := 0; i < qLen; i++ {
-			node, _ := q.Pop()
-			query := domain.Relation{
-				SubjectNamespace: node.Cur.Namespace,
-				SubjectName:      node.Cur.Name,
-				SubjectRelation:  node.Cur.Relation,
-			}
-			tuples, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, tuple := range tuples {
-				if tuple.ObjectNamespace == object.Namespace && tuple.ObjectName == object.Name && tuple.Relation == object.Relation {
-					return append(node.Path, tuple), nil
-				}
-				child := domain.Node{
-					Namespace: tuple.ObjectNamespace,
-					Name:      tuple.ObjectName,
-					Relation:  tuple.Relation,
-				}
-				if !searchCondition.ShouldStop(child) && !visited.Exist(child) {
-					visited.Add(child)
-					copyPath := append(node.Path, tuple)
-					q.Push(NodeItem{
-						Cur:  child,
-						Path: copyPath,
-					})
-				}
-			}
-		}
+	var path []domain.Relation
+	var result sqldomain.SearchResult
+	reachability, search := searchable(repo, rules)
+	if search {
+		result, err = reachability.Search(budgeted, subject, object, searchCondition, b.MaxDepth, true)
+		search = err == nil && !result.Conditional
 	}
-
-	return nil, nil
+	switch {
+	case err != nil:
+	case search:
+		path = result.Path
+	default:
+		path, err = shortestPath(budgeted, u.graph(repo, options, rules), subject, object, searchCondition, b)
+	}
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	return path, nil
 
 	// maxDepth, err := strconv.Atoi(viper.GetString("main.max-search-depth"))
 	// if err != nil {
@@ -316,67 +439,174 @@ func (u *RelationUsecase) GetShortestPath(subject domain.Node, object domain.Nod
 	// return finalPath, nil
 }
 
-func (u *RelationUsecase) GetAllPaths(subject domain.Node, object domain.Node, searchCondition domain.SearchCondition) ([][]domain.Relation, error) {
+func (u *RelationUsecase) GetKShortestPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, k int, preference domain.PathPreference, options ...usecasedom.ReadOptions) ([][]domain.Relation, error) {
 	if err := utils.ValidateNode(object, false); err != nil {
 		return nil, err
 	}
 	if err := utils.ValidateNode(subject, true); err != nil {
 		return nil, err
 	}
+	if k <= 0 {
+		return nil, domain.RequestBodyError{}
+	}
+	b := budget(options)
+	if b.MaxPaths > 0 && k > b.MaxPaths {
+		k = b.MaxPaths
+	}
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	paths, err := kShortestPaths(budgeted, u.graph(repo, options, rules), subject, object, searchCondition, preference, k, b)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	return paths, nil
+}
+
+func (u *RelationUsecase) GetAllPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, options ...usecasedom.ReadOptions) ([][]domain.Relation, bool, error) {
 	paths := [][]domain.Relation{}
+	truncated, err := u.StreamAllPaths(ctx, subject, object, searchCondition, func(path []domain.Relation) error {
+		paths = append(paths, path)
+		return nil
+	}, options...)
+	if err != nil {
+		return nil, false, err
+	}
+	return paths, truncated, nil
+}
+
+func (u *RelationUsecase) StreamAllPaths(ctx context.Context, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, emit func(path []domain.Relation) error, options ...usecasedom.ReadOptions) (bool, error) {
+	if err := utils.ValidateNode(object, false); err != nil {
+		return false, err
+	}
+	if err := utils.ValidateNode(subject, true); err != nil {
+		return false, err
+	}
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return false, err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return false, budgetError(ctx, budgeted, err)
+	}
+	repo = u.graph(repo, options, rules)
+	visited := visits{max: b.MaxNodes}
+	depth := 0
+	found := 0
 	type NodeItem struct {
 		Cur  domain.Node
 		Path []domain.Relation
+		// OnPath holds the nodes of Path, a copy per item like Path
+		OnPath map[domain.Node]struct{}
 	}
 	firstNode := NodeItem{
-		Cur:  subject,
-		Path: []domain.Relation{},
+		Cur:    subject,
+		Path:   []domain.Relation{},
+		OnPath: map[domain.Node]struct{}{subject: {}},
 	}
 	q := queue.NewQueue[NodeItem]()
 	q.Push(firstNode)
+	// running out of any budget ends the paths emitted so far as truncated
 	for !q.IsEmpty() {
+		if b.MaxDepth > 0 && depth >= b.MaxDepth {
+			return true, nil
+		}
+		frontier := []domain.Node{}
+		for _, item := range q.ToSlice() {
+			frontier = append(frontier, item.Cur)
+		}
+		children, err := children(budgeted, repo, frontier)
+		if err != nil {
+			if outOfTime(ctx, budgeted) {
+				return true, nil
+			}
+			return false, err
+		}
 		qLen := q.Len()
 		for i := 0; i < qLen; i++ {
 			node, _ := q.Pop()
-			query := domain.Relation{
-				SubjectNamespace: node.Cur.Namespace,
-				SubjectName:      node.Cur.Name,
-				SubjectRelation:  node.Cur.Relation,
-			}
-			tuples, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, tuple := range tuples {
+			for _, tuple := range children[node.Cur] {
 				if tuple.ObjectNamespace == object.Namespace && tuple.ObjectName == object.Name && tuple.Relation == object.Relation {
-					paths = append(paths, append(node.Path, tuple))
+					if b.MaxPaths > 0 && found >= b.MaxPaths {
+						return true, nil
+					}
+					found++
+					if err := emit(appendPath(node.Path, tuple)); err != nil {
+						return false, err
+					}
 				}
 				child := domain.Node{
 					Namespace: tuple.ObjectNamespace,
 					Name:      tuple.ObjectName,
 					Relation:  tuple.Relation,
 				}
-				if searchCondition.ShouldStop(child) {
+				// a path passes a node once, only a cycle leads back to one
+				if _, ok := node.OnPath[child]; ok || searchCondition.ShouldStop(child) {
 					continue
 				}
-				copyPath := append(node.Path, tuple)
+				if err := visited.add(1); err != nil {
+					return true, nil
+				}
+				onPath := make(map[domain.Node]struct{}, len(node.OnPath)+1)
+				for pathNode := range node.OnPath {
+					onPath[pathNode] = struct{}{}
+				}
+				onPath[child] = struct{}{}
 				q.Push(NodeItem{
-					Cur:  child,
-					Path: copyPath,
+					Cur:    child,
+					Path:   appendPath(node.Path, tuple),
+					OnPath: onPath,
 				})
 
 			}
 		}
+		depth++
 	}
 
-	return paths, nil
+	return false, nil
 }
 
-func (u *RelationUsecase) GetAllObjectRelations(subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int) ([]domain.Relation, error) {
-	if err := utils.ValidateNode(subject, true); err != nil {
+func (u *RelationUsecase) GetAllObjectRelations(ctx context.Context, subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, options ...usecasedom.ReadOptions) ([]domain.Relation, error) {
+	relations := []domain.Relation{}
+	err := u.StreamAllObjectRelations(ctx, subject, searchCondition, collectCondition, maxDepth, func(relation domain.Relation) error {
+		relations = append(relations, relation)
+		return nil
+	}, options...)
+	if err != nil {
 		return nil, err
 	}
+	return relations, nil
+}
+
+func (u *RelationUsecase) StreamAllObjectRelations(ctx context.Context, subject domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, emit func(relation domain.Relation) error, options ...usecasedom.ReadOptions) error {
+	if err := utils.ValidateNode(subject, true); err != nil {
+		return err
+	}
+	// maxDepth is given explicitly, the budget bounds nodes and time
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return budgetError(ctx, budgeted, err)
+	}
+	repo = u.graph(repo, options, rules)
+	nodes := visits{max: b.MaxNodes}
 	depth := 0
 	relations := set.NewSet[domain.Relation]()
 	visited := set.NewSet[domain.Node]()
@@ -384,29 +614,30 @@ func (u *RelationUsecase) GetAllObjectRelations(subject domain.Node, searchCondi
 	visited.Add(subject)
 	q.Push(subject)
 	for !q.IsEmpty() {
+		children, err := children(budgeted, repo, q.ToSlice())
+		if err != nil {
+			return budgetError(ctx, budgeted, err)
+		}
 		qLen := q.Len()
 		for i := 0; i < qLen; i++ {
 			node, _ := q.Pop()
-			query := domain.Relation{
-				SubjectNamespace: node.Namespace,
-				SubjectName:      node.Name,
-				SubjectRelation:  node.Relation,
-			}
-			tuples, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, tuple := range tuples {
+			for _, tuple := range children[node] {
 				child := domain.Node{
 					Namespace: tuple.ObjectNamespace,
 					Name:      tuple.ObjectName,
 					Relation:  tuple.Relation,
 				}
-				if collectCondition.ShouldCollect(child) {
+				// a relation may be found from a node with and without relation
+				if collectCondition.ShouldCollect(child) && !relations.Exist(tuple) {
 					relations.Add(tuple)
+					if err := emit(tuple); err != nil {
+						return err
+					}
 				}
 				if !searchCondition.ShouldStop(child) && !visited.Exist(child) {
+					if err := nodes.add(1); err != nil {
+						return err
+					}
 					visited.Add(child)
 					q.Push(child)
 				}
@@ -418,13 +649,39 @@ func (u *RelationUsecase) GetAllObjectRelations(subject domain.Node, searchCondi
 		}
 	}
 
-	return relations.ToSlice(), nil
+	return nil
 }
 
-func (u *RelationUsecase) GetAllSubjectRelations(object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int) ([]domain.Relation, error) {
-	if err := utils.ValidateNode(object, false); err != nil {
+func (u *RelationUsecase) GetAllSubjectRelations(ctx context.Context, object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, options ...usecasedom.ReadOptions) ([]domain.Relation, error) {
+	relations := []domain.Relation{}
+	err := u.StreamAllSubjectRelations(ctx, object, searchCondition, collectCondition, maxDepth, func(relation domain.Relation) error {
+		relations = append(relations, relation)
+		return nil
+	}, options...)
+	if err != nil {
 		return nil, err
 	}
+	return relations, nil
+}
+
+func (u *RelationUsecase) StreamAllSubjectRelations(ctx context.Context, object domain.Node, searchCondition domain.SearchCondition, collectCondition domain.CollectCondition, maxDepth int, emit func(relation domain.Relation) error, options ...usecasedom.ReadOptions) error {
+	if err := utils.ValidateNode(object, false); err != nil {
+		return err
+	}
+	// maxDepth is given explicitly, the budget bounds nodes and time
+	b := budget(options)
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return budgetError(ctx, budgeted, err)
+	}
+	repo = u.graph(repo, options, rules)
+	nodes := visits{max: b.MaxNodes}
 	depth := 0
 	relations := set.NewSet[domain.Relation]()
 	visited := set.NewSet[domain.Node]()
@@ -432,29 +689,30 @@ func (u *RelationUsecase) GetAllSubjectRelations(object domain.Node, searchCondi
 	visited.Add(object)
 	q.Push(object)
 	for !q.IsEmpty() {
+		parents, err := parents(budgeted, repo, q.ToSlice())
+		if err != nil {
+			return budgetError(ctx, budgeted, err)
+		}
 		qLen := q.Len()
 		for i := 0; i < qLen; i++ {
 			node, _ := q.Pop()
-			query := domain.Relation{
-				ObjectNamespace: node.Namespace,
-				ObjectName:      node.Name,
-				Relation:        node.Relation,
-			}
-			tuples, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, tuple := range tuples {
+			for _, tuple := range parents[node] {
 				parent := domain.Node{
 					Namespace: tuple.SubjectNamespace,
 					Name:      tuple.SubjectName,
 					Relation:  tuple.SubjectRelation,
 				}
-				if collectCondition.ShouldCollect(parent) {
+				// a relation may be found from a node with and without relation
+				if collectCondition.ShouldCollect(parent) && !relations.Exist(tuple) {
 					relations.Add(tuple)
+					if err := emit(tuple); err != nil {
+						return err
+					}
 				}
 				if !searchCondition.ShouldStop(parent) && !visited.Exist(parent) {
+					if err := nodes.add(1); err != nil {
+						return err
+					}
 					visited.Add(parent)
 					q.Push(parent)
 				}
@@ -466,13 +724,18 @@ func (u *RelationUsecase) GetAllSubjectRelations(object domain.Node, searchCondi
 		}
 	}
 
-	return relations.ToSlice(), nil
+	return nil
 }
 
-func (u *RelationUsecase) GetTree(subject domain.Node, maxDepth int) (*domain.TreeNode, error) {
+func (u *RelationUsecase) GetTree(ctx context.Context, subject domain.Node, maxDepth int) (*domain.TreeNode, error) {
 	if err := utils.ValidateNode(subject, true); err != nil {
 		return &domain.TreeNode{}, err
 	}
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return &domain.TreeNode{}, err
+	}
+	repo := u.graph(u.RelationRepo, nil, rules)
 	depth := 0
 	head := &domain.TreeNode{
 		Namespace: subject.Namespace,
@@ -481,41 +744,43 @@ func (u *RelationUsecase) GetTree(subject domain.Node, maxDepth int) (*domain.Tr
 		Children:  []domain.TreeNode{},
 	}
 	q := queue.NewQueue[*domain.TreeNode]()
-	visited := set.NewSet[**domain.TreeNode]()
 	q.Push(head)
 	for !q.IsEmpty() {
 		depth++
 		if depth > maxDepth {
 			break
 		}
+		frontier := []domain.Node{}
+		for _, treeNode := range q.ToSlice() {
+			frontier = append(frontier, domain.Node{
+				Namespace: treeNode.Namespace,
+				Name:      treeNode.Name,
+				Relation:  treeNode.Relation,
+			})
+		}
+		children, err := children(ctx, repo, frontier)
+		if err != nil {
+			return head, err
+		}
 		qLen := q.Len()
 		for i := 0; i < qLen; i++ {
 			parent, err := q.Pop()
 			if err != nil {
 				return head, err
 			}
-			if visited.Exist(&parent) {
-				continue
-			}
-			visited.Add(&parent)
-			query := domain.Relation{
-				SubjectNamespace: parent.Namespace,
-				SubjectName:      parent.Name,
-				SubjectRelation:  parent.Relation,
-			}
-			relations, err := u.RelationRepo.Query(query)
-			if err != nil {
-				return head, err
-			}
-			for _, r := range relations {
-				treeNode := domain.TreeNode{
+			relations := children[frontier[i]]
+			parent.Children = make([]domain.TreeNode, len(relations))
+			for j, r := range relations {
+				parent.Children[j] = domain.TreeNode{
 					Namespace: r.ObjectNamespace,
 					Name:      r.ObjectName,
 					Relation:  r.Relation,
 					Children:  []domain.TreeNode{},
 				}
-				parent.Children = append(parent.Children, treeNode)
-				q.Push(&treeNode)
+			}
+			// the slice is complete, so pointers into it stay valid
+			for j := range parent.Children {
+				q.Push(&parent.Children[j])
 			}
 		}
 	}
@@ -523,6 +788,192 @@ func (u *RelationUsecase) GetTree(subject domain.Node, maxDepth int) (*domain.Tr
 	return head, nil
 }
 
-func (u *RelationUsecase) ClearAllRelations() error {
-	return u.RelationRepo.DeleteAll()
+func (u *RelationUsecase) Expand(ctx context.Context, object domain.Node, depth int, options ...usecasedom.ReadOptions) (*domain.UsersetTree, error) {
+	if err := utils.ValidateNode(object, false); err != nil {
+		return nil, err
+	}
+	b := budget(options)
+	if depth <= 0 {
+		depth = b.MaxDepth
+	}
+	rules, err := u.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	budgeted, cancel := withTimeout(ctx, b)
+	defer cancel()
+	repo, err := u.reader(budgeted, options)
+	if err != nil {
+		return nil, budgetError(ctx, budgeted, err)
+	}
+	repo = u.graph(repo, options, rules)
+	root := &domain.UsersetTree{
+		Namespace: object.Namespace,
+		Name:      object.Name,
+		Relation:  object.Relation,
+		Children:  []domain.UsersetTree{},
+	}
+	// a subject set reached through several others is expanded under each,
+	// so the node budget counts the nodes of the tree
+	visited := visits{max: b.MaxNodes}
+	level := []*domain.UsersetTree{root}
+	for d := 0; len(level) > 0; d++ {
+		if depth > 0 && d >= depth {
+			for _, tree := range level {
+				tree.Truncated = true
+			}
+			break
+		}
+		frontier := make([]domain.Node, len(level))
+		for i, tree := range level {
+			frontier[i] = domain.Node{Namespace: tree.Namespace, Name: tree.Name, Relation: tree.Relation}
+		}
+		parents, err := parents(budgeted, repo, frontier)
+		if err != nil {
+			return nil, budgetError(ctx, budgeted, err)
+		}
+		next := []*domain.UsersetTree{}
+		for i, tree := range level {
+			for _, tuple := range parents[frontier[i]] {
+				if err := visited.add(1); err != nil {
+					return nil, err
+				}
+				tree.Children = append(tree.Children, domain.UsersetTree{
+					Namespace: tuple.SubjectNamespace,
+					Name:      tuple.SubjectName,
+					Relation:  tuple.SubjectRelation,
+					Children:  []domain.UsersetTree{},
+				})
+			}
+			sort.Slice(tree.Children, func(a, b int) bool {
+				x, y := tree.Children[a], tree.Children[b]
+				if x.Namespace != y.Namespace {
+					return x.Namespace < y.Namespace
+				}
+				if x.Name != y.Name {
+					return x.Name < y.Name
+				}
+				return x.Relation < y.Relation
+			})
+			// the slice is complete, so pointers into it stay valid
+			for j := range tree.Children {
+				if tree.Children[j].Relation != "" {
+					next = append(next, &tree.Children[j])
+				}
+			}
+		}
+		level = next
+	}
+
+	return root, nil
+}
+
+func (u *RelationUsecase) ClearAllRelations(ctx context.Context) (string, error) {
+	token, err := u.write(ctx, func(tx sqldomain.RelationRepository) error {
+		return tx.DeleteAll(ctx)
+	})
+	if err != nil {
+		return "", err
+	}
+	if u.Cache != nil {
+		u.Cache.invalidateAll()
+	}
+	return token, nil
+}
+
+// children fetches the relations leaving every node of a BFS frontier with
+// one repository call and groups them by node. The relations of the wildcard
+// of a namespace leave each node without relation of it as well. Every
+// traversal calls it once per level, so it is where they stop once ctx is
+// done.
+func children(ctx context.Context, repo sqldomain.RelationRepository, frontier []domain.Node) (map[domain.Node][]domain.Relation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	groups, nodes := newNodeGroups(frontier)
+	// the nodes each wildcard matches, the wildcards are queried as nodes
+	// of their own so the adjacency cache keeps their relations apart
+	matched := map[domain.Node][]domain.Node{}
+	for _, node := range nodes {
+		if node.Relation != "" || node.IsWildcard() {
+			continue
+		}
+		wildcard := domain.Node{Namespace: node.Namespace, Name: domain.Wildcard}
+		if _, ok := matched[wildcard]; !ok {
+			if _, ok := groups[wildcard]; !ok {
+				nodes = append(nodes, wildcard)
+			}
+		}
+		matched[wildcard] = append(matched[wildcard], node)
+	}
+	tuples, err := repo.QueryBySubjects(ctx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, tuple := range tuples {
+		subject := subjectOf(tuple)
+		groups.add(subject, tuple)
+		for _, node := range matched[subject] {
+			groups[node] = append(groups[node], tuple)
+		}
+	}
+	return groups, nil
+}
+
+// parents is children for the relations pointing to the frontier.
+func parents(ctx context.Context, repo sqldomain.RelationRepository, frontier []domain.Node) (map[domain.Node][]domain.Relation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	groups, nodes := newNodeGroups(frontier)
+	tuples, err := repo.QueryByObjects(ctx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, tuple := range tuples {
+		groups.add(domain.Node{
+			Namespace: tuple.ObjectNamespace,
+			Name:      tuple.ObjectName,
+			Relation:  tuple.Relation,
+		}, tuple)
+	}
+	return groups, nil
+}
+
+type nodeGroups map[domain.Node][]domain.Relation
+
+// newNodeGroups returns the groups with a key per frontier node, and the
+// frontier without duplicates.
+func newNodeGroups(frontier []domain.Node) (nodeGroups, []domain.Node) {
+	groups := make(nodeGroups, len(frontier))
+	nodes := []domain.Node{}
+	for _, node := range frontier {
+		if _, ok := groups[node]; !ok {
+			groups[node] = nil
+			nodes = append(nodes, node)
+		}
+	}
+	return groups, nodes
+}
+
+// add puts tuple into the group of node, and into the group of the node
+// without relation, which the repository matches against every relation.
+func (g nodeGroups) add(node domain.Node, tuple domain.Relation) {
+	if _, ok := g[node]; ok {
+		g[node] = append(g[node], tuple)
+	}
+	if node.Relation != "" {
+		node.Relation = ""
+		if _, ok := g[node]; ok {
+			g[node] = append(g[node], tuple)
+		}
+	}
+}
+
+// appendPath returns path extended by tuple without sharing the backing
+// array of path, which sibling paths are extended from as well.
+func appendPath(path []domain.Relation, tuple domain.Relation) []domain.Relation {
+	newPath := make([]domain.Relation, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, tuple)
 }
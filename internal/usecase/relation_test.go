@@ -1,23 +1,42 @@
 package usecase_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
 	sqldom "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	usecasedom "github.com/skyrocketOoO/zanazibar-dag/domain/usecase"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/inmemory"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/kv"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
 	"github.com/skyrocketOoO/zanazibar-dag/internal/usecase"
+	"github.com/spf13/viper"
 )
 
 func TestGetAllNamespaces(t *testing.T) {
+	ctx := context.Background()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRelationRepo := sqldom.NewMockRelationRepository(ctrl)
-	mockRelationRepo.EXPECT().GetAllNamespaces().Return([]string{"foo", "bar"}, nil)
+	mockRelationRepo.EXPECT().GetAllNamespaces(gomock.Any()).Return([]string{"foo", "bar"}, nil)
 
 	usecaseRepo := usecase.NewRelationUsecase(mockRelationRepo)
 
-	nss, err := usecaseRepo.GetAllNamespaces()
+	nss, err := usecaseRepo.GetAllNamespaces(ctx)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -25,3 +44,1624 @@ func TestGetAllNamespaces(t *testing.T) {
 		t.Errorf("Unexpected error: %s", "nss should be 'foo' or 'bar")
 	}
 }
+
+func TestCheckAndShortestPathWithInmemoryRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRelationRepository()
+	// user:alice -> group:eng#member -> doc:1#viewer
+	relations := []domain.Relation{
+		{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+	}
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	relationUsecase := usecase.NewRelationUsecase(repo)
+
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	ok, err := relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if !ok {
+		t.Error("Check = false, want true")
+	}
+
+	path, err := relationUsecase.GetShortestPath(ctx, alice, doc, domain.SearchCondition{})
+	if err != nil {
+		t.Fatalf("GetShortestPath: %v", err)
+	}
+	if len(path) != 2 || path[0] != relations[0] || path[1] != relations[1] {
+		t.Errorf("GetShortestPath = %v, want %v", path, relations)
+	}
+
+	bob := domain.Node{Namespace: "user", Name: "bob"}
+	ok, err = relationUsecase.Check(ctx, bob, doc, domain.SearchCondition{})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if ok {
+		t.Error("Check = true for unrelated subject, want false")
+	}
+}
+
+func TestTraversalsWithInmemoryRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := inmemory.NewRelationRepository()
+	// user:alice -> group:eng#member -> doc:1#viewer
+	//                                -> doc:2#viewer
+	relations := []domain.Relation{
+		{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+		{ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+	}
+	for _, relation := range relations {
+		if err := repo.Create(ctx, relation); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	relationUsecase := usecase.NewRelationUsecase(repo)
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+
+	objectRelations, err := relationUsecase.GetAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 10)
+	if err != nil {
+		t.Fatalf("GetAllObjectRelations: %v", err)
+	}
+	if len(objectRelations) != 3 {
+		t.Errorf("GetAllObjectRelations = %v, want all relations", objectRelations)
+	}
+
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	subjectRelations, err := relationUsecase.GetAllSubjectRelations(ctx, doc, domain.SearchCondition{}, domain.CollectCondition{}, 10)
+	if err != nil {
+		t.Fatalf("GetAllSubjectRelations: %v", err)
+	}
+	if len(subjectRelations) != 2 {
+		t.Errorf("GetAllSubjectRelations = %v, want the relations to doc:1", subjectRelations)
+	}
+
+	tree, err := relationUsecase.GetTree(ctx, alice, 2)
+	if err != nil {
+		t.Fatalf("GetTree: %v", err)
+	}
+	if len(tree.Children) != 1 || len(tree.Children[0].Children) != 2 {
+		t.Errorf("GetTree = %+v, want alice -> eng -> two docs", tree)
+	}
+}
+
+// TestShortestPathMatchesForwardSearch compares the bidirectional search
+// with a plain BFS from the subject on a random DAG, where users belong to
+// many groups.
+func TestShortestPathMatchesForwardSearch(t *testing.T) {
+	ctx := context.Background()
+	const users, groups, edges = 4, 12, 40
+	random := rand.New(rand.NewSource(1))
+	relations := []domain.Relation{}
+	for i := 0; i < edges; i++ {
+		from, to := random.Intn(groups), random.Intn(groups)
+		if from >= to {
+			continue
+		}
+		relations = append(relations, domain.Relation{
+			ObjectNamespace: "group", ObjectName: fmt.Sprint(to), Relation: "member",
+			SubjectNamespace: "group", SubjectName: fmt.Sprint(from), SubjectRelation: "member",
+		})
+	}
+	for user := 0; user < users; user++ {
+		for group := 0; group < groups; group += 1 + random.Intn(3) {
+			relations = append(relations, domain.Relation{
+				ObjectNamespace: "group", ObjectName: fmt.Sprint(group), Relation: "member",
+				SubjectNamespace: "user", SubjectName: fmt.Sprint(user),
+			})
+		}
+	}
+	repo := inmemory.NewRelationRepository()
+	for _, relation := range relations {
+		if err := repo.CreateIfNotExists(ctx, relation); err != nil {
+			t.Fatalf("CreateIfNotExists: %v", err)
+		}
+	}
+	relationUsecase := usecase.NewRelationUsecase(repo)
+
+	conditions := map[string]domain.SearchCondition{
+		"all":        {},
+		"even names": {In: domain.Compare{Names: []string{"0", "2", "4", "6", "8", "10"}}},
+	}
+	for name, condition := range conditions {
+		for _, maxDepth := range []int{0, 2} {
+			viper.Set("main.max-search-depth", maxDepth)
+			for user := 0; user < users; user++ {
+				subject := domain.Node{Namespace: "user", Name: fmt.Sprint(user)}
+				for group := 0; group < groups; group++ {
+					object := domain.Node{Namespace: "group", Name: fmt.Sprint(group), Relation: "member"}
+					want := forwardDistance(relations, subject, object, condition, maxDepth)
+
+					ok, err := relationUsecase.Check(ctx, subject, object, condition)
+					if err != nil {
+						t.Fatalf("Check: %v", err)
+					}
+					if ok != (want > 0) {
+						t.Errorf("%s, depth %d: Check(%v, %v) = %v, want %v", name, maxDepth, subject, object, ok, want > 0)
+					}
+					path, err := relationUsecase.GetShortestPath(ctx, subject, object, condition)
+					if err != nil {
+						t.Fatalf("GetShortestPath: %v", err)
+					}
+					if len(path) != want {
+						t.Errorf("%s, depth %d: GetShortestPath(%v, %v) = %v, want %d relations", name, maxDepth, subject, object, path, want)
+						continue
+					}
+					for i := 1; i < len(path); i++ {
+						if path[i].SubjectName != path[i-1].ObjectName || condition.ShouldStop(domain.Node{Namespace: "group", Name: path[i].SubjectName, Relation: "member"}) {
+							t.Errorf("%s: GetShortestPath(%v, %v) = %v, not a path", name, subject, object, path)
+						}
+					}
+				}
+			}
+		}
+	}
+	viper.Set("main.max-search-depth", 0)
+}
+
+func TestKShortestPathsRankAllPaths(t *testing.T) {
+	ctx := context.Background()
+	random := rand.New(rand.NewSource(2))
+	const groups, k = 10, 6
+	// even groups are teams, which the preference ranks first
+	node := func(i int) domain.Node {
+		if i%2 == 0 {
+			return domain.Node{Namespace: "team", Name: fmt.Sprint(i), Relation: "member"}
+		}
+		return domain.Node{Namespace: "group", Name: fmt.Sprint(i), Relation: "member"}
+	}
+	repo := inmemory.NewRelationRepository()
+	create := func(subject, object domain.Node) {
+		relation := domain.Relation{
+			ObjectNamespace: object.Namespace, ObjectName: object.Name, Relation: object.Relation,
+			SubjectNamespace: subject.Namespace, SubjectName: subject.Name, SubjectRelation: subject.Relation,
+		}
+		if err := repo.CreateIfNotExists(ctx, relation); err != nil {
+			t.Fatalf("CreateIfNotExists: %v", err)
+		}
+	}
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	create(alice, node(0))
+	create(alice, node(1))
+	for i := 0; i < groups; i++ {
+		for j := i + 1; j < groups; j++ {
+			if random.Intn(3) == 0 {
+				create(node(i), node(j))
+			}
+		}
+	}
+	relationUsecase := usecase.NewRelationUsecase(repo)
+	preference := domain.PathPreference{Namespaces: []string{"team"}}
+	cost := func(path []domain.Relation) int {
+		c := 0
+		for _, relation := range path {
+			c += preference.Cost(relation)
+		}
+		return c
+	}
+
+	for i := 2; i < groups; i++ {
+		object := node(i)
+		all, _, err := relationUsecase.GetAllPaths(ctx, alice, object, domain.SearchCondition{})
+		if err != nil {
+			t.Fatalf("GetAllPaths: %v", err)
+		}
+		sort.SliceStable(all, func(a, b int) bool {
+			if len(all[a]) != len(all[b]) {
+				return len(all[a]) < len(all[b])
+			}
+			return cost(all[a]) < cost(all[b])
+		})
+		if len(all) > k {
+			all = all[:k]
+		}
+		paths, err := relationUsecase.GetKShortestPaths(ctx, alice, object, domain.SearchCondition{}, k, preference)
+		if err != nil {
+			t.Fatalf("GetKShortestPaths: %v", err)
+		}
+		if len(paths) != len(all) {
+			t.Errorf("GetKShortestPaths(%v) = %d paths, want %d", object, len(paths), len(all))
+			continue
+		}
+		seen := map[string]bool{}
+		for j, path := range paths {
+			if len(path) != len(all[j]) || cost(path) != cost(all[j]) {
+				t.Errorf("GetKShortestPaths(%v)[%d] = %v, want length %d and cost %d", object, j, path, len(all[j]), cost(all[j]))
+			}
+			if key := fmt.Sprint(path); seen[key] {
+				t.Errorf("GetKShortestPaths(%v) repeats %v", object, path)
+			} else {
+				seen[key] = true
+			}
+		}
+	}
+
+	if _, err := relationUsecase.GetKShortestPaths(ctx, alice, node(9), domain.SearchCondition{}, 0, preference); err == nil {
+		t.Errorf("GetKShortestPaths with k 0 succeeded")
+	}
+}
+
+func TestExpandReturnsUsersetTree(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "carol"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "group", SubjectName: "sre", SubjectRelation: "member"},
+				{ObjectNamespace: "group", ObjectName: "sre", Relation: "member", SubjectNamespace: "user", SubjectName: "bob"},
+				// not the viewer relation
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "owner", SubjectNamespace: "user", SubjectName: "dave"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+			leaf := func(namespace, name string) domain.UsersetTree {
+				return domain.UsersetTree{Namespace: namespace, Name: name, Children: []domain.UsersetTree{}}
+			}
+
+			tree, err := relationUsecase.Expand(ctx, doc, 0)
+			if err != nil {
+				t.Fatalf("Expand: %v", err)
+			}
+			want := domain.UsersetTree{Namespace: "doc", Name: "1", Relation: "viewer", Children: []domain.UsersetTree{
+				{Namespace: "group", Name: "eng", Relation: "member", Children: []domain.UsersetTree{
+					{Namespace: "group", Name: "sre", Relation: "member", Children: []domain.UsersetTree{leaf("user", "bob")}},
+					leaf("user", "alice"),
+				}},
+				leaf("user", "carol"),
+			}}
+			if !reflect.DeepEqual(*tree, want) {
+				t.Errorf("Expand = %+v, want %+v", *tree, want)
+			}
+
+			tree, err = relationUsecase.Expand(ctx, doc, 1)
+			if err != nil {
+				t.Fatalf("Expand: %v", err)
+			}
+			want = domain.UsersetTree{Namespace: "doc", Name: "1", Relation: "viewer", Children: []domain.UsersetTree{
+				{Namespace: "group", Name: "eng", Relation: "member", Children: []domain.UsersetTree{}, Truncated: true},
+				leaf("user", "carol"),
+			}}
+			if !reflect.DeepEqual(*tree, want) {
+				t.Errorf("Expand to depth 1 = %+v, want %+v", *tree, want)
+			}
+		})
+	}
+}
+
+func TestSchemaRewriteRules(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "editor", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "parent", SubjectNamespace: "folder", SubjectName: "x"},
+				{ObjectNamespace: "folder", ObjectName: "x", Relation: "viewer", SubjectNamespace: "user", SubjectName: "bob"},
+				// owner is not part of viewer
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "owner", SubjectNamespace: "user", SubjectName: "carol"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+			alice := domain.Node{Namespace: "user", Name: "alice"}
+			bob := domain.Node{Namespace: "user", Name: "bob"}
+			check := func(subject domain.Node) bool {
+				ok, err := relationUsecase.Check(ctx, subject, doc, domain.SearchCondition{})
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				return ok
+			}
+			if check(alice) || check(bob) {
+				t.Fatalf("Check found a computed relation before the schema was written")
+			}
+
+			if _, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "viewer", Rewrite: "direct + editor + parent->viewer"},
+					{Name: "editor"},
+					{Name: "parent"},
+					{Name: "owner"},
+				}},
+			}); err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			if !check(alice) {
+				t.Errorf("Check(editor as viewer) = false, want true")
+			}
+			if !check(bob) {
+				t.Errorf("Check(folder viewer as viewer) = false, want true")
+			}
+			if check(domain.Node{Namespace: "user", Name: "carol"}) {
+				t.Errorf("Check(owner as viewer) = true, want false")
+			}
+			path, err := relationUsecase.GetShortestPath(ctx, bob, doc, domain.SearchCondition{})
+			if err != nil {
+				t.Fatalf("GetShortestPath: %v", err)
+			}
+			if len(path) != 2 {
+				t.Errorf("GetShortestPath(folder viewer) = %v, want 2 relations", path)
+			}
+
+			tree, err := relationUsecase.Expand(ctx, doc, 0)
+			if err != nil {
+				t.Fatalf("Expand: %v", err)
+			}
+			want := domain.UsersetTree{Namespace: "doc", Name: "1", Relation: "viewer", Children: []domain.UsersetTree{
+				{Namespace: "doc", Name: "1", Relation: "editor", Children: []domain.UsersetTree{
+					{Namespace: "user", Name: "alice", Children: []domain.UsersetTree{}},
+				}},
+				{Namespace: "folder", Name: "x", Relation: "viewer", Children: []domain.UsersetTree{
+					{Namespace: "user", Name: "bob", Children: []domain.UsersetTree{}},
+				}},
+			}}
+			if !reflect.DeepEqual(*tree, want) {
+				t.Errorf("Expand = %+v, want %+v", *tree, want)
+			}
+
+			_, err = relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "viewer", Rewrite: "editor"},
+					{Name: "editor", Rewrite: "viewer"},
+				}},
+			})
+			if _, ok := err.(domain.InvalidSchemaError); !ok {
+				t.Errorf("WriteSchema(cyclic) error = %v, want InvalidSchemaError", err)
+			}
+			schema, err := relationUsecase.ReadSchema(ctx)
+			if err != nil {
+				t.Fatalf("ReadSchema: %v", err)
+			}
+			if schema.Version != 1 {
+				t.Errorf("ReadSchema version = %d, want 1", schema.Version)
+			}
+		})
+	}
+}
+
+func TestPermissionIntersectionAndExclusion(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "editor", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "editor", SubjectNamespace: "user", SubjectName: "bob"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "org", SubjectNamespace: "org", SubjectName: "acme"},
+				{ObjectNamespace: "org", ObjectName: "acme", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "user", SubjectName: "erin"},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "carol"},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "dave"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "banned", SubjectNamespace: "group", SubjectName: "suspended", SubjectRelation: "member"},
+				{ObjectNamespace: "group", ObjectName: "suspended", Relation: "member", SubjectNamespace: "user", SubjectName: "dave"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			if _, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "can_edit", Rewrite: "editor & org->member"},
+					{Name: "viewer", Rewrite: "reader - banned"},
+					{Name: "editor"},
+					{Name: "org"},
+					{Name: "reader"},
+					{Name: "banned"},
+				}},
+			}); err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			canEdit := domain.Node{Namespace: "doc", Name: "1", Relation: "can_edit"}
+			viewer := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+			for _, tc := range []struct {
+				subject string
+				object  domain.Node
+				want    bool
+			}{
+				{"alice", canEdit, true},
+				{"bob", canEdit, false},
+				{"carol", viewer, true},
+				{"dave", viewer, false},
+				{"erin", viewer, true},
+			} {
+				subject := domain.Node{Namespace: "user", Name: tc.subject}
+				ok, err := relationUsecase.Check(ctx, subject, tc.object, domain.SearchCondition{})
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				if ok != tc.want {
+					t.Errorf("Check(%s, %s) = %v, want %v", tc.subject, tc.object.Relation, ok, tc.want)
+				}
+				explanation, err := relationUsecase.Explain(ctx, subject, tc.object, domain.SearchCondition{})
+				if err != nil {
+					t.Fatalf("Explain: %v", err)
+				}
+				if explanation.Allowed != tc.want {
+					t.Errorf("Explain(%s, %s).Allowed = %v, want %v", tc.subject, tc.object.Relation, explanation.Allowed, tc.want)
+				}
+			}
+
+			// the exclusion denies dave, so it is the branch explained
+			explanation, err := relationUsecase.Explain(ctx, domain.Node{Namespace: "user", Name: "dave"}, viewer, domain.SearchCondition{})
+			if err != nil {
+				t.Fatalf("Explain: %v", err)
+			}
+			if explanation.Rewrite != "reader - banned" || len(explanation.Children) != 1 ||
+				explanation.Children[0].Rewrite != "banned" || !explanation.Children[0].Allowed {
+				t.Errorf("Explain(dave, viewer) = %+v, want denied by banned", *explanation)
+			}
+
+			// a subject not found for lack of depth under an exclusion
+			// would be let in
+			_, err = relationUsecase.Check(ctx, domain.Node{Namespace: "user", Name: "erin"}, viewer, domain.SearchCondition{},
+				usecasedom.ReadOptions{Budget: domain.Budget{MaxDepth: 2}})
+			if err != (domain.BudgetExceededError{Limit: domain.DepthBudget}) {
+				t.Errorf("Check out of depth under an exclusion error = %v, want a depth budget error", err)
+			}
+
+			_, err = relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{{Name: "viewer", Rewrite: "reader - (banned"}}},
+			})
+			if _, ok := err.(domain.InvalidSchemaError); !ok {
+				t.Errorf("WriteSchema(unbalanced) error = %v, want InvalidSchemaError", err)
+			}
+		})
+	}
+}
+
+func TestSchemaSubjectTypesValidateWrites(t *testing.T) {
+	ctx := context.Background()
+	viewer := func(subjectNamespace, subjectRelation string) domain.Relation {
+		return domain.Relation{
+			ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer",
+			SubjectNamespace: subjectNamespace, SubjectName: "x", SubjectRelation: subjectRelation,
+		}
+	}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, namespace := range []domain.NamespaceDefinition{
+				{Name: "group", Relations: []domain.RelationDefinition{{Name: "member", SubjectTypes: []string{"user"}}}},
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "viewer", SubjectTypes: []string{"user", "group#member"}},
+					{Name: "reader", Rewrite: "viewer"},
+				}},
+			} {
+				if _, err := relationUsecase.WriteNamespace(ctx, namespace); err != nil {
+					t.Fatalf("WriteNamespace: %v", err)
+				}
+			}
+			_, err := relationUsecase.WriteNamespace(ctx, domain.NamespaceDefinition{Name: "folder", Relations: []domain.RelationDefinition{
+				{Name: "viewer", SubjectTypes: []string{"group#owner"}},
+			}})
+			if _, ok := err.(domain.InvalidSchemaError); !ok {
+				t.Errorf("WriteNamespace(undeclared subject type) error = %v, want InvalidSchemaError", err)
+			}
+
+			for _, relation := range []domain.Relation{viewer("user", ""), viewer("group", "member")} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Errorf("Create(%v): %v", relation, err)
+				}
+			}
+			for _, relation := range []domain.Relation{
+				viewer("team", ""),
+				viewer("group", ""),
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "owner", SubjectNamespace: "user", SubjectName: "x"},
+				// computed only
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "user", SubjectName: "x"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); !errors.As(err, &domain.SchemaViolationError{}) {
+					t.Errorf("Create(%v) error = %v, want SchemaViolationError", relation, err)
+				}
+			}
+			// namespaces without definition take any relation
+			if _, err := relationUsecase.Create(ctx, domain.Relation{
+				ObjectNamespace: "team", ObjectName: "a", Relation: "anything", SubjectNamespace: "user", SubjectName: "x",
+			}, false); err != nil {
+				t.Errorf("Create(undeclared namespace): %v", err)
+			}
+
+			_, err = relationUsecase.BatchOperation(ctx, []domain.Operation{
+				{Type: domain.CreateOperation, Relation: viewer("user", "")},
+				{Type: domain.CreateOperation, Relation: viewer("team", "")},
+			})
+			var batchErr domain.BatchOperationError
+			if !errors.As(err, &batchErr) || batchErr.Index != 1 || !errors.As(err, &domain.SchemaViolationError{}) {
+				t.Errorf("BatchOperation error = %v, want a schema violation of operation 1", err)
+			}
+
+			result, err := relationUsecase.BulkImport(ctx, &sliceReader{lines: []importLine{
+				{relation: domain.Relation{ObjectNamespace: "group", ObjectName: "a", Relation: "member", SubjectNamespace: "user", SubjectName: "y"}},
+				{relation: domain.Relation{ObjectNamespace: "group", ObjectName: "a", Relation: "member", SubjectNamespace: "team", SubjectName: "y"}},
+			}}, nil)
+			if err != nil {
+				t.Fatalf("BulkImport: %v", err)
+			}
+			if result.Imported != 1 || result.ErrorCount != 1 || len(result.Errors) != 1 || result.Errors[0].Line != 2 {
+				t.Errorf("BulkImport = %+v, want line 2 rejected", result)
+			}
+
+			schema, err := relationUsecase.ReadSchema(ctx)
+			if err != nil {
+				t.Fatalf("ReadSchema: %v", err)
+			}
+			again, err := relationUsecase.WriteSchema(ctx, schema.Namespaces)
+			if err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			if again.Version != schema.Version {
+				t.Errorf("WriteSchema(latest) version = %d, want %d unchanged", again.Version, schema.Version)
+			}
+			if _, err := relationUsecase.DeleteNamespace(ctx, "doc"); err != nil {
+				t.Fatalf("DeleteNamespace: %v", err)
+			}
+			if _, err := relationUsecase.Create(ctx, viewer("team", ""), false); err != nil {
+				t.Errorf("Create after DeleteNamespace: %v", err)
+			}
+		})
+	}
+}
+
+func TestConditionalRelations(t *testing.T) {
+	ctx := context.Background()
+	params := func(values map[string]interface{}) domain.ConditionParams {
+		p, err := domain.NewConditionParams(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member",
+					Condition: "ip(client_ip) in network", ConditionParams: params(map[string]interface{}{"network": "10.0.0.0/8"})},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "bob",
+					Condition: "now < expires", ConditionParams: params(map[string]interface{}{"expires": "2000-01-01T00:00:00Z"})},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "user", SubjectName: "carol"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "user", SubjectName: "dave"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "banned", SubjectNamespace: "user", SubjectName: "dave",
+					Condition: "tier != \"gold\""},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			for _, condition := range []string{"client_ip +", "1 + \"a\" == 2", "now < 5"} {
+				_, err := relationUsecase.Create(ctx, domain.Relation{
+					ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "user", SubjectName: "alice", Condition: condition,
+				}, false)
+				if !errors.As(err, &domain.InvalidConditionError{}) {
+					t.Errorf("Create(condition %q) error = %v, want InvalidConditionError", condition, err)
+				}
+			}
+
+			viewer := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+			for _, tc := range []struct {
+				subject string
+				context domain.ConditionContext
+				want    bool
+			}{
+				{"alice", domain.ConditionContext{"client_ip": "10.1.2.3"}, true},
+				{"alice", domain.ConditionContext{"client_ip": "192.168.0.1"}, false},
+				// a missing context variable does not cross the relation
+				{"alice", nil, false},
+				// expired
+				{"bob", domain.ConditionContext{"client_ip": "10.1.2.3"}, false},
+			} {
+				subject := domain.Node{Namespace: "user", Name: tc.subject}
+				options := usecasedom.ReadOptions{Context: tc.context}
+				ok, err := relationUsecase.Check(ctx, subject, viewer, domain.SearchCondition{}, options)
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				if ok != tc.want {
+					t.Errorf("Check(%s, %v) = %v, want %v", tc.subject, tc.context, ok, tc.want)
+				}
+				path, err := relationUsecase.GetShortestPath(ctx, subject, viewer, domain.SearchCondition{}, options)
+				if err != nil {
+					t.Fatalf("GetShortestPath: %v", err)
+				}
+				if (len(path) > 0) != tc.want {
+					t.Errorf("GetShortestPath(%s, %v) = %v, want found %v", tc.subject, tc.context, path, tc.want)
+				}
+			}
+			_, err := relationUsecase.Check(ctx, domain.Node{Namespace: "user", Name: "alice"}, viewer, domain.SearchCondition{},
+				usecasedom.ReadOptions{Context: domain.ConditionContext{"client_ip": 10}})
+			if !errors.As(err, &domain.ConditionContextError{}) {
+				t.Errorf("Check(mistyped context) error = %v, want ConditionContextError", err)
+			}
+
+			if _, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "can_read", Rewrite: "reader - banned"},
+					{Name: "viewer"},
+					{Name: "reader"},
+					{Name: "banned"},
+				}},
+			}); err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			canRead := domain.Node{Namespace: "doc", Name: "1", Relation: "can_read"}
+			dave := domain.Node{Namespace: "user", Name: "dave"}
+			for _, tc := range []struct {
+				tier string
+				want bool
+			}{{"gold", true}, {"silver", false}} {
+				ok, err := relationUsecase.Check(ctx, dave, canRead, domain.SearchCondition{},
+					usecasedom.ReadOptions{Context: domain.ConditionContext{"tier": tc.tier}})
+				if err != nil {
+					t.Fatalf("Check: %v", err)
+				}
+				if ok != tc.want {
+					t.Errorf("Check(dave, tier %s) = %v, want %v", tc.tier, ok, tc.want)
+				}
+			}
+			// not crossing the ban for want of context would grant access
+			if ok, err := relationUsecase.Check(ctx, dave, canRead, domain.SearchCondition{}); err != nil || ok {
+				t.Errorf("Check(dave, no context) = %v, %v, want false", ok, err)
+			}
+			ok, err := relationUsecase.Check(ctx, domain.Node{Namespace: "user", Name: "carol"}, canRead, domain.SearchCondition{})
+			if err != nil || !ok {
+				t.Errorf("Check(carol, no context) = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+func TestDeepConditionsCompileFast(t *testing.T) {
+	// a and b stay untyped, so every == checks its left operand again with
+	// the type of the right one
+	expression := "a == b"
+	for i := 0; i < domain.MaxConditionDepth; i++ {
+		expression = "(" + expression + ") == true"
+	}
+	start := time.Now()
+	if _, err := domain.CompileCondition(expression, ""); !errors.As(err, &domain.InvalidConditionError{}) {
+		t.Errorf("CompileCondition = %v, want InvalidConditionError", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("CompileCondition took %v", elapsed)
+	}
+
+	_, err := domain.CompileCondition("("+expression+") == true", "")
+	if err == nil || !strings.Contains(err.Error(), "nested deeper") {
+		t.Errorf("CompileCondition = %v, want an error for the nesting", err)
+	}
+}
+
+func TestExpiringRelations(t *testing.T) {
+	ctx := context.Background()
+	member := domain.Relation{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+	viewer := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"}
+	at := func(d time.Duration) *time.Time {
+		t := time.Now().Add(d)
+		return &t
+	}
+	expired := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "user", SubjectName: "bob",
+		ExpiresAt: at(-time.Minute)}
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	bob := domain.Node{Namespace: "user", Name: "bob"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			relationUsecase.Cache = usecase.NewCache(100, time.Minute)
+			check := func(subject domain.Node, want bool) {
+				t.Helper()
+				ok, err := relationUsecase.Check(ctx, subject, doc, domain.SearchCondition{})
+				if err != nil || ok != want {
+					t.Fatalf("Check(%s) = %v, %v, want %v", subject.Name, ok, err, want)
+				}
+				path, err := relationUsecase.GetShortestPath(ctx, subject, doc, domain.SearchCondition{})
+				if err != nil || (len(path) > 0) != want {
+					t.Fatalf("GetShortestPath(%s) = %v, %v, want found %v", subject.Name, path, err, want)
+				}
+			}
+			expiring := member
+			expiring.ExpiresAt = at(300 * time.Millisecond)
+			for _, relation := range []domain.Relation{expiring, viewer, expired} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			check(alice, true)
+			check(bob, false)
+			// the checks before must not have been cached past the expiry
+			time.Sleep(time.Until(*expiring.ExpiresAt))
+			check(alice, false)
+
+			swept, err := relationUsecase.SweepExpired(ctx)
+			if err != nil || swept != 2 {
+				t.Fatalf("SweepExpired = %d, %v, want 2", swept, err)
+			}
+			if swept, _ := relationUsecase.SweepExpired(ctx); swept != 0 {
+				t.Errorf("SweepExpired again = %d, want 0", swept)
+			}
+			// an expired relation may be granted again before it is swept
+			expired := expired
+			expired.ExpiresAt = at(-time.Second)
+			for _, relation := range []domain.Relation{expired, member} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			expired.ExpiresAt = nil
+			if _, err := relationUsecase.Create(ctx, expired, false); err != nil {
+				t.Fatalf("Create over expired: %v", err)
+			}
+			check(alice, true)
+			check(bob, true)
+		})
+	}
+}
+
+func TestWildcardSubjects(t *testing.T) {
+	ctx := context.Background()
+	public := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "user", SubjectName: domain.Wildcard}
+	banned := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "banned", SubjectNamespace: "user", SubjectName: "dave"}
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	reader := domain.Node{Namespace: "doc", Name: "1", Relation: "reader"}
+	viewer := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			relationUsecase.Cache = usecase.NewCache(100, time.Minute)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: domain.Wildcard, Relation: "reader", SubjectNamespace: "user", SubjectName: "alice"},
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "reader", SubjectNamespace: "group", SubjectName: domain.Wildcard, SubjectRelation: "member"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); !errors.As(err, &domain.RequestBodyError{}) {
+					t.Errorf("Create(%+v) error = %v, want RequestBodyError", relation, err)
+				}
+			}
+
+			// cached before the wildcard is written
+			if ok, err := relationUsecase.Check(ctx, alice, reader, domain.SearchCondition{}); err != nil || ok {
+				t.Fatalf("Check before = %v, %v, want false", ok, err)
+			}
+			for _, relation := range []domain.Relation{public, banned} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			for _, subject := range []domain.Node{alice, {Namespace: "user", Name: domain.Wildcard}} {
+				ok, err := relationUsecase.Check(ctx, subject, reader, domain.SearchCondition{})
+				if err != nil || !ok {
+					t.Errorf("Check(%s) = %v, %v, want true", subject.Name, ok, err)
+				}
+			}
+			// a wildcard matches subjects, not subject sets or other namespaces
+			for _, subject := range []domain.Node{{Namespace: "user", Name: "alice", Relation: "friend"}, {Namespace: "bot", Name: "alice"}} {
+				ok, err := relationUsecase.Check(ctx, subject, reader, domain.SearchCondition{})
+				if err != nil || ok {
+					t.Errorf("Check(%+v) = %v, %v, want false", subject, ok, err)
+				}
+			}
+			path, err := relationUsecase.GetShortestPath(ctx, alice, reader, domain.SearchCondition{})
+			if err != nil {
+				t.Fatalf("GetShortestPath: %v", err)
+			}
+			if !reflect.DeepEqual(path, []domain.Relation{public}) {
+				t.Errorf("GetShortestPath = %+v, want the wildcard relation", path)
+			}
+			objects, err := relationUsecase.GetAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 0)
+			if err != nil {
+				t.Fatalf("GetAllObjectRelations: %v", err)
+			}
+			if !reflect.DeepEqual(objects, []domain.Relation{public}) {
+				t.Errorf("GetAllObjectRelations = %+v, want the wildcard relation", objects)
+			}
+			subjects, err := relationUsecase.GetAllSubjectRelations(ctx, reader, domain.SearchCondition{}, domain.CollectCondition{}, 0)
+			if err != nil {
+				t.Fatalf("GetAllSubjectRelations: %v", err)
+			}
+			if !reflect.DeepEqual(subjects, []domain.Relation{public}) {
+				t.Errorf("GetAllSubjectRelations = %+v, want the wildcard relation", subjects)
+			}
+			tree, err := relationUsecase.Expand(ctx, reader, 0)
+			if err != nil {
+				t.Fatalf("Expand: %v", err)
+			}
+			want := domain.UsersetTree{Namespace: "doc", Name: "1", Relation: "reader", Children: []domain.UsersetTree{
+				{Namespace: "user", Name: domain.Wildcard, Children: []domain.UsersetTree{}},
+			}}
+			if !reflect.DeepEqual(*tree, want) {
+				t.Errorf("Expand = %+v, want %+v", *tree, want)
+			}
+
+			_, err = relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "viewer", Rewrite: "reader - banned"},
+					{Name: "reader", SubjectTypes: []string{"user"}},
+					{Name: "banned"},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			// the namespace does not allow its wildcard
+			if _, err := relationUsecase.Create(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "2", Relation: "reader", SubjectNamespace: "user", SubjectName: domain.Wildcard}, false); !errors.As(err, &domain.SchemaViolationError{}) {
+				t.Errorf("Create(wildcard) error = %v, want SchemaViolationError", err)
+			}
+			_, err = relationUsecase.WriteNamespace(ctx, domain.NamespaceDefinition{Name: "doc", Relations: []domain.RelationDefinition{
+				{Name: "viewer", Rewrite: "reader - banned"},
+				{Name: "reader", SubjectTypes: []string{"user", "user:*"}},
+				{Name: "banned"},
+			}})
+			if err != nil {
+				t.Fatalf("WriteNamespace: %v", err)
+			}
+			if _, err := relationUsecase.Create(ctx, domain.Relation{ObjectNamespace: "doc", ObjectName: "2", Relation: "reader", SubjectNamespace: "user", SubjectName: domain.Wildcard}, false); err != nil {
+				t.Errorf("Create(wildcard) error = %v", err)
+			}
+			for subject, want := range map[string]bool{"alice": true, "dave": false} {
+				ok, err := relationUsecase.Check(ctx, domain.Node{Namespace: "user", Name: subject}, viewer, domain.SearchCondition{})
+				if err != nil || ok != want {
+					t.Errorf("Check(%s, viewer) = %v, %v, want %v", subject, ok, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchOperationRejectsCycleWithinBatch(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			operations := []domain.Operation{
+				{Type: domain.CreateOperation, Relation: domain.Relation{
+					ObjectNamespace: "group", ObjectName: "a", Relation: "member",
+					SubjectNamespace: "group", SubjectName: "b", SubjectRelation: "member",
+				}},
+				{Type: domain.CreateOperation, Relation: domain.Relation{
+					ObjectNamespace: "group", ObjectName: "b", Relation: "member",
+					SubjectNamespace: "group", SubjectName: "a", SubjectRelation: "member",
+				}},
+			}
+			_, err := relationUsecase.BatchOperation(ctx, operations)
+			batchErr, ok := err.(domain.BatchOperationError)
+			if !ok {
+				t.Fatalf("BatchOperation = %v, want BatchOperationError", err)
+			}
+			if batchErr.Index != 1 || batchErr.Err != (domain.CauseCycleError{}) {
+				t.Errorf("BatchOperation = %v, want a cycle at operation 1", err)
+			}
+
+			relations, _, err := repo.GetAll(ctx)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if len(relations) != 0 {
+				t.Errorf("GetAll = %v, want the batch rolled back", relations)
+			}
+		})
+	}
+}
+
+func TestCycleChecksSeeComputedRelations(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			if _, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{
+					{Name: "viewer", Rewrite: "direct + editor + parent->viewer"},
+					{Name: "editor"},
+					{Name: "parent"},
+				}},
+			}); err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+			if _, err := relationUsecase.Create(ctx, domain.Relation{
+				ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "doc", SubjectName: "1", SubjectRelation: "viewer",
+			}, false); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			for _, relation := range []domain.Relation{
+				// doc:1#viewer@doc:1#editor is computed
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "editor", SubjectNamespace: "doc", SubjectName: "1", SubjectRelation: "viewer"},
+				// computes doc:2#viewer@doc:2#viewer through parent->viewer
+				{ObjectNamespace: "doc", ObjectName: "2", Relation: "parent", SubjectNamespace: "doc", SubjectName: "2"},
+				// computes doc:1#viewer@doc:2#viewer, and doc:2#viewer@doc:1#viewer is stored
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "parent", SubjectNamespace: "doc", SubjectName: "2"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != (domain.CauseCycleError{}) {
+					t.Errorf("Create(%+v) error = %v, want CauseCycleError", relation, err)
+				}
+				_, err := relationUsecase.BulkImport(ctx, &sliceReader{lines: []importLine{{relation: relation}}}, nil)
+				if err != (domain.CauseCycleError{}) {
+					t.Errorf("BulkImport(%+v) error = %v, want CauseCycleError", relation, err)
+				}
+			}
+			relations, _, _ := repo.GetAll(ctx)
+			if len(relations) != 1 {
+				t.Errorf("GetAll = %v, want the cyclic writes rolled back", relations)
+			}
+		})
+	}
+}
+
+func TestSchemasClosingCyclesAreRejected(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "doc", ObjectName: "1", Relation: "editor", SubjectNamespace: "doc", SubjectName: "1", SubjectRelation: "viewer"},
+				{ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "doc", SubjectName: "3", SubjectRelation: "viewer"},
+				{ObjectNamespace: "doc", ObjectName: "3", Relation: "parent", SubjectNamespace: "doc", SubjectName: "2"},
+			} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			previous, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+				{Name: "doc", Relations: []domain.RelationDefinition{{Name: "viewer"}, {Name: "editor"}, {Name: "parent"}}},
+			})
+			if err != nil {
+				t.Fatalf("WriteSchema: %v", err)
+			}
+
+			for _, rewrite := range []string{
+				// computes doc:1#viewer@doc:1#editor, and doc:1#editor@doc:1#viewer is stored
+				"direct + editor",
+				// computes doc:3#viewer@doc:2#viewer, and doc:2#viewer@doc:3#viewer is stored
+				"direct + parent->viewer",
+			} {
+				_, err := relationUsecase.WriteSchema(ctx, []domain.NamespaceDefinition{
+					{Name: "doc", Relations: []domain.RelationDefinition{{Name: "viewer", Rewrite: rewrite}, {Name: "editor"}, {Name: "parent"}}},
+				})
+				if err != (domain.CauseCycleError{}) {
+					t.Errorf("WriteSchema(%q) error = %v, want CauseCycleError", rewrite, err)
+				}
+				if schema, err := relationUsecase.ReadSchema(ctx); err != nil || schema.Version != previous.Version {
+					t.Errorf("ReadSchema = %+v, %v, want version %d kept", schema, err, previous.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestBulkImport(t *testing.T) {
+	ctx := context.Background()
+	member := func(object, subject string) domain.Relation {
+		return domain.Relation{
+			ObjectNamespace: "group", ObjectName: object, Relation: "member",
+			SubjectNamespace: "group", SubjectName: subject, SubjectRelation: "member",
+		}
+	}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			if _, err := relationUsecase.Create(ctx, member("a", "b"), false); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			phases := []string{}
+			result, err := relationUsecase.BulkImport(ctx, &sliceReader{lines: []importLine{
+				{relation: member("b", "c")},
+				{relation: member("a", "b")},
+				{err: domain.RequestBodyError{}},
+				{relation: domain.Relation{ObjectNamespace: "group"}},
+				{relation: member("b", "c")},
+				{relation: member("c", "d")},
+			}}, func(progress domain.ImportProgress) {
+				if len(phases) == 0 || phases[len(phases)-1] != progress.Phase {
+					phases = append(phases, progress.Phase)
+				}
+			})
+			if err != nil {
+				t.Fatalf("BulkImport: %v", err)
+			}
+			if result.Lines != 6 || result.Imported != 2 || result.ErrorCount != 2 {
+				t.Errorf("BulkImport = %+v, want 6 lines, 2 imported and 2 errors", result)
+			}
+			if len(result.Errors) != 2 || result.Errors[0].Line != 3 || result.Errors[1].Line != 4 {
+				t.Errorf("BulkImport errors = %+v, want lines 3 and 4", result.Errors)
+			}
+			if fmt.Sprint(phases) != fmt.Sprint([]string{domain.ImportStaging, domain.ImportValidating, domain.ImportCommitting}) {
+				t.Errorf("progress phases = %v", phases)
+			}
+
+			// d -> c -> b -> a exists, a -> d closes a cycle through the graph
+			_, err = relationUsecase.BulkImport(ctx, &sliceReader{lines: []importLine{
+				{relation: member("e", "a")},
+				{relation: member("d", "a")},
+			}}, nil)
+			if err != (domain.CauseCycleError{}) {
+				t.Fatalf("BulkImport = %v, want CauseCycleError", err)
+			}
+			relations, _, _ := repo.GetAll(ctx)
+			if len(relations) != 3 {
+				t.Errorf("GetAll = %v, want the failed import rolled back", relations)
+			}
+
+			// the relations of a cycle a chunk apart, staged ones are checked
+			// a chunk at a time
+			lines := []importLine{{relation: member("x", "y")}}
+			for i := 0; i < 10000; i++ {
+				lines = append(lines, importLine{relation: member(fmt.Sprint("f", i), "z")})
+			}
+			lines = append(lines, importLine{relation: member("y", "x")})
+			_, err = relationUsecase.BulkImport(ctx, &sliceReader{lines: lines}, nil)
+			if err != (domain.CauseCycleError{}) {
+				t.Fatalf("BulkImport = %v, want CauseCycleError", err)
+			}
+		})
+	}
+}
+
+// racingRepository creates relation right before the first transaction, as
+// a writer would that commits between the checks of an import and its
+// commit.
+type racingRepository struct {
+	*sql.RelationRepository
+	relation domain.Relation
+	once     sync.Once
+}
+
+func (r *racingRepository) WithTransaction(ctx context.Context, fn func(tx sqldom.RelationRepository) error) error {
+	var err error
+	r.once.Do(func() { err = r.RelationRepository.Create(ctx, r.relation) })
+	if err != nil {
+		return err
+	}
+	return r.RelationRepository.WithTransaction(ctx, fn)
+}
+
+func TestBulkImportRechecksConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	imported := domain.Relation{ObjectNamespace: "group", ObjectName: "a", Relation: "member", SubjectNamespace: "group", SubjectName: "b", SubjectRelation: "member"}
+	racing := domain.Relation{ObjectNamespace: "group", ObjectName: "b", Relation: "member", SubjectNamespace: "group", SubjectName: "a", SubjectRelation: "member"}
+	for name, repo := range newRepos(t) {
+		sqlRepo, ok := repo.(*sql.RelationRepository)
+		if !ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(&racingRepository{RelationRepository: sqlRepo, relation: racing})
+			_, err := relationUsecase.BulkImport(ctx, &sliceReader{lines: []importLine{{relation: imported}}}, nil)
+			if err != (domain.CauseCycleError{}) {
+				t.Fatalf("BulkImport = %v, want CauseCycleError", err)
+			}
+			relations, _, _ := sqlRepo.GetAll(ctx)
+			if len(relations) != 1 || relations[0] != racing {
+				t.Errorf("GetAll = %v, want only the relation written meanwhile", relations)
+			}
+		})
+	}
+}
+
+func TestConsistencyTokens(t *testing.T) {
+	ctx := context.Background()
+	member := domain.Relation{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+	viewer := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"}
+	user := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			if _, err := relationUsecase.Create(ctx, member, false); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			granted, err := relationUsecase.Create(ctx, viewer, false)
+			if err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			revoked, err := relationUsecase.Delete(ctx, member)
+			if err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if granted == "" || revoked == granted {
+				t.Fatalf("tokens %q and %q, want two distinct tokens", granted, revoked)
+			}
+
+			ok, err := relationUsecase.Check(ctx, user, doc, domain.SearchCondition{}, usecasedom.ReadOptions{Consistency: usecasedom.Consistency{AtLeastAsFresh: revoked}})
+			if err != nil || ok {
+				t.Errorf("Check at least as fresh as the delete = %v, %v, want false", ok, err)
+			}
+			_, err = relationUsecase.Check(ctx, user, doc, domain.SearchCondition{}, usecasedom.ReadOptions{Consistency: usecasedom.Consistency{AtLeastAsFresh: "x"}})
+			if err != (domain.InvalidTokenError{}) {
+				t.Errorf("Check with a malformed token = %v, want InvalidTokenError", err)
+			}
+
+			ok, err = relationUsecase.Check(ctx, user, doc, domain.SearchCondition{}, usecasedom.ReadOptions{Consistency: usecasedom.Consistency{ExactSnapshot: granted}})
+			if err == (domain.SnapshotUnavailableError{}) {
+				t.Skip("repository keeps no history")
+			}
+			if err != nil || !ok {
+				t.Errorf("Check at the snapshot before the delete = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	ctx := context.Background()
+	member := domain.Relation{ObjectNamespace: "group", ObjectName: "eng", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+	viewer := domain.Relation{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "eng", SubjectRelation: "member"}
+	other := domain.Relation{ObjectNamespace: "doc", ObjectName: "2", Relation: "viewer", SubjectNamespace: "user", SubjectName: "bob"}
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			relationUsecase.Cache = usecase.NewCache(100, time.Minute)
+			check := func(want bool) {
+				t.Helper()
+				ok, err := relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{})
+				if err != nil || ok != want {
+					t.Fatalf("Check = %v, %v, want %v", ok, err, want)
+				}
+			}
+			for _, relation := range []domain.Relation{member, viewer} {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+
+			check(true)
+			check(true)
+			if stats := relationUsecase.CacheStats().Checks; stats.Hits != 1 || stats.Misses != 1 {
+				t.Errorf("check counters = %+v, want 1 hit and 1 miss", stats)
+			}
+
+			// the delete is on the path of the cached check
+			if _, err := relationUsecase.Delete(ctx, member); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			check(false)
+			// a write alice does not reach keeps the check cached
+			if _, err := relationUsecase.Create(ctx, other, false); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			check(false)
+			if stats := relationUsecase.CacheStats().Checks; stats.Hits != 2 || stats.Invalidations != 1 {
+				t.Errorf("check counters = %+v, want 2 hits and 1 invalidation", stats)
+			}
+			if _, err := relationUsecase.Create(ctx, member, false); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			check(true)
+
+			// lookups of the traversals are cached and invalidated per node
+			lookup := func(want int) {
+				t.Helper()
+				relations, err := relationUsecase.GetAllSubjectRelations(ctx, doc, domain.SearchCondition{}, domain.CollectCondition{}, 2)
+				if err != nil || len(relations) != want {
+					t.Fatalf("GetAllSubjectRelations = %v, %v, want %d relations", relations, err, want)
+				}
+			}
+			lookup(2)
+			lookup(2)
+			if stats := relationUsecase.CacheStats().Lookups; stats.Hits == 0 {
+				t.Errorf("lookup counters = %+v, want hits", stats)
+			}
+			if _, err := relationUsecase.Delete(ctx, member); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			lookup(1)
+		})
+	}
+}
+
+func TestCacheInvalidationPastBudgetDropsChecks(t *testing.T) {
+	ctx := context.Background()
+	alice := domain.Node{Namespace: "user", Name: "alice"}
+	doc := domain.Node{Namespace: "doc", Name: "1", Relation: "viewer"}
+	// user:alice -> group:0#member -> group:1#member -> group:2#member -> doc:1#viewer
+	relations := []domain.Relation{
+		{ObjectNamespace: "group", ObjectName: "0", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+		{ObjectNamespace: "group", ObjectName: "1", Relation: "member", SubjectNamespace: "group", SubjectName: "0", SubjectRelation: "member"},
+		{ObjectNamespace: "group", ObjectName: "2", Relation: "member", SubjectNamespace: "group", SubjectName: "1", SubjectRelation: "member"},
+		{ObjectNamespace: "doc", ObjectName: "1", Relation: "viewer", SubjectNamespace: "group", SubjectName: "2", SubjectRelation: "member"},
+	}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			relationUsecase.Cache = usecase.NewCache(100, time.Minute)
+			for _, relation := range relations {
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			if ok, err := relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{}); err != nil || !ok {
+				t.Fatalf("Check = %v, %v, want true", ok, err)
+			}
+
+			// four nodes reach group:2#member, the search for them gives up
+			viper.Set("main.max-visited-nodes", 2)
+			_, err := relationUsecase.Delete(ctx, relations[3])
+			viper.Set("main.max-visited-nodes", 0)
+			if err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if ok, err := relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{}); err != nil || ok {
+				t.Errorf("Check = %v, %v, want the cached check dropped", ok, err)
+			}
+		})
+	}
+}
+
+func TestTraversalsStopOnCancel(t *testing.T) {
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			// user:alice -> group:0#member -> ... -> group:9#member
+			chain := domain.Relation{ObjectNamespace: "group", ObjectName: "0", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"}
+			for i := 0; i < 10; i++ {
+				if _, err := relationUsecase.Create(context.Background(), chain, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+				chain = domain.Relation{
+					ObjectNamespace: "group", ObjectName: fmt.Sprint(i + 1), Relation: "member",
+					SubjectNamespace: "group", SubjectName: fmt.Sprint(i), SubjectRelation: "member",
+				}
+			}
+			alice := domain.Node{Namespace: "user", Name: "alice"}
+			last := domain.Node{Namespace: "group", Name: "9", Relation: "member"}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := relationUsecase.Check(ctx, alice, last, domain.SearchCondition{}); !errors.Is(err, context.Canceled) {
+				t.Errorf("Check = %v, want context.Canceled", err)
+			}
+			if _, _, err := relationUsecase.GetAllPaths(ctx, alice, last, domain.SearchCondition{}); !errors.Is(err, context.Canceled) {
+				t.Errorf("GetAllPaths = %v, want context.Canceled", err)
+			}
+			if _, err := relationUsecase.GetAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 0); !errors.Is(err, context.Canceled) {
+				t.Errorf("GetAllObjectRelations = %v, want context.Canceled", err)
+			}
+			if _, err := relationUsecase.Create(ctx, domain.Relation{ObjectNamespace: "group", ObjectName: "9", Relation: "member", SubjectNamespace: "user", SubjectName: "bob"}, false); !errors.Is(err, context.Canceled) {
+				t.Errorf("Create = %v, want context.Canceled", err)
+			}
+		})
+	}
+}
+
+func TestTraversalBudgets(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			// user:alice -> 3 layers of 3 groups, each member of every group
+			// of the next layer -> doc:x#viewer, 27 paths of 4 relations
+			const layers, width = 3, 3
+			create := func(relation domain.Relation) {
+				t.Helper()
+				if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			group := func(layer, i int) string { return fmt.Sprintf("%d-%d", layer, i) }
+			for i := 0; i < width; i++ {
+				create(domain.Relation{ObjectNamespace: "group", ObjectName: group(0, i), Relation: "member", SubjectNamespace: "user", SubjectName: "alice"})
+				create(domain.Relation{ObjectNamespace: "doc", ObjectName: "x", Relation: "viewer", SubjectNamespace: "group", SubjectName: group(layers-1, i), SubjectRelation: "member"})
+				for layer := 1; layer < layers; layer++ {
+					for j := 0; j < width; j++ {
+						create(domain.Relation{
+							ObjectNamespace: "group", ObjectName: group(layer, j), Relation: "member",
+							SubjectNamespace: "group", SubjectName: group(layer-1, i), SubjectRelation: "member",
+						})
+					}
+				}
+			}
+			alice := domain.Node{Namespace: "user", Name: "alice"}
+			doc := domain.Node{Namespace: "doc", Name: "x", Relation: "viewer"}
+			withBudget := func(budget domain.Budget) usecasedom.ReadOptions {
+				return usecasedom.ReadOptions{Budget: budget}
+			}
+
+			paths, truncated, err := relationUsecase.GetAllPaths(ctx, alice, doc, domain.SearchCondition{})
+			if err != nil || truncated || len(paths) != 27 {
+				t.Fatalf("GetAllPaths = %d paths, %v, %v, want 27 complete", len(paths), truncated, err)
+			}
+			paths, truncated, err = relationUsecase.GetAllPaths(ctx, alice, doc, domain.SearchCondition{}, withBudget(domain.Budget{MaxPaths: 5}))
+			if err != nil || !truncated || len(paths) != 5 {
+				t.Errorf("GetAllPaths with 5 paths = %d paths, %v, %v, want 5 truncated", len(paths), truncated, err)
+			}
+			paths, truncated, err = relationUsecase.GetAllPaths(ctx, alice, doc, domain.SearchCondition{}, withBudget(domain.Budget{MaxDepth: 2}))
+			if err != nil || !truncated || len(paths) != 0 {
+				t.Errorf("GetAllPaths with depth 2 = %d paths, %v, %v, want none truncated", len(paths), truncated, err)
+			}
+			paths, truncated, err = relationUsecase.GetAllPaths(ctx, alice, doc, domain.SearchCondition{}, withBudget(domain.Budget{MaxNodes: 10}))
+			if err != nil || !truncated || len(paths) == 27 {
+				t.Errorf("GetAllPaths with 10 nodes = %d paths, %v, %v, want truncated", len(paths), truncated, err)
+			}
+
+			_, err = relationUsecase.GetAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 10, withBudget(domain.Budget{MaxNodes: 4}))
+			if err != (domain.BudgetExceededError{Limit: domain.NodeBudget}) {
+				t.Errorf("GetAllObjectRelations with 4 nodes = %v, want node budget exceeded", err)
+			}
+			if _, err := relationUsecase.GetAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 10); err != nil {
+				t.Errorf("GetAllObjectRelations = %v", err)
+			}
+
+			ok, err := relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{}, withBudget(domain.Budget{MaxDepth: 3}))
+			if err != nil || ok {
+				t.Errorf("Check with depth 3 = %v, %v, want false", ok, err)
+			}
+			ok, err = relationUsecase.Check(ctx, alice, doc, domain.SearchCondition{}, withBudget(domain.Budget{MaxDepth: 4}))
+			if err != nil || !ok {
+				t.Errorf("Check with depth 4 = %v, %v, want true", ok, err)
+			}
+		})
+	}
+}
+
+func TestGetAllPathsOnCycle(t *testing.T) {
+	// the walk must end on its own, not by running out of time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	member := func(object, subject string) domain.Relation {
+		return domain.Relation{
+			ObjectNamespace: "group", ObjectName: object, Relation: "member",
+			SubjectNamespace: "group", SubjectName: subject, SubjectRelation: "member",
+		}
+	}
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			// written to the repository, the usecase rejects the cycle
+			for _, relation := range []domain.Relation{
+				{ObjectNamespace: "group", ObjectName: "a", Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+				member("b", "a"),
+				member("a", "b"),
+				{ObjectNamespace: "doc", ObjectName: "x", Relation: "viewer", SubjectNamespace: "group", SubjectName: "b", SubjectRelation: "member"},
+			} {
+				if err := repo.Create(ctx, relation); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+			}
+			paths, truncated, err := relationUsecase.GetAllPaths(ctx, domain.Node{Namespace: "user", Name: "alice"},
+				domain.Node{Namespace: "doc", Name: "x", Relation: "viewer"}, domain.SearchCondition{},
+				usecasedom.ReadOptions{Budget: domain.Budget{}})
+			if err != nil || truncated || len(paths) != 1 || len(paths[0]) != 3 {
+				t.Errorf("GetAllPaths = %v, %v, %v, want the one path of 3 relations", paths, truncated, err)
+			}
+		})
+	}
+}
+
+func TestStreamsStopWhenEmitFails(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			// user:alice -> group:0..4#member -> doc:x#viewer
+			for i := 0; i < 5; i++ {
+				for _, relation := range []domain.Relation{
+					{ObjectNamespace: "group", ObjectName: fmt.Sprint(i), Relation: "member", SubjectNamespace: "user", SubjectName: "alice"},
+					{ObjectNamespace: "doc", ObjectName: "x", Relation: "viewer", SubjectNamespace: "group", SubjectName: fmt.Sprint(i), SubjectRelation: "member"},
+				} {
+					if _, err := relationUsecase.Create(ctx, relation, false); err != nil {
+						t.Fatalf("Create: %v", err)
+					}
+				}
+			}
+			alice := domain.Node{Namespace: "user", Name: "alice"}
+			doc := domain.Node{Namespace: "doc", Name: "x", Relation: "viewer"}
+			stop := errors.New("stop")
+
+			emitted := 0
+			_, err := relationUsecase.StreamAllPaths(ctx, alice, doc, domain.SearchCondition{}, func(path []domain.Relation) error {
+				if emitted++; emitted == 2 {
+					return stop
+				}
+				return nil
+			})
+			if err != stop || emitted != 2 {
+				t.Errorf("StreamAllPaths = %v after %d paths, want stop after 2", err, emitted)
+			}
+
+			emitted = 0
+			err = relationUsecase.StreamAllObjectRelations(ctx, alice, domain.SearchCondition{}, domain.CollectCondition{}, 5, func(relation domain.Relation) error {
+				if emitted++; emitted == 3 {
+					return stop
+				}
+				return nil
+			})
+			if err != stop || emitted != 3 {
+				t.Errorf("StreamAllObjectRelations = %v after %d relations, want stop after 3", err, emitted)
+			}
+
+			relations, err := relationUsecase.GetAllSubjectRelations(ctx, doc, domain.SearchCondition{}, domain.CollectCondition{}, 5)
+			if err != nil || len(relations) != 10 {
+				t.Errorf("GetAllSubjectRelations = %d relations, %v, want 10", len(relations), err)
+			}
+		})
+	}
+}
+
+// TestConcurrentCreatesKeepDag races writers creating edges in both
+// directions between a few nodes, and checks no cycle got through.
+func TestConcurrentCreatesKeepDag(t *testing.T) {
+	ctx := context.Background()
+	for name, repo := range newRepos(t) {
+		t.Run(name, func(t *testing.T) {
+			relationUsecase := usecase.NewRelationUsecase(repo)
+			const nodes, writers, createsPerWriter = 6, 8, 40
+
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					random := rand.New(rand.NewSource(seed))
+					for i := 0; i < createsPerWriter; i++ {
+						from, to := random.Intn(nodes), random.Intn(nodes)
+						if from == to {
+							continue
+						}
+						_, err := relationUsecase.Create(ctx, domain.Relation{
+							ObjectNamespace:  "group",
+							ObjectName:       fmt.Sprint(to),
+							Relation:         "member",
+							SubjectNamespace: "group",
+							SubjectName:      fmt.Sprint(from),
+							SubjectRelation:  "member",
+						}, true)
+						if _, ok := err.(domain.CauseCycleError); err != nil && !ok {
+							t.Errorf("Create: %v", err)
+						}
+					}
+				}(int64(w))
+			}
+			wg.Wait()
+
+			relations, _, err := repo.GetAll(ctx)
+			if err != nil {
+				t.Fatalf("GetAll: %v", err)
+			}
+			if cycle := findCycle(relations); cycle != nil {
+				t.Errorf("graph has a cycle through %v", cycle)
+			}
+		})
+	}
+}
+
+// newRepos returns every backend that can run in the test environment.
+// Postgres is included when TEST_POSTGRES is set, using the POSTGRES_*
+// variables of sql.InitDb.
+func newRepos(t *testing.T) map[string]sqldom.RelationRepository {
+	ctx := context.Background()
+	repos := map[string]sqldom.RelationRepository{
+		"inmemory": inmemory.NewRelationRepository(),
+	}
+	kvRepo, err := kv.NewRelationRepository(filepath.Join(t.TempDir(), "kv.db"))
+	if err != nil {
+		t.Fatalf("kv.NewRelationRepository: %v", err)
+	}
+	t.Cleanup(func() { kvRepo.Close() })
+	repos["kv"] = kvRepo
+	dbTypes := []string{"sqlite"}
+	if os.Getenv("TEST_POSTGRES") != "" {
+		dbTypes = append(dbTypes, "postgres")
+	}
+	for _, dbType := range dbTypes {
+		viper.Set("db.type", dbType)
+		viper.Set("db.sqlite.path", filepath.Join(t.TempDir(), "test.db"))
+		db, err := sql.InitDb()
+		if err != nil {
+			t.Fatalf("InitDb: %v", err)
+		}
+		ormRepo, err := sql.NewOrmRepository(db)
+		if err != nil {
+			t.Fatalf("NewOrmRepository: %v", err)
+		}
+		if err := ormRepo.RelationshipRepo.DeleteAll(ctx); err != nil {
+			t.Fatalf("DeleteAll: %v", err)
+		}
+		repos[dbType] = &ormRepo.RelationshipRepo
+	}
+	return repos
+}
+
+// forwardDistance returns the length of the shortest path from subject to
+// object found by a BFS from the subject, or 0 if there is none.
+func forwardDistance(relations []domain.Relation, subject, object domain.Node, condition domain.SearchCondition, maxDepth int) int {
+	visited := map[domain.Node]bool{subject: true}
+	frontier := []domain.Node{subject}
+	for depth := 1; len(frontier) > 0 && (maxDepth <= 0 || depth <= maxDepth); depth++ {
+		next := []domain.Node{}
+		for _, node := range frontier {
+			for _, relation := range relations {
+				from := domain.Node{Namespace: relation.SubjectNamespace, Name: relation.SubjectName, Relation: relation.SubjectRelation}
+				to := domain.Node{Namespace: relation.ObjectNamespace, Name: relation.ObjectName, Relation: relation.Relation}
+				if from != node {
+					continue
+				}
+				if to == object {
+					return depth
+				}
+				if !visited[to] && !condition.ShouldStop(to) {
+					visited[to] = true
+					next = append(next, to)
+				}
+			}
+		}
+		frontier = next
+	}
+	return 0
+}
+
+// findCycle returns the nodes left over by a topological sort, which are
+// on or behind a cycle, or nil if the relations form a DAG.
+func findCycle(relations []domain.Relation) []domain.Node {
+	inDegree := map[domain.Node]int{}
+	edges := map[domain.Node][]domain.Node{}
+	for _, relation := range relations {
+		from := domain.Node{Namespace: relation.SubjectNamespace, Name: relation.SubjectName, Relation: relation.SubjectRelation}
+		to := domain.Node{Namespace: relation.ObjectNamespace, Name: relation.ObjectName, Relation: relation.Relation}
+		edges[from] = append(edges[from], to)
+		inDegree[to]++
+		if _, ok := inDegree[from]; !ok {
+			inDegree[from] = 0
+		}
+	}
+	ready := []domain.Node{}
+	for node, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, node)
+		}
+	}
+	for len(ready) > 0 {
+		node := ready[len(ready)-1]
+		ready = ready[:len(ready)-1]
+		delete(inDegree, node)
+		for _, next := range edges[node] {
+			inDegree[next]--
+			if inDegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(inDegree) == 0 {
+		return nil
+	}
+	cycle := []domain.Node{}
+	for node := range inDegree {
+		cycle = append(cycle, node)
+	}
+	return cycle
+}
+
+type importLine struct {
+	relation domain.Relation
+	err      error
+}
+
+type sliceReader struct {
+	lines []importLine
+}
+
+func (r *sliceReader) Read() (domain.Relation, error) {
+	if len(r.lines) == 0 {
+		return domain.Relation{}, io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return line.relation, line.err
+}
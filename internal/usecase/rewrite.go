@@ -0,0 +1,397 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+// rewriteRules is a schema compiled for the traversals, indexed both ways.
+// The traversals follow the relations defined by unions, the ones defined
+// with intersection or exclusion are evaluated by the permission engine
+// only.
+type rewriteRules struct {
+	version uint64
+	// declared holds the relations of every declared namespace.
+	declared map[string]map[string]domain.RelationDefinition
+	rewrites map[relationKey]domain.Rewrite
+	// computed is set if a rewrite is not just direct, operators if one has
+	// an intersection or exclusion
+	computed  bool
+	operators bool
+	// terms holds the terms of the relations defined by unions.
+	terms map[relationKey][]domain.RewriteTerm
+	// computedBy lists the relations computed from a relation of the same
+	// object.
+	computedBy map[relationKey][]string
+	// arrowsBy lists the tupleset->relation terms by their relation.
+	arrowsBy map[string][]arrow
+}
+
+type relationKey struct {
+	namespace string
+	relation  string
+}
+
+// arrow is the term tupleset->relation of the definition of relation in
+// namespace, whose relation the arrowsBy key is.
+type arrow struct {
+	namespace string
+	relation  string
+	tupleset  string
+}
+
+// compileRules returns the rules of schema, nil if it declares no
+// namespace. The schema was validated when written.
+func compileRules(schema domain.Schema) *rewriteRules {
+	rules := &rewriteRules{
+		version:    schema.Version,
+		declared:   map[string]map[string]domain.RelationDefinition{},
+		rewrites:   map[relationKey]domain.Rewrite{},
+		terms:      map[relationKey][]domain.RewriteTerm{},
+		computedBy: map[relationKey][]string{},
+		arrowsBy:   map[string][]arrow{},
+	}
+	for _, namespace := range schema.Namespaces {
+		rules.declared[namespace.Name] = map[string]domain.RelationDefinition{}
+		for _, definition := range namespace.Relations {
+			rules.declared[namespace.Name][definition.Name] = definition
+			rewrite, err := domain.ParseRewrite(definition.Rewrite)
+			if err != nil {
+				continue
+			}
+			key := relationKey{namespace: namespace.Name, relation: definition.Name}
+			rules.rewrites[key] = rewrite
+			if rewrite.Operator != "" || !rewrite.Term.Direct() {
+				rules.computed = true
+			}
+			if !rewrite.Union() {
+				rules.operators = true
+				continue
+			}
+			terms := rewrite.Terms()
+			rules.terms[key] = terms
+			for _, term := range terms {
+				switch {
+				case term.Direct():
+				case term.Tupleset == "":
+					from := relationKey{namespace: namespace.Name, relation: term.Relation}
+					rules.computedBy[from] = append(rules.computedBy[from], definition.Name)
+				default:
+					rules.arrowsBy[term.Relation] = append(rules.arrowsBy[term.Relation], arrow{
+						namespace: namespace.Name,
+						relation:  definition.Name,
+						tupleset:  term.Tupleset,
+					})
+				}
+			}
+		}
+	}
+	if len(rules.declared) == 0 {
+		return nil
+	}
+	return rules
+}
+
+// computes reports whether the rules compute relations, otherwise the
+// traversals and searches of the repository see the stored ones only.
+func (r *rewriteRules) computes() bool {
+	return r != nil && r.computed
+}
+
+// schemaVersion returns the version of the schema of the rules, 0 for nil
+// ones, which compute nothing whatever their version.
+func (r *rewriteRules) schemaVersion() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.version
+}
+
+// allows rejects relation with a domain.SchemaViolationError if the schema
+// declares its object namespace and the relation or its subject is not
+// declared for it.
+func (r *rewriteRules) allows(relation domain.Relation) error {
+	if r == nil {
+		return nil
+	}
+	relations, ok := r.declared[relation.ObjectNamespace]
+	if !ok {
+		return nil
+	}
+	definition, ok := relations[relation.Relation]
+	if !ok {
+		return domain.SchemaViolationError{Reason: fmt.Sprintf("relation %s#%s is not declared", relation.ObjectNamespace, relation.Relation)}
+	}
+	if !r.rewrites[relationKey{namespace: relation.ObjectNamespace, relation: relation.Relation}].Direct() {
+		return domain.SchemaViolationError{Reason: fmt.Sprintf("relation %s#%s is computed only", relation.ObjectNamespace, relation.Relation)}
+	}
+	if len(definition.SubjectTypes) == 0 {
+		return nil
+	}
+	subjectType := relation.SubjectNamespace
+	if relation.SubjectName == domain.Wildcard {
+		subjectType += ":" + domain.Wildcard
+	} else if relation.SubjectRelation != "" {
+		subjectType += "#" + relation.SubjectRelation
+	}
+	for _, allowed := range definition.SubjectTypes {
+		if allowed == subjectType {
+			return nil
+		}
+	}
+	return domain.SchemaViolationError{Reason: fmt.Sprintf("relation %s#%s does not take subjects of type %s", relation.ObjectNamespace, relation.Relation, subjectType)}
+}
+
+// adds returns the relations storing relation adds to the graph the rules
+// define: relation itself, and if it is a tupleset the relations computed
+// through it, like doc:1#viewer@folder:x#viewer for doc:1#parent@folder:x
+// and parent->viewer.
+func (r *rewriteRules) adds(relation domain.Relation) []domain.Relation {
+	relations := []domain.Relation{relation}
+	if r == nil {
+		return relations
+	}
+	for subjectRelation, arrows := range r.arrowsBy {
+		for _, a := range arrows {
+			if a.namespace != relation.ObjectNamespace || a.tupleset != relation.Relation {
+				continue
+			}
+			relations = append(relations, domain.Relation{
+				ObjectNamespace:  relation.ObjectNamespace,
+				ObjectName:       relation.ObjectName,
+				Relation:         a.relation,
+				SubjectNamespace: relation.SubjectNamespace,
+				SubjectName:      relation.SubjectName,
+				SubjectRelation:  subjectRelation,
+			})
+		}
+	}
+	return relations
+}
+
+// sources returns the relations the rules compute others from: those of
+// computedBy, and the tuplesets of the arrows.
+func (r *rewriteRules) sources() []relationKey {
+	if r == nil {
+		return nil
+	}
+	seen := map[relationKey]struct{}{}
+	keys := []relationKey{}
+	add := func(key relationKey) {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	for key := range r.computedBy {
+		add(key)
+	}
+	for _, arrows := range r.arrowsBy {
+		for _, a := range arrows {
+			add(relationKey{namespace: a.namespace, relation: a.tupleset})
+		}
+	}
+	return keys
+}
+
+// direct reports whether the traversals follow the stored relations of
+// relation in namespace, which they do unless its definition leaves out
+// direct or is no union.
+func (r *rewriteRules) direct(namespace, relation string) bool {
+	key := relationKey{namespace: namespace, relation: relation}
+	if _, ok := r.rewrites[key]; !ok {
+		return true
+	}
+	for _, term := range r.terms[key] {
+		if term.Direct() {
+			return true
+		}
+	}
+	return false
+}
+
+// rewriteOf returns the rewrite of relation in namespace, direct if it has
+// no definition.
+func (r *rewriteRules) rewriteOf(namespace, relation string) domain.Rewrite {
+	if r == nil {
+		return domain.Rewrite{}
+	}
+	return r.rewrites[relationKey{namespace: namespace, relation: relation}]
+}
+
+// rewrite returns repo with the relations the rules compute, repo itself if
+// there are none.
+func rewrite(repo sqldomain.RelationRepository, rules *rewriteRules) sqldomain.RelationRepository {
+	if !rules.computes() {
+		return repo
+	}
+	return &rewriteRepository{RelationRepository: repo, rules: rules}
+}
+
+// rewriteRepository serves QueryBySubjects and QueryByObjects of the graph
+// the schema defines: the stored relations of the relations that include
+// them, and the computed ones. A relation computed from editor of doc:1
+// reads as doc:1#viewer@doc:1#editor, one computed through parent->viewer
+// and doc:1#parent@folder:x as doc:1#viewer@folder:x#viewer, so the
+// traversals walk them like stored ones. Tupleset relations are read as
+// stored. It hides the optional interfaces of the repository it wraps, whose
+// own searches do not know the schema.
+type rewriteRepository struct {
+	sqldomain.RelationRepository
+	rules *rewriteRules
+}
+
+func (r *rewriteRepository) QueryByObjects(ctx context.Context, objects []domain.Node) ([]domain.Relation, error) {
+	stored, err := r.RelationRepository.QueryByObjects(ctx, objects)
+	if err != nil {
+		return nil, err
+	}
+	relations := newRelationList()
+	for _, relation := range stored {
+		if r.rules.direct(relation.ObjectNamespace, relation.Relation) {
+			relations.add(relation)
+		}
+	}
+
+	// the objects pointing to each tupleset, and the relation computed on
+	// them from the objects the tupleset points to
+	type pending struct {
+		object   domain.Node
+		relation string
+	}
+	tuplesets := []domain.Node{}
+	arrows := map[domain.Node][]pending{}
+	for _, object := range objects {
+		for _, term := range r.rules.terms[relationKey{namespace: object.Namespace, relation: object.Relation}] {
+			switch {
+			case term.Direct():
+			case term.Tupleset == "":
+				relations.add(domain.Relation{
+					ObjectNamespace:  object.Namespace,
+					ObjectName:       object.Name,
+					Relation:         object.Relation,
+					SubjectNamespace: object.Namespace,
+					SubjectName:      object.Name,
+					SubjectRelation:  term.Relation,
+				})
+			default:
+				tupleset := domain.Node{Namespace: object.Namespace, Name: object.Name, Relation: term.Tupleset}
+				if _, ok := arrows[tupleset]; !ok {
+					tuplesets = append(tuplesets, tupleset)
+				}
+				arrows[tupleset] = append(arrows[tupleset], pending{object: object, relation: term.Relation})
+			}
+		}
+	}
+	if len(tuplesets) == 0 {
+		return relations.relations, nil
+	}
+
+	tuples, err := r.RelationRepository.QueryByObjects(ctx, tuplesets)
+	if err != nil {
+		return nil, err
+	}
+	for _, tuple := range tuples {
+		for _, p := range arrows[objectOf(tuple)] {
+			relations.add(domain.Relation{
+				ObjectNamespace:  p.object.Namespace,
+				ObjectName:       p.object.Name,
+				Relation:         p.object.Relation,
+				SubjectNamespace: tuple.SubjectNamespace,
+				SubjectName:      tuple.SubjectName,
+				SubjectRelation:  p.relation,
+			})
+		}
+	}
+	return relations.relations, nil
+}
+
+// QueryBySubjects computes relations for subject sets only, a subject
+// without relation is no relation of its object.
+func (r *rewriteRepository) QueryBySubjects(ctx context.Context, subjects []domain.Node) ([]domain.Relation, error) {
+	stored, err := r.RelationRepository.QueryBySubjects(ctx, subjects)
+	if err != nil {
+		return nil, err
+	}
+	relations := newRelationList()
+	for _, relation := range stored {
+		if r.rules.direct(relation.ObjectNamespace, relation.Relation) {
+			relations.add(relation)
+		}
+	}
+
+	// the subject sets of each entity computed through tuplesets
+	entities := []domain.Node{}
+	arrows := map[domain.Node][]domain.Node{}
+	for _, subject := range subjects {
+		if subject.Relation == "" {
+			continue
+		}
+		for _, relation := range r.rules.computedBy[relationKey{namespace: subject.Namespace, relation: subject.Relation}] {
+			relations.add(domain.Relation{
+				ObjectNamespace:  subject.Namespace,
+				ObjectName:       subject.Name,
+				Relation:         relation,
+				SubjectNamespace: subject.Namespace,
+				SubjectName:      subject.Name,
+				SubjectRelation:  subject.Relation,
+			})
+		}
+		if len(r.rules.arrowsBy[subject.Relation]) > 0 {
+			entity := entityOf(subject)
+			if _, ok := arrows[entity]; !ok {
+				entities = append(entities, entity)
+			}
+			arrows[entity] = append(arrows[entity], subject)
+		}
+	}
+	if len(entities) == 0 {
+		return relations.relations, nil
+	}
+
+	// an entity without relation matches the tupleset relations pointing to
+	// it whatever their subject relation
+	tuples, err := r.RelationRepository.QueryBySubjects(ctx, entities)
+	if err != nil {
+		return nil, err
+	}
+	for _, tuple := range tuples {
+		for _, subject := range arrows[entityOf(subjectOf(tuple))] {
+			for _, a := range r.rules.arrowsBy[subject.Relation] {
+				if tuple.ObjectNamespace != a.namespace || tuple.Relation != a.tupleset {
+					continue
+				}
+				relations.add(domain.Relation{
+					ObjectNamespace:  tuple.ObjectNamespace,
+					ObjectName:       tuple.ObjectName,
+					Relation:         a.relation,
+					SubjectNamespace: subject.Namespace,
+					SubjectName:      subject.Name,
+					SubjectRelation:  subject.Relation,
+				})
+			}
+		}
+	}
+	return relations.relations, nil
+}
+
+// relationList collects relations without duplicates, in the order added.
+// found holds their Key.
+type relationList struct {
+	found     map[domain.Relation]struct{}
+	relations []domain.Relation
+}
+
+func newRelationList() *relationList {
+	return &relationList{found: map[domain.Relation]struct{}{}, relations: []domain.Relation{}}
+}
+
+func (l *relationList) add(relation domain.Relation) {
+	if _, ok := l.found[relation.Key()]; !ok {
+		l.found[relation.Key()] = struct{}{}
+		l.relations = append(l.relations, relation)
+	}
+}
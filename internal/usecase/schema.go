@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+	"github.com/spf13/viper"
+)
+
+// schemaCache holds the compiled rules of the latest schema. Schemas written
+// by other server replicas are seen once it reloads, after the refresh
+// interval.
+type schemaCache struct {
+	lock     sync.Mutex
+	loaded   bool
+	loadedAt time.Time
+	rules    *rewriteRules
+	// version is the version of the rules, which are nil for a schema
+	// without definitions
+	version uint64
+}
+
+func (c *schemaCache) get(refresh time.Duration) (*rewriteRules, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if !c.loaded || refresh <= 0 || time.Since(c.loadedAt) >= refresh {
+		return nil, false
+	}
+	return c.rules, true
+}
+
+// put keeps schema unless a later version is kept already.
+func (c *schemaCache) put(schema domain.Schema) *rewriteRules {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.loaded && schema.Version < c.version {
+		return c.rules
+	}
+	c.loaded, c.loadedAt = true, time.Now()
+	c.rules, c.version = compileRules(schema), schema.Version
+	return c.rules
+}
+
+// rules returns the rewrite rules of the latest schema, nil if there are
+// none or the repository does not store schemas. Every read applies the
+// latest schema, also to an exact snapshot.
+func (u *RelationUsecase) rules(ctx context.Context) (*rewriteRules, error) {
+	repo, ok := u.RelationRepo.(sqldomain.SchemaRepository)
+	if !ok {
+		return nil, nil
+	}
+	if rules, ok := u.schemas.get(viper.GetDuration("main.schema-refresh-interval")); ok {
+		return rules, nil
+	}
+	schema, err := repo.ReadSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return u.schemas.put(schema), nil
+}
+
+func (u *RelationUsecase) ReadSchema(ctx context.Context) (domain.Schema, error) {
+	repo, ok := u.RelationRepo.(sqldomain.SchemaRepository)
+	if !ok {
+		return domain.Schema{}, fmt.Errorf("the repository does not store schemas")
+	}
+	schema, err := repo.ReadSchema(ctx)
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	u.schemas.put(schema)
+	return schema, nil
+}
+
+// WriteSchema validates namespaces and writes them as the next version of
+// the schema, unless they are the latest version already.
+func (u *RelationUsecase) WriteSchema(ctx context.Context, namespaces []domain.NamespaceDefinition) (domain.Schema, error) {
+	return u.updateSchema(ctx, func([]domain.NamespaceDefinition) []domain.NamespaceDefinition {
+		return namespaces
+	})
+}
+
+// WriteNamespace adds namespace to the schema, or replaces the definition
+// of the same name.
+func (u *RelationUsecase) WriteNamespace(ctx context.Context, namespace domain.NamespaceDefinition) (domain.Schema, error) {
+	return u.updateSchema(ctx, func(namespaces []domain.NamespaceDefinition) []domain.NamespaceDefinition {
+		for i, definition := range namespaces {
+			if definition.Name == namespace.Name {
+				namespaces[i] = namespace
+				return namespaces
+			}
+		}
+		return append(namespaces, namespace)
+	})
+}
+
+// DeleteNamespace removes the definition of name from the schema, after
+// which the namespace takes any relation.
+func (u *RelationUsecase) DeleteNamespace(ctx context.Context, name string) (domain.Schema, error) {
+	return u.updateSchema(ctx, func(namespaces []domain.NamespaceDefinition) []domain.NamespaceDefinition {
+		kept := []domain.NamespaceDefinition{}
+		for _, definition := range namespaces {
+			if definition.Name != name {
+				kept = append(kept, definition)
+			}
+		}
+		return kept
+	})
+}
+
+// updateSchema writes the namespaces update returns for the latest ones as
+// the next version of the schema, in one transaction so concurrent updates
+// do not overwrite each other. Cached checks were computed under the
+// previous version, so they are dropped. A version that closes a cycle
+// through the relations written already is rejected with a
+// domain.CauseCycleError, other relations it no longer allows are kept.
+func (u *RelationUsecase) updateSchema(ctx context.Context, update func(namespaces []domain.NamespaceDefinition) []domain.NamespaceDefinition) (domain.Schema, error) {
+	if _, ok := u.RelationRepo.(sqldomain.SchemaRepository); !ok {
+		return domain.Schema{}, fmt.Errorf("the repository does not store schemas")
+	}
+	var schema domain.Schema
+	written := false
+	err := u.RelationRepo.WithTransaction(ctx, func(tx sqldomain.RelationRepository) error {
+		repo := tx.(sqldomain.SchemaRepository)
+		latest, err := repo.ReadSchema(ctx)
+		if err != nil {
+			return err
+		}
+		namespaces := normalize(update(append([]domain.NamespaceDefinition{}, latest.Namespaces...)))
+		if err := (domain.Schema{Namespaces: namespaces}).Validate(); err != nil {
+			return err
+		}
+		if sameNamespaces(namespaces, latest.Namespaces) {
+			schema = latest
+			return nil
+		}
+		if schema, err = repo.WriteSchema(ctx, namespaces); err != nil {
+			return err
+		}
+		written = true
+		return checkRulesAcyclic(ctx, tx, compileRules(schema))
+	})
+	if err != nil {
+		return domain.Schema{}, err
+	}
+	u.schemas.put(schema)
+	if written && u.Cache != nil {
+		u.Cache.invalidateAll()
+	}
+	return schema, nil
+}
+
+// latestRules returns the rules of the latest schema repo holds, not the
+// cached ones, nil if it does not store schemas.
+func latestRules(ctx context.Context, repo sqldomain.RelationRepository) (*rewriteRules, error) {
+	schemas, ok := repo.(sqldomain.SchemaRepository)
+	if !ok {
+		return nil, nil
+	}
+	schema, err := schemas.ReadSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return compileRules(schema), nil
+}
+
+// checkRulesAcyclic checks that the relations the rules compute close no
+// cycle through the stored relations of repo. Such a cycle passes the
+// object of a stored relation of one of the sources of the rules, or the
+// object of one computed through a tupleset, so checkAcyclic starts from
+// those.
+func checkRulesAcyclic(ctx context.Context, repo sqldomain.RelationRepository, rules *rewriteRules) error {
+	if !rules.computes() {
+		return nil
+	}
+	added := []domain.Relation{}
+	for _, key := range rules.sources() {
+		relations, err := repo.Query(ctx, domain.Relation{ObjectNamespace: key.namespace, Relation: key.relation})
+		if err != nil {
+			return err
+		}
+		added = append(added, relations...)
+	}
+	return checkAcyclic(ctx, repo, rules, added)
+}
+
+// normalize returns namespaces with empty lists of relations and no empty
+// lists of subject types, as they are stored.
+func normalize(namespaces []domain.NamespaceDefinition) []domain.NamespaceDefinition {
+	normalized := make([]domain.NamespaceDefinition, len(namespaces))
+	for i, namespace := range namespaces {
+		relations := make([]domain.RelationDefinition, len(namespace.Relations))
+		for j, relation := range namespace.Relations {
+			if len(relation.SubjectTypes) == 0 {
+				relation.SubjectTypes = nil
+			}
+			relations[j] = relation
+		}
+		normalized[i] = domain.NamespaceDefinition{Name: namespace.Name, Relations: relations}
+	}
+	return normalized
+}
+
+// sameNamespaces compares namespaces as stored.
+func sameNamespaces(a, b []domain.NamespaceDefinition) bool {
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(x, y)
+}
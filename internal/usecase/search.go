@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/skyrocketOoO/zanazibar-dag/domain"
+	sqldomain "github.com/skyrocketOoO/zanazibar-dag/domain/infra/sql"
+)
+
+// searchSide is one half of a bidirectional search: the nodes found from
+// its root, each with the relation leading back to the root.
+type searchSide struct {
+	steps    map[domain.Node]searchStep
+	frontier []domain.Node
+	depth    int
+}
+
+// searchStep is the relation between a node and its neighbour one level
+// closer to the root of the side.
+type searchStep struct {
+	next     domain.Node
+	relation domain.Relation
+	root     bool
+}
+
+func newSearchSide(root domain.Node) *searchSide {
+	return &searchSide{
+		steps:    map[domain.Node]searchStep{root: {root: true}},
+		frontier: []domain.Node{root},
+	}
+}
+
+// path returns the relations from node to the root, in the order they are
+// walked from node.
+func (s *searchSide) path(node domain.Node) []domain.Relation {
+	path := []domain.Relation{}
+	for step := s.steps[node]; !step.root; step = s.steps[step.next] {
+		path = append(path, step.relation)
+	}
+	return path
+}
+
+// shortestPath searches from the subject along the relations and from the
+// object against them at the same time, one level of the smaller frontier
+// at a time, and joins the halves where they meet. Nodes the search
+// condition stops at are not passed through by either half. It returns nil
+// if there is no path of at most budget.MaxDepth relations, and fails once
+// both halves together visit more than budget.MaxNodes nodes. Limits of 0 or
+// less mean no limit.
+func shortestPath(ctx context.Context, repo sqldomain.RelationRepository, subject domain.Node, object domain.Node, searchCondition domain.SearchCondition, budget domain.Budget) ([]domain.Relation, error) {
+	forward, backward := newSearchSide(subject), newSearchSide(object)
+	maxDepth := budget.MaxDepth
+	visited := visits{max: budget.MaxNodes}
+
+	for len(forward.frontier) > 0 && len(backward.frontier) > 0 {
+		if maxDepth > 0 && forward.depth+backward.depth >= maxDepth {
+			break
+		}
+		var path []domain.Relation
+		// a level may meet the other half at several depths, the shortest
+		// meeting of the level is the shortest path
+		meet := func(candidate []domain.Relation) {
+			if path == nil || len(candidate) < len(path) {
+				path = candidate
+			}
+		}
+
+		if len(forward.frontier) <= len(backward.frontier) {
+			children, err := children(ctx, repo, forward.frontier)
+			if err != nil {
+				return nil, err
+			}
+			forward.depth++
+			next := []domain.Node{}
+			for _, node := range forward.frontier {
+				for _, tuple := range children[node] {
+					child := objectOf(tuple)
+					if _, ok := backward.steps[child]; ok {
+						meet(join(forward.path(node), tuple, backward.path(child)))
+						continue
+					}
+					if _, ok := forward.steps[child]; ok || searchCondition.ShouldStop(child) {
+						continue
+					}
+					if err := visited.add(1); err != nil {
+						return nil, err
+					}
+					forward.steps[child] = searchStep{next: node, relation: tuple}
+					next = append(next, child)
+				}
+			}
+			forward.frontier = next
+		} else {
+			parents, err := parents(ctx, repo, backward.frontier)
+			if err != nil {
+				return nil, err
+			}
+			backward.depth++
+			next := []domain.Node{}
+			for _, node := range backward.frontier {
+				for _, tuple := range parents[node] {
+					// the repository matches a node without relation against
+					// every relation, an object is matched exactly
+					if objectOf(tuple) != node {
+						continue
+					}
+					parent := subjectOf(tuple)
+					if parent.Matches(subject) {
+						meet(join(nil, tuple, backward.path(node)))
+						continue
+					}
+					if searchCondition.ShouldStop(parent) {
+						continue
+					}
+					if _, ok := forward.steps[parent]; ok {
+						meet(join(forward.path(parent), tuple, backward.path(node)))
+						continue
+					}
+					if _, ok := backward.steps[parent]; ok {
+						continue
+					}
+					if err := visited.add(1); err != nil {
+						return nil, err
+					}
+					backward.steps[parent] = searchStep{next: node, relation: tuple}
+					next = append(next, parent)
+				}
+			}
+			backward.frontier = next
+		}
+		if path != nil {
+			return path, nil
+		}
+	}
+	return nil, nil
+}
+
+// join returns the path walking head against its order, then tuple, then
+// tail, where head and tail are the paths of a forward and a backward node.
+func join(head []domain.Relation, tuple domain.Relation, tail []domain.Relation) []domain.Relation {
+	path := make([]domain.Relation, 0, len(head)+1+len(tail))
+	for i := len(head) - 1; i >= 0; i-- {
+		path = append(path, head[i])
+	}
+	path = append(path, tuple)
+	return append(path, tail...)
+}
@@ -16,11 +16,12 @@ import (
 	"github.com/skyrocketOoO/zanazibar-dag/internal/delivery"
 	"github.com/skyrocketOoO/zanazibar-dag/internal/delivery/proto"
 	"github.com/skyrocketOoO/zanazibar-dag/internal/delivery/rest"
-	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra"
 	"github.com/skyrocketOoO/zanazibar-dag/internal/usecase"
 	"google.golang.org/grpc"
 
 	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -32,20 +33,38 @@ func main() {
 		panic(err.Error())
 	}
 
-	db, err := sql.InitDb()
-	if err != nil {
-		panic(err)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	sqlRepo, err := sql.NewOrmRepository(db)
+	relationRepo, err := infra.NewRelationRepository()
 	if err != nil {
 		panic(err)
 	}
 
-	usecaseRepo := usecase.NewUsecaseRepository(sqlRepo)
+	usecaseRepo := usecase.NewUsecaseRepository(relationRepo)
+
+	// the schema file becomes the next schema version if it differs from the
+	// stored one
+	if path := viper.GetString("main.schema-file"); path != "" {
+		namespaces, err := config.ReadSchemaFile(path)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := usecaseRepo.RelationUsecase.WriteSchema(context.Background(), namespaces); err != nil {
+			panic(err)
+		}
+	}
 
 	handlerRepo := delivery.NewHandlerRepository(usecaseRepo)
 
+	// requests running longer fail with 504 or DeadlineExceeded, 0 means no
+	// limit
+	requestTimeout := viper.GetDuration("main.request-timeout")
+
 	var wg sync.WaitGroup
 
 	server := gin.Default()
@@ -60,7 +79,12 @@ func main() {
 			c.JSON(http.StatusOK, nil)
 		})
 		relationHandler := handlerRepo.RelationHandler
+		// an import runs as long as its body streams, so it is not bounded
+		server.POST("/relation/import", relationHandler.BulkImport)
 		relationRouter := server.Group("/relation")
+		if requestTimeout > 0 {
+			relationRouter.Use(rest.Timeout(requestTimeout))
+		}
 		{
 			relationRouter.GET("/", relationHandler.Get)
 			relationRouter.POST("/", relationHandler.Create)
@@ -71,13 +95,30 @@ func main() {
 
 			relationRouter.POST("get-all-namespaces", relationHandler.GetAllNamespaces)
 			relationRouter.POST("/check", relationHandler.Check)
+			relationRouter.POST("/explain", relationHandler.Explain)
 			relationRouter.POST("/get-shortest-path", relationHandler.GetShortestPath)
 			relationRouter.POST("/get-all-paths", relationHandler.GetAllPaths)
+			relationRouter.POST("/get-k-shortest-paths", relationHandler.GetKShortestPaths)
 			relationRouter.POST("/get-all-object-relations", relationHandler.GetAllObjectRelations)
 			relationRouter.POST("/get-all-subject-relations", relationHandler.GetAllSubjectRelations)
+			relationRouter.POST("/expand", relationHandler.Expand)
 			relationRouter.POST("/clear-all-relations", relationHandler.ClearAllRelations)
 		}
 
+		schemaRouter := server.Group("/schema")
+		if requestTimeout > 0 {
+			schemaRouter.Use(rest.Timeout(requestTimeout))
+		}
+		{
+			schemaRouter.GET("", relationHandler.ReadSchema)
+			schemaRouter.PUT("", relationHandler.WriteSchema)
+			schemaRouter.GET("/namespaces/:namespace", relationHandler.ReadNamespace)
+			schemaRouter.PUT("/namespaces/:namespace", relationHandler.WriteNamespace)
+			schemaRouter.DELETE("/namespaces/:namespace", relationHandler.DeleteNamespace)
+		}
+
+		server.GET("/cache/stats", relationHandler.GetCacheStats)
+
 		vd := rest.NewVisualDelivery(*usecase.NewVisualUsecase())
 		server.GET("/visual", vd.SeeTree)
 
@@ -94,7 +135,11 @@ func main() {
 	}()
 
 	wg.Add(1)
-	grpcServer := grpc.NewServer()
+	grpcOptions := []grpc.ServerOption{}
+	if requestTimeout > 0 {
+		grpcOptions = append(grpcOptions, grpc.UnaryInterceptor(proto.Timeout(requestTimeout)))
+	}
+	grpcServer := grpc.NewServer(grpcOptions...)
 	go func() {
 		defer wg.Done()
 		proto.RegisterRelationServiceServer(grpcServer, proto.NewRelationHandler(usecaseRepo.RelationUsecase))
@@ -127,6 +172,8 @@ func main() {
 	// Shut down the gRPC server
 	grpcServer.GracefulStop()
 
+	usecaseRepo.Stop()
+
 	// Wait for all goroutines to finish
 	wg.Wait()
 
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/skyrocketOoO/zanazibar-dag/internal/infra/sql"
+	"github.com/spf13/viper"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | version"
+
+// runMigrate handles the migrate subcommand, which moves the schema of the
+// configured database up or down instead of starting the servers.
+func runMigrate(args []string) error {
+	switch dbType := viper.GetString("db.type"); dbType {
+	case "inmemory":
+		return fmt.Errorf("db type %s has no schema to migrate", dbType)
+	case "kv":
+		return fmt.Errorf("db type %s migrates its store when it is opened", dbType)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := sql.InitDb()
+	if err != nil {
+		return err
+	}
+	migrator, err := sql.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid steps: %s", args[1])
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+	case "version":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	version, err := migrator.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("schema version %d, binary supports %d\n", version, migrator.Latest())
+	return nil
+}
@@ -1,7 +1,6 @@
 package utils
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
 
@@ -9,12 +8,18 @@ import (
 )
 
 func ValidateRelation(rel domain.Relation) error {
-	fmt.Printf("%+v\n", rel)
 	if rel.ObjectNamespace == "" || rel.ObjectName == "" || rel.Relation == "" ||
 		rel.SubjectNamespace == "" || rel.SubjectName == "" {
 		return domain.RequestBodyError{}
 	}
-	return ValidateReservedWord(rel)
+	// a wildcard stands for subjects, and only for the ones without relation
+	if rel.ObjectName == domain.Wildcard || rel.SubjectName == domain.Wildcard && rel.SubjectRelation != "" {
+		return domain.RequestBodyError{}
+	}
+	if _, err := rel.Compile(); err != nil {
+		return err
+	}
+	return ValidateReservedWord(rel.Key())
 }
 
 func ValidateNode(node domain.Node, isSubject bool) error {
@@ -24,6 +29,9 @@ func ValidateNode(node domain.Node, isSubject bool) error {
 	if !isSubject && node.Relation == "" {
 		return domain.RequestBodyError{}
 	}
+	if node.Name == domain.Wildcard && (!isSubject || node.Relation != "") {
+		return domain.RequestBodyError{}
+	}
 	return ValidateReservedWord(node)
 }
 
@@ -31,8 +39,10 @@ func ValidateReservedWord(st interface{}) error {
 	value := reflect.ValueOf(st)
 	for i := 0; i < value.NumField(); i++ {
 		field := value.Field(i)
-		str := field.Interface().(string)
-		if strings.Contains(str, "%") {
+		if field.Kind() != reflect.String {
+			continue
+		}
+		if strings.Contains(field.String(), "%") {
 			return domain.RequestBodyError{}
 		}
 